@@ -0,0 +1,126 @@
+// Package backplane carries the events of the rooms between the replicas of
+// the chat server, through a publish/subscribe bus.
+package backplane
+
+import (
+	"sync"
+)
+
+// Handler receives the payloads published on a topic, in order.
+type Handler func(payload []byte)
+
+// Backplane is a publish/subscribe bus. Implementations must be safe for
+// concurrent use.
+type Backplane interface {
+	// Publish sends payload to the subscribers of topic, including the ones
+	// of the publishing process.
+	Publish(topic string, payload []byte) error
+	// Subscribe calls h with the payloads published on topic until the
+	// returned function is called.
+	Subscribe(topic string, h Handler) (unsubscribe func(), err error)
+	Close() error
+}
+
+// Local is the Backplane of a single process. It calls the handlers
+// synchronously, from Publish.
+type Local struct {
+	subscriptions
+}
+
+func (l *Local) Publish(topic string, payload []byte) error {
+	for _, h := range l.handlers(topic) {
+		h(payload)
+	}
+
+	return nil
+}
+
+func (l *Local) Subscribe(topic string, h Handler) (func(), error) {
+	id, _ := l.add(topic, h)
+
+	return func() {
+		l.remove(topic, id)
+	}, nil
+}
+
+func (l *Local) Close() error {
+	return nil
+}
+
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// subscriptions indexes the handlers by topic. The zero value is ready to
+// use.
+type subscriptions struct {
+	mu     sync.Mutex
+	nextID int
+	topics map[string]map[int]Handler
+}
+
+// add registers h for topic, reporting whether it is the first handler of
+// topic.
+func (s *subscriptions) add(topic string, h Handler) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.topics == nil {
+		s.topics = make(map[string]map[int]Handler)
+	}
+	s.nextID++
+	first := len(s.topics[topic]) == 0
+	if first {
+		s.topics[topic] = make(map[int]Handler)
+	}
+	s.topics[topic][s.nextID] = h
+
+	return s.nextID, first
+}
+
+// remove unregisters the handler id of topic, reporting whether it was the
+// last handler of topic.
+func (s *subscriptions) remove(topic string, id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handlers, ok := s.topics[topic]
+	if !ok {
+		return false
+	}
+	if _, ok := handlers[id]; !ok {
+		return false
+	}
+	delete(handlers, id)
+	if len(handlers) > 0 {
+		return false
+	}
+	delete(s.topics, topic)
+
+	return true
+}
+
+func (s *subscriptions) handlers(topic string) []Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handlers := make([]Handler, 0, len(s.topics[topic]))
+	for _, h := range s.topics[topic] {
+		handlers = append(handlers, h)
+	}
+
+	return handlers
+}
+
+// list returns the topics with at least one handler.
+func (s *subscriptions) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+
+	return topics
+}
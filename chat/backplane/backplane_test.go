@@ -0,0 +1,190 @@
+package backplane
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis implements the publish/subscribe commands of a Redis server.
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	// subscribers holds the connections subscribed to each channel.
+	subscribers map[string]map[*respConn]bool
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(&respConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)})
+	}
+}
+
+func (f *fakeRedis) handle(c *respConn) {
+	defer func() {
+		f.mu.Lock()
+		for _, conns := range f.subscribers {
+			delete(conns, c)
+		}
+		f.mu.Unlock()
+		c.close()
+	}()
+	for {
+		req, err := c.read()
+		if err != nil {
+			return
+		}
+		args, _ := req.([]interface{})
+		if len(args) < 2 {
+			return
+		}
+		f.mu.Lock()
+		switch cmd := args[0].(string); cmd {
+		case "SUBSCRIBE", "UNSUBSCRIBE":
+			for _, arg := range args[1:] {
+				channel := arg.(string)
+				if f.subscribers[channel] == nil {
+					f.subscribers[channel] = make(map[*respConn]bool)
+				}
+				if cmd == "SUBSCRIBE" {
+					f.subscribers[channel][c] = true
+				} else {
+					delete(f.subscribers[channel], c)
+				}
+				c.w.WriteString("*3\r\n")
+				writeBulk(c, map[string]string{"SUBSCRIBE": "subscribe", "UNSUBSCRIBE": "unsubscribe"}[cmd])
+				writeBulk(c, channel)
+				c.w.WriteString(":1\r\n")
+			}
+		case "PUBLISH":
+			channel, payload := args[1].(string), args[2].(string)
+			for sub := range f.subscribers[channel] {
+				sub.w.WriteString("*3\r\n")
+				writeBulk(sub, "message")
+				writeBulk(sub, channel)
+				writeBulk(sub, payload)
+				sub.w.Flush()
+			}
+			c.w.WriteString(":1\r\n")
+		}
+		c.w.Flush()
+		f.mu.Unlock()
+	}
+}
+
+func writeBulk(c *respConn, s string) {
+	c.w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func newFakeRedis(t *testing.T) string {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	f := &fakeRedis{listener: listener, subscribers: make(map[string]map[*respConn]bool)}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return listener.Addr().String()
+}
+
+func TestBackplane(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// New returns two backplanes sharing their topics.
+		New func(t *testing.T) (Backplane, Backplane)
+	}{
+		{
+			Name: "local",
+			New: func(t *testing.T) (Backplane, Backplane) {
+				l := NewLocal()
+
+				return l, l
+			},
+		},
+		{
+			Name: "redis",
+			New: func(t *testing.T) (Backplane, Backplane) {
+				addr := newFakeRedis(t)
+				a, err := NewRedis(addr)
+				if err != nil {
+					t.Fatalf("NewRedis failed: %v", err)
+				}
+				b, err := NewRedis(addr)
+				if err != nil {
+					t.Fatalf("NewRedis failed: %v", err)
+				}
+
+				return a, b
+			},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			a, b := tt.New(t)
+			defer a.Close()
+			defer b.Close()
+
+			received := make(chan string, 10)
+			subscribe := func(bp Backplane, name, topic string) func() {
+				unsubscribe, err := bp.Subscribe(topic, func(payload []byte) {
+					received <- name + ":" + string(payload)
+				})
+				if err != nil {
+					t.Fatalf("Subscribe failed: %v", err)
+				}
+
+				return unsubscribe
+			}
+			expect := func(want ...string) {
+				got := make(map[string]bool)
+				for range want {
+					select {
+					case msg := <-received:
+						got[msg] = true
+					case <-time.After(time.Second):
+						t.Fatalf("got %v, want %v", got, want)
+					}
+				}
+				for _, w := range want {
+					if !got[w] {
+						t.Errorf("got %v, want %v", got, want)
+					}
+				}
+				select {
+				case msg := <-received:
+					t.Errorf("unexpected %s", msg)
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+
+			subscribe(a, "a", "room.dev")
+			unsubscribeB := subscribe(b, "b", "room.dev")
+			subscribe(b, "b", "room.ops")
+
+			if err := a.Publish("room.dev", []byte("hello")); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			expect("a:hello", "b:hello")
+
+			if err := a.Publish("room.ops", []byte("paged")); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			expect("b:paged")
+
+			unsubscribeB()
+			if err := b.Publish("room.dev", []byte("bye")); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			expect("a:bye")
+		})
+	}
+}
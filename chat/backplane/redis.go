@@ -0,0 +1,376 @@
+package backplane
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	redisDialTimeout = 5 * time.Second
+	// redisSubscribeTimeout bounds the wait for the confirmation of a
+	// subscription.
+	redisSubscribeTimeout = 5 * time.Second
+	redisMaxBackoff       = 10 * time.Second
+)
+
+var errClosed = errors.New("backplane closed")
+
+// redisError is an error reply of the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// Redis is a Backplane over the publish/subscribe commands of a Redis server,
+// or of any server speaking its protocol. The handlers are called from a
+// single goroutine reading the subscriptions, so they must not block.
+//
+// Redis reconnects when the connection is lost, subscribing again. The
+// payloads published meanwhile are lost.
+type Redis struct {
+	addr string
+	subscriptions
+
+	// pub sends the PUBLISH commands, it is guarded by pubMu.
+	pub   *respConn
+	pubMu sync.Mutex
+
+	// sub is the connection in subscriber mode. subMu guards the writes to
+	// it and its replacement.
+	sub   *respConn
+	subMu sync.Mutex
+	// pending holds the subscriptions waiting for their confirmation. It is
+	// guarded by pendingMu.
+	pending   map[string]chan struct{}
+	pendingMu sync.Mutex
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	// done is closed when the subscriptions are not read anymore.
+	done chan struct{}
+}
+
+func (r *Redis) Publish(topic string, payload []byte) error {
+	r.pubMu.Lock()
+	defer r.pubMu.Unlock()
+
+	// A connection broken since the last command fails once, then a new one
+	// is dialed.
+	for attempt := 0; ; attempt++ {
+		if r.isClosed() {
+			return errClosed
+		}
+		if r.pub == nil {
+			c, err := dialRESP(r.addr)
+			if err != nil {
+				return err
+			}
+			r.pub = c
+		}
+		_, err := r.pub.do("PUBLISH", topic, string(payload))
+		var replyErr redisError
+		switch {
+		case err == nil:
+			return nil
+		case errors.As(err, &replyErr):
+			return err
+		}
+		r.pub.close()
+		r.pub = nil
+		if attempt > 0 {
+			return fmt.Errorf("publish failed: %w", err)
+		}
+	}
+}
+
+// Subscribe waits for the server to confirm the first subscription to a
+// topic, so that the payloads published afterwards are received.
+func (r *Redis) Subscribe(topic string, h Handler) (func(), error) {
+	r.subMu.Lock()
+	id, first := r.add(topic, h)
+	var confirmed chan struct{}
+	if first {
+		confirmed = r.expect(topic)
+		if err := r.sub.write("SUBSCRIBE", topic); err != nil {
+			// The subscriptions are renewed with the connection.
+			log.Warnf("Subscribing to %s failed: %v", topic, err)
+		}
+	}
+	r.subMu.Unlock()
+	unsubscribe := func() {
+		if !r.remove(topic, id) {
+			return
+		}
+		r.subMu.Lock()
+		defer r.subMu.Unlock()
+		if err := r.sub.write("UNSUBSCRIBE", topic); err != nil {
+			log.Warnf("Unsubscribing from %s failed: %v", topic, err)
+		}
+	}
+	if confirmed == nil {
+		return unsubscribe, nil
+	}
+
+	select {
+	case <-confirmed:
+		return unsubscribe, nil
+	case <-r.closed:
+		unsubscribe()
+
+		return nil, errClosed
+	case <-time.After(redisSubscribeTimeout):
+		unsubscribe()
+
+		return nil, fmt.Errorf("subscription to %s not confirmed", topic)
+	}
+}
+
+func (r *Redis) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+		r.pubMu.Lock()
+		if r.pub != nil {
+			r.pub.close()
+		}
+		r.pubMu.Unlock()
+		r.subMu.Lock()
+		r.sub.close()
+		r.subMu.Unlock()
+	})
+	<-r.done
+
+	return nil
+}
+
+func (r *Redis) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// expect returns a channel closed when the subscription to topic is
+// confirmed.
+func (r *Redis) expect(topic string) chan struct{} {
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+
+	c, ok := r.pending[topic]
+	if !ok {
+		c = make(chan struct{})
+		r.pending[topic] = c
+	}
+
+	return c
+}
+
+func (r *Redis) confirm(topic string) {
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+
+	if c, ok := r.pending[topic]; ok {
+		close(c)
+		delete(r.pending, topic)
+	}
+}
+
+// readLoop dispatches the payloads received on the subscriber connection to
+// the handlers, until r is closed.
+func (r *Redis) readLoop() {
+	defer close(r.done)
+	for {
+		reply, err := r.sub.read()
+		if err != nil {
+			var replyErr redisError
+			if errors.As(err, &replyErr) {
+				log.Errorf("Backplane error: %v", err)
+
+				continue
+			}
+			if r.isClosed() {
+				return
+			}
+			log.Warnf("Backplane connection lost: %v", err)
+			if !r.reconnect() {
+				return
+			}
+
+			continue
+		}
+		push, ok := reply.([]interface{})
+		if !ok || len(push) < 2 {
+			continue
+		}
+		kind, _ := push[0].(string)
+		topic, _ := push[1].(string)
+		switch kind {
+		case "subscribe":
+			r.confirm(topic)
+		case "message":
+			if len(push) < 3 {
+				continue
+			}
+			payload, _ := push[2].(string)
+			for _, h := range r.handlers(topic) {
+				h([]byte(payload))
+			}
+		}
+	}
+}
+
+// reconnect dials a new subscriber connection, with backoff, and renews the
+// subscriptions on it. It returns false when r is closed meanwhile.
+func (r *Redis) reconnect() bool {
+	backoff := 100 * time.Millisecond
+	for {
+		select {
+		case <-r.closed:
+			return false
+		case <-time.After(backoff):
+		}
+		r.subMu.Lock()
+		if r.isClosed() {
+			r.subMu.Unlock()
+
+			return false
+		}
+		c, err := dialRESP(r.addr)
+		if err == nil {
+			if topics := r.list(); len(topics) > 0 {
+				err = c.write(append([]string{"SUBSCRIBE"}, topics...)...)
+			}
+		}
+		if err == nil {
+			r.sub.close()
+			r.sub = c
+			r.subMu.Unlock()
+			log.Infof("Backplane reconnected to %s", r.addr)
+
+			return true
+		}
+		r.subMu.Unlock()
+		log.Warnf("Backplane reconnection failed: %v", err)
+		if backoff *= 2; backoff > redisMaxBackoff {
+			backoff = redisMaxBackoff
+		}
+	}
+}
+
+// NewRedis connects to the Redis server at addr.
+func NewRedis(addr string) (*Redis, error) {
+	sub, err := dialRESP(addr)
+	if err != nil {
+		return nil, err
+	}
+	r := &Redis{
+		addr:    addr,
+		sub:     sub,
+		pending: make(map[string]chan struct{}),
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.readLoop()
+
+	return r, nil
+}
+
+// respConn is a connection speaking RESP, the protocol of Redis.
+type respConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// do sends a command and reads its reply.
+func (c *respConn) do(args ...string) (interface{}, error) {
+	if err := c.write(args...); err != nil {
+		return nil, err
+	}
+
+	return c.read()
+}
+
+// write sends a command, as an array of bulk strings.
+func (c *respConn) write(args ...string) error {
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	return c.w.Flush()
+}
+
+// read reads a reply: a string, an int64, a []interface{} of replies or nil.
+// The error replies are returned as redisError.
+func (c *respConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, redisError(value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		array := make([]interface{}, n)
+		for i := range array {
+			if array[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+
+		return array, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", kind)
+	}
+}
+
+func (c *respConn) close() {
+	c.conn.Close()
+}
+
+func dialRESP(addr string) (*respConn, error) {
+	conn, err := net.DialTimeout("tcp", addr, redisDialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the backplane: %w", err)
+	}
+
+	return &respConn{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}, nil
+}
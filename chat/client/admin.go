@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+type AdminClient struct {
+	pb.ChatAdminClient
+	conn *grpc.ClientConn
+}
+
+func (c *AdminClient) Shutdown() error {
+	return c.conn.Close()
+}
+
+// NewAdminClient connects to the admin server at serverAddr. When token is
+// not empty, it is sent as a bearer token with every call.
+func NewAdminClient(serverAddr string, token string) (*AdminClient, error) {
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.Dial(serverAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &AdminClient{
+		ChatAdminClient: pb.NewChatAdminClient(conn),
+		conn:            conn,
+	}
+
+	return c, nil
+}
+
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false since the admin server is expected to
+// listen on localhost only, and the chat server may serve plaintext.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
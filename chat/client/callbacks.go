@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	pbutils "github.com/golang/protobuf/ptypes"
 	"github.com/looplab/fsm"
@@ -41,6 +43,22 @@ func PairHandler(stream pb.Chat_RouteChatClient, author string) fsm.Callback {
 
 func ConfirmRoomHandler(stream pb.Chat_RouteChatClient, sigint chan<- os.Signal) fsm.Callback {
 	return func(e *fsm.Event) {
+		if sMsgP, err := extractServerMsg(e); err == nil {
+			var confirmRoomMsg pb.ServerMessage_ServerConfirmRoomCheckout
+			if err := pbutils.UnmarshalAny(sMsgP.Operation, &confirmRoomMsg); err == nil && confirmRoomMsg.Room != nil {
+				fmt.Printf("*** Joined room %s\n", confirmRoomMsg.Room.Name)
+				if confirmRoomMsg.Room.Topic != "" {
+					fmt.Printf("*** Topic: %s\n", confirmRoomMsg.Room.Topic)
+				}
+			}
+		}
+		// The first checkout, possibly after waiting in the queue of a full
+		// room, starts reading the user input, the next ones confirm a change
+		// of room.
+		if e.Src == "ready" {
+			return
+		}
+
 		go func() {
 			reader := bufio.NewReader(os.Stdin)
 			for {
@@ -53,13 +71,25 @@ func ConfirmRoomHandler(stream pb.Chat_RouteChatClient, sigint chan<- os.Signal)
 				}
 				message := strings.Replace(text, "\n", "", -1)
 
-				switch message {
-				case "q":
+				switch {
+				case message == "q":
 					sigint <- os.Interrupt
 
 					return
-				case "":
+				case message == "":
 					// do not send empty messages.
+				case strings.HasPrefix(message, "/"):
+					cMsgP, err := parseCommand(message)
+					if err != nil {
+						fmt.Printf("!!! %v\n", err)
+
+						continue
+					}
+					if err := stream.Send(cMsgP); err != nil {
+						log.Errorf("Failed to send %s: %v", cMsgP.Command, err)
+
+						return
+					}
 				default:
 					writeMsg := pb.ClientMessage_ClientWriteMessage{
 						Body: message,
@@ -108,8 +138,360 @@ func ForwardMessageHandler(author string) fsm.Callback {
 	}
 }
 
+func AnnouncementHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var announcementMsg pb.ServerMessage_ServerAnnouncement
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &announcementMsg); err != nil {
+			log.Errorf("Unmarshal to announcement failed: %v", err)
+
+			return
+		}
+
+		fmt.Printf("*** %s\n", announcementMsg.Body)
+	}
+}
+
+func ErrorHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var errorMsg pb.ServerMessage_ServerError
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &errorMsg); err != nil {
+			log.Errorf("Unmarshal to error failed: %v", err)
+
+			return
+		}
+
+		fmt.Printf("!!! %s failed: %s\n", errorMsg.Command, errorMsg.Message)
+	}
+}
+
+func RoomStateHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var roomStateMsg pb.ServerMessage_ServerRoomState
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &roomStateMsg); err != nil {
+			log.Errorf("Unmarshal to roomState failed: %v", err)
+
+			return
+		}
+
+		switch roomStateMsg.Mode {
+		case pb.RoomMode_Slow:
+			fmt.Printf("*** The room is in slow mode: one message every %s\n", roomStateMsg.SlowModeInterval.AsDuration())
+		case pb.RoomMode_ReadOnly:
+			fmt.Println("*** The room is read-only")
+		case pb.RoomMode_AnnouncementOnly:
+			fmt.Println("*** Only moderators can write in the room")
+		default:
+			fmt.Println("*** Everybody can write in the room")
+		}
+	}
+}
+
+func RoomListHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var roomListMsg pb.ServerMessage_ServerRoomList
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &roomListMsg); err != nil {
+			log.Errorf("Unmarshal to roomList failed: %v", err)
+
+			return
+		}
+
+		for _, r := range roomListMsg.Rooms {
+			visibility := ""
+			if r.Visibility == pb.RoomVisibility_Private {
+				visibility = ", private"
+			}
+			topic := ""
+			if r.Topic != "" {
+				topic = ": " + r.Topic
+			}
+			unread := ""
+			if r.Unread > 0 {
+				unread = fmt.Sprintf(", %d unread", r.Unread)
+			}
+			fmt.Printf("*** %s (%d participants%s%s)%s\n", r.Name, r.Participants, visibility, unread, topic)
+		}
+	}
+}
+
+func RoomDetailsHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var roomDetailsMsg pb.ServerMessage_ServerRoomDetails
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &roomDetailsMsg); err != nil {
+			log.Errorf("Unmarshal to roomDetails failed: %v", err)
+
+			return
+		}
+
+		r := roomDetailsMsg.Room
+		fmt.Printf("*** Room %s (%d participants)\n", r.GetName(), r.GetParticipants())
+		if r.GetTopic() != "" {
+			fmt.Printf("*** Topic: %s\n", r.Topic)
+		}
+		if r.GetDescription() != "" {
+			fmt.Printf("*** %s\n", r.Description)
+		}
+		created := fmt.Sprintf("*** Created at %s", r.GetCreatedAt().AsTime().Local().Format(time.Stamp))
+		if r.GetCreator() != "" {
+			created += " by " + r.Creator
+		}
+		fmt.Println(created)
+		keys := make([]string, 0, len(r.GetLabels()))
+		for k := range r.GetLabels() {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("*** %s=%s\n", k, r.Labels[k])
+		}
+	}
+}
+
+func TopicChangedHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var topicChangedMsg pb.ServerMessage_ServerTopicChanged
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &topicChangedMsg); err != nil {
+			log.Errorf("Unmarshal to topicChanged failed: %v", err)
+
+			return
+		}
+
+		by := ""
+		if topicChangedMsg.ChangedBy != "" {
+			by = " by " + topicChangedMsg.ChangedBy
+		}
+		if topicChangedMsg.Topic == "" {
+			fmt.Printf("*** Topic cleared%s\n", by)
+
+			return
+		}
+		fmt.Printf("*** Topic changed%s: %s\n", by, topicChangedMsg.Topic)
+	}
+}
+
+func DirectMessageHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var directMsg pb.ServerMessage_ServerDirectMessage
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &directMsg); err != nil {
+			log.Errorf("Unmarshal to directMessage failed: %v", err)
+
+			return
+		}
+
+		fmt.Printf("[DM] %s%s: %s\n", directMsg.From, sentWhileAway(directMsg.Offline, directMsg.SentAt.AsTime()), directMsg.Body)
+	}
+}
+
+func MentionHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var mentionMsg pb.ServerMessage_ServerMention
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &mentionMsg); err != nil {
+			log.Errorf("Unmarshal to mention failed: %v", err)
+
+			return
+		}
+
+		fmt.Printf("*** %s mentioned you in %s%s: %s\n", mentionMsg.Author, mentionMsg.RoomName, sentWhileAway(mentionMsg.Offline, mentionMsg.SentAt.AsTime()), mentionMsg.Body)
+	}
+}
+
+func ReadReceiptHandler(author string) fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var readReceiptMsg pb.ServerMessage_ServerReadReceipt
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &readReceiptMsg); err != nil {
+			log.Errorf("Unmarshal to readReceipt failed: %v", err)
+
+			return
+		}
+
+		if readReceiptMsg.Username == author {
+			fmt.Printf("*** Marked as read up to message %d\n", readReceiptMsg.MessageId)
+
+			return
+		}
+		fmt.Printf("*** %s read up to message %d\n", readReceiptMsg.Username, readReceiptMsg.MessageId)
+	}
+}
+
+func SearchResultsHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var searchResultsMsg pb.ServerMessage_ServerSearchResults
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &searchResultsMsg); err != nil || searchResultsMsg.Response == nil {
+			log.Errorf("Unmarshal to searchResults failed: %v", err)
+
+			return
+		}
+
+		res := searchResultsMsg.Response
+		if len(res.Messages) == 0 {
+			fmt.Println("*** No results")
+
+			return
+		}
+		for _, m := range res.Messages {
+			fmt.Printf("[%s #%d %s] %s: %s\n", m.RoomName, m.Id, m.SentAt.AsTime().Local().Format("Jan 2 15:04"), m.Author, m.Body)
+		}
+		if res.NextPageToken != "" {
+			fmt.Printf("*** More results, repeat the search with page:%s\n", res.NextPageToken)
+		}
+	}
+}
+
+func HistoryHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var historyMsg pb.ServerMessage_ServerHistory
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &historyMsg); err != nil || historyMsg.Response == nil {
+			log.Errorf("Unmarshal to history failed: %v", err)
+
+			return
+		}
+
+		res := historyMsg.Response
+		if len(res.Messages) == 0 {
+			fmt.Println("*** No messages")
+
+			return
+		}
+		for _, m := range res.Messages {
+			fmt.Printf("[#%d %s] %s: %s\n", m.Id, m.SentAt.AsTime().Local().Format("Jan 2 15:04"), m.Author, m.Body)
+		}
+		if res.NextCursor != "" {
+			fmt.Printf("*** More with /history in:%s page:%s\n", res.Messages[0].RoomName, res.NextCursor)
+		}
+	}
+}
+
+// sentWhileAway describes when a message kept in the mailbox was sent.
+func sentWhileAway(offline bool, sentAt time.Time) string {
+	if !offline {
+		return ""
+	}
+
+	return fmt.Sprintf(" (while you were away, %s)", sentAt.Local().Format("Jan 2 15:04"))
+}
+
+func InviteCreatedHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var inviteCreatedMsg pb.ServerMessage_ServerInviteCreated
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &inviteCreatedMsg); err != nil {
+			log.Errorf("Unmarshal to inviteCreated failed: %v", err)
+
+			return
+		}
+
+		uses := "unlimited uses"
+		switch inviteCreatedMsg.MaxUses {
+		case 0:
+		case 1:
+			uses = "single use"
+		default:
+			uses = fmt.Sprintf("%d uses", inviteCreatedMsg.MaxUses)
+		}
+		fmt.Printf("*** Invite code %s (%s, expires at %s)\n", inviteCreatedMsg.Code, uses, inviteCreatedMsg.ExpiresAt.AsTime().Local().Format(time.Stamp))
+	}
+}
+
+func QueuePositionHandler() fsm.Callback {
+	return func(e *fsm.Event) {
+		sMsgP, err := extractServerMsg(e)
+		if err != nil {
+			log.Errorf("Cannot extract server msg: %v", err)
+
+			return
+		}
+		var queuePositionMsg pb.ServerMessage_ServerQueuePosition
+		if err := pbutils.UnmarshalAny(sMsgP.Operation, &queuePositionMsg); err != nil {
+			log.Errorf("Unmarshal to queuePosition failed: %v", err)
+
+			return
+		}
+
+		fmt.Printf("*** Room %s is full, you are number %d in the queue\n", queuePositionMsg.Room.GetName(), queuePositionMsg.Position)
+	}
+}
+
 func ShutdownHandler(stream pb.Chat_RouteChatClient, sigint chan<- os.Signal) fsm.Callback {
 	return func(e *fsm.Event) {
+		// The shutdown is either sent by the server, carrying a reason, or
+		// triggered locally by an interrupt.
+		if sMsgP, err := extractServerMsg(e); err == nil {
+			var shutdownMsg pb.ServerMessage_ServerShutdown
+			if err := pbutils.UnmarshalAny(sMsgP.Operation, &shutdownMsg); err == nil && shutdownMsg.Reason != "" {
+				fmt.Printf("*** Disconnected: %s\n", shutdownMsg.Reason)
+			}
+		}
+
 		quitMsg := pb.ClientMessage_ClientQuit{}
 		op, err := pbutils.MarshalAny(&quitMsg)
 		if err != nil {
@@ -122,7 +504,7 @@ func ShutdownHandler(stream pb.Chat_RouteChatClient, sigint chan<- os.Signal) fs
 			Operation: op,
 		}
 
-		if err := stream.Send(&cMsg); err != nil && !errors.As(err, &io.EOF) {
+		if err := stream.Send(&cMsg); err != nil && !errors.Is(err, io.EOF) {
 			log.Errorf("Send failed: %v", err)
 			// TODO end send failure
 			return
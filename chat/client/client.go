@@ -16,9 +16,11 @@ func (c *Client) Shutdown() error {
 	return c.conn.Close()
 }
 
-func NewClient(serverAddr string) (*Client, error) {
-	credentials := grpc.WithTransportCredentials(insecure.NewCredentials())
-	conn, err := grpc.Dial(serverAddr, credentials)
+// NewClient connects to the chat server at serverAddr, in plaintext unless
+// opts configure other transport credentials.
+func NewClient(serverAddr string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	conn, err := grpc.Dial(serverAddr, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,3 +32,8 @@ func NewClient(serverAddr string) (*Client, error) {
 
 	return c, nil
 }
+
+// WithToken sends token as a bearer token with every call.
+func WithToken(token string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(bearerToken(token))
+}
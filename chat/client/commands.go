@@ -0,0 +1,306 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+var errNotACommand = errors.New("not a command")
+
+// ParseRole parses the case-insensitive name of a room role.
+func ParseRole(s string) (pb.Role, error) {
+	for name, value := range pb.Role_value {
+		if pb.Role(value) == pb.Role_Muted {
+			continue
+		}
+		if strings.EqualFold(name, s) {
+			return pb.Role(value), nil
+		}
+	}
+
+	return pb.Role_Member, fmt.Errorf("invalid role %s", s)
+}
+
+// ParseRoomMode parses the case-insensitive name of a room mode.
+func ParseRoomMode(s string) (pb.RoomMode, error) {
+	for name, value := range pb.RoomMode_value {
+		if strings.EqualFold(name, s) {
+			return pb.RoomMode(value), nil
+		}
+	}
+
+	return pb.RoomMode_Normal, fmt.Errorf("invalid room mode %s", s)
+}
+
+// parseCommand parses a line typed by the user starting with a slash into
+// the corresponding client message. It returns errNotACommand for lines that
+// are not commands.
+func parseCommand(line string) (*pb.ClientMessage, error) {
+	if !strings.HasPrefix(line, "/") {
+		return nil, errNotACommand
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "/"))
+	if len(fields) == 0 {
+		return nil, errNotACommand
+	}
+	name, args := fields[0], fields[1:]
+
+	var cmd pb.ClientMessage_ClientCommand
+	var op proto.Message
+	switch name {
+	case "kick":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("usage: /kick USERNAME [REASON]")
+		}
+		cmd = pb.ClientMessage_Kick
+		op = &pb.ClientMessage_ClientKick{
+			Username: args[0],
+			Reason:   strings.Join(args[1:], " "),
+		}
+	case "ban":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("usage: /ban USERNAME [DURATION] [REASON]")
+		}
+		banMsg := &pb.ClientMessage_ClientBan{Username: args[0]}
+		reason := args[1:]
+		if len(reason) > 0 {
+			if d, err := time.ParseDuration(reason[0]); err == nil {
+				banMsg.Duration = durationpb.New(d)
+				reason = reason[1:]
+			}
+		}
+		banMsg.Reason = strings.Join(reason, " ")
+		cmd, op = pb.ClientMessage_Ban, banMsg
+	case "mute", "unmute":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("usage: /%s USERNAME", name)
+		}
+		cmd = pb.ClientMessage_Mute
+		op = &pb.ClientMessage_ClientMute{
+			Username: args[0],
+			Muted:    name == "mute",
+		}
+	case "role":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("usage: /role USERNAME (owner|moderator|member)")
+		}
+		role, err := ParseRole(args[1])
+		if err != nil {
+			return nil, err
+		}
+		cmd = pb.ClientMessage_SetRole
+		op = &pb.ClientMessage_ClientSetRole{
+			Username: args[0],
+			Role:     role,
+		}
+	case "mode":
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("usage: /mode (normal|slow INTERVAL|readonly|announcementonly)")
+		}
+		mode, err := ParseRoomMode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		setRoomModeMsg := &pb.ClientMessage_ClientSetRoomMode{Mode: mode}
+		if len(args) == 2 {
+			d, err := time.ParseDuration(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid slow mode interval: %w", err)
+			}
+			setRoomModeMsg.SlowModeInterval = durationpb.New(d)
+		}
+		cmd, op = pb.ClientMessage_SetRoomMode, setRoomModeMsg
+	case "rooms":
+		cmd, op = pb.ClientMessage_ListRooms, &pb.ClientMessage_ClientListRooms{}
+	case "join":
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("usage: /join ROOM [INVITE_CODE]")
+		}
+		joinRoomMsg := &pb.ClientMessage_ClientJoinRoom{Room: args[0]}
+		if len(args) == 2 {
+			joinRoomMsg.InviteCode = args[1]
+		}
+		cmd, op = pb.ClientMessage_JoinRoom, joinRoomMsg
+	case "accept":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("usage: /accept INVITE_CODE")
+		}
+		cmd, op = pb.ClientMessage_JoinRoom, &pb.ClientMessage_ClientJoinRoom{InviteCode: args[0]}
+	case "invite":
+		if len(args) > 2 {
+			return nil, fmt.Errorf("usage: /invite [MAX_USES] [TTL]")
+		}
+		createInviteMsg := &pb.ClientMessage_ClientCreateInvite{}
+		if len(args) > 0 {
+			maxUses, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid invite uses: %w", err)
+			}
+			createInviteMsg.MaxUses = uint32(maxUses)
+		}
+		if len(args) > 1 {
+			d, err := time.ParseDuration(args[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid invite ttl: %w", err)
+			}
+			createInviteMsg.Ttl = durationpb.New(d)
+		}
+		cmd, op = pb.ClientMessage_CreateInvite, createInviteMsg
+	case "info":
+		cmd, op = pb.ClientMessage_GetRoomInfo, &pb.ClientMessage_ClientGetRoomInfo{}
+	case "topic":
+		// Without arguments, the topic is cleared.
+		cmd, op = pb.ClientMessage_SetTopic, &pb.ClientMessage_ClientSetTopic{Topic: strings.Join(args, " ")}
+	case "desc":
+		cmd, op = pb.ClientMessage_SetDescription, &pb.ClientMessage_ClientSetDescription{Description: strings.Join(args, " ")}
+	case "label":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("usage: /label KEY [VALUE]")
+		}
+		cmd = pb.ClientMessage_SetLabel
+		op = &pb.ClientMessage_ClientSetLabel{
+			Key:   args[0],
+			Value: strings.Join(args[1:], " "),
+		}
+	case "msg":
+		if len(args) < 2 {
+			return nil, fmt.Errorf("usage: /msg USERNAME TEXT")
+		}
+		cmd = pb.ClientMessage_DirectMessage
+		op = &pb.ClientMessage_ClientDirectMessage{
+			To:   args[0],
+			Body: strings.Join(args[1:], " "),
+		}
+	case "read", "seen":
+		if len(args) > 2 {
+			return nil, fmt.Errorf("usage: /%s [MESSAGE_ID] [ROOM]", name)
+		}
+		markReadMsg := &pb.ClientMessage_ClientMarkRead{Share: name == "seen"}
+		if len(args) > 0 {
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid message id: %w", err)
+			}
+			markReadMsg.MessageId = id
+		}
+		if len(args) > 1 {
+			markReadMsg.Room = args[1]
+		}
+		cmd, op = pb.ClientMessage_MarkRead, markReadMsg
+	case "search":
+		req, err := parseSearch(args)
+		if err != nil {
+			return nil, err
+		}
+		cmd, op = pb.ClientMessage_Search, &pb.ClientMessage_ClientSearch{Request: req}
+	case "history":
+		req, err := parseHistory(args)
+		if err != nil {
+			return nil, err
+		}
+		cmd, op = pb.ClientMessage_GetHistory, &pb.ClientMessage_ClientGetHistory{Request: req}
+	default:
+		return nil, fmt.Errorf("unknown command /%s", name)
+	}
+
+	operation, err := pbutils.MarshalAny(op)
+	if err != nil {
+		return nil, fmt.Errorf("marshal from %s failed: %w", cmd, err)
+	}
+
+	return &pb.ClientMessage{
+		Command:   cmd,
+		Operation: operation,
+	}, nil
+}
+
+// parseSearch parses the arguments of /search: keywords, mixed with the
+// from:USERNAME, in:ROOM, since:DURATION, until:DURATION and page:TOKEN
+// filters. The durations are relative to now.
+func parseSearch(args []string) (*pb.SearchRequest, error) {
+	req := &pb.SearchRequest{}
+	var keywords []string
+	for _, arg := range args {
+		i := strings.Index(arg, ":")
+		if i < 0 {
+			keywords = append(keywords, arg)
+
+			continue
+		}
+		key, value := arg[:i], arg[i+1:]
+		switch key {
+		case "from":
+			req.Author = value
+		case "in":
+			req.Room = value
+		case "page":
+			req.PageToken = value
+		case "since", "until":
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s duration: %w", key, err)
+			}
+			ts := timestamppb.New(time.Now().Add(-d))
+			if key == "since" {
+				req.Since = ts
+			} else {
+				req.Until = ts
+			}
+		default:
+			keywords = append(keywords, arg)
+		}
+	}
+	if len(keywords) == 0 && req.Author == "" && req.Room == "" && req.Since == nil && req.Until == nil {
+		return nil, fmt.Errorf("usage: /search [from:USERNAME] [in:ROOM] [since:DURATION] [until:DURATION] [page:TOKEN] KEYWORDS")
+	}
+	req.Query = strings.Join(keywords, " ")
+
+	return req, nil
+}
+
+// parseHistory parses the arguments of /history: the in:ROOM, before:ID,
+// after:ID, page:CURSOR and size:N options. Without them, it reads the last
+// messages of the current room.
+func parseHistory(args []string) (*pb.GetHistoryRequest, error) {
+	req := &pb.GetHistoryRequest{}
+	for _, arg := range args {
+		i := strings.Index(arg, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("usage: /history [in:ROOM] [before:ID|after:ID|page:CURSOR] [size:N]")
+		}
+		key, value := arg[:i], arg[i+1:]
+		switch key {
+		case "in":
+			req.Room = value
+		case "page":
+			req.Anchor = &pb.GetHistoryRequest_Cursor{Cursor: value}
+		case "before", "after", "size":
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			switch key {
+			case "before":
+				req.Anchor = &pb.GetHistoryRequest_BeforeId{BeforeId: n}
+			case "after":
+				req.Anchor = &pb.GetHistoryRequest_AfterId{AfterId: n}
+			default:
+				req.PageSize = uint32(n)
+			}
+		default:
+			return nil, fmt.Errorf("unknown /history option %s", key)
+		}
+	}
+
+	return req, nil
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestParseCommand(t *testing.T) {
+	testsTable := []struct {
+		Name    string
+		Line    string
+		WantCmd pb.ClientMessage_ClientCommand
+		WantOp  proto.Message
+		WantErr bool
+	}{
+		{
+			Name:    "kick with reason",
+			Line:    "/kick bob stop it",
+			WantCmd: pb.ClientMessage_Kick,
+			WantOp:  &pb.ClientMessage_ClientKick{Username: "bob", Reason: "stop it"},
+		},
+		{
+			Name:    "ban with duration",
+			Line:    "/ban bob 10m spam",
+			WantCmd: pb.ClientMessage_Ban,
+			WantOp:  &pb.ClientMessage_ClientBan{Username: "bob", Duration: durationpb.New(10 * time.Minute), Reason: "spam"},
+		},
+		{
+			Name:    "ban forever",
+			Line:    "/ban bob spam",
+			WantCmd: pb.ClientMessage_Ban,
+			WantOp:  &pb.ClientMessage_ClientBan{Username: "bob", Reason: "spam"},
+		},
+		{
+			Name:    "unmute",
+			Line:    "/unmute bob",
+			WantCmd: pb.ClientMessage_Mute,
+			WantOp:  &pb.ClientMessage_ClientMute{Username: "bob"},
+		},
+		{
+			Name:    "role",
+			Line:    "/role bob MODERATOR",
+			WantCmd: pb.ClientMessage_SetRole,
+			WantOp:  &pb.ClientMessage_ClientSetRole{Username: "bob", Role: pb.Role_Moderator},
+		},
+		{
+			Name:    "muted is not a role",
+			Line:    "/role bob muted",
+			WantErr: true,
+		},
+		{
+			Name:    "slow mode",
+			Line:    "/mode slow 30s",
+			WantCmd: pb.ClientMessage_SetRoomMode,
+			WantOp:  &pb.ClientMessage_ClientSetRoomMode{Mode: pb.RoomMode_Slow, SlowModeInterval: durationpb.New(30 * time.Second)},
+		},
+		{
+			Name:    "read-only mode",
+			Line:    "/mode readonly",
+			WantCmd: pb.ClientMessage_SetRoomMode,
+			WantOp:  &pb.ClientMessage_ClientSetRoomMode{Mode: pb.RoomMode_ReadOnly},
+		},
+		{
+			Name:    "join with invite",
+			Line:    "/join secret ABCD",
+			WantCmd: pb.ClientMessage_JoinRoom,
+			WantOp:  &pb.ClientMessage_ClientJoinRoom{Room: "secret", InviteCode: "ABCD"},
+		},
+		{
+			Name:    "accept invite",
+			Line:    "/accept ABCD",
+			WantCmd: pb.ClientMessage_JoinRoom,
+			WantOp:  &pb.ClientMessage_ClientJoinRoom{InviteCode: "ABCD"},
+		},
+		{
+			Name:    "single-use invite",
+			Line:    "/invite 1 1h",
+			WantCmd: pb.ClientMessage_CreateInvite,
+			WantOp:  &pb.ClientMessage_ClientCreateInvite{MaxUses: 1, Ttl: durationpb.New(time.Hour)},
+		},
+		{
+			Name:    "topic",
+			Line:    "/topic release day",
+			WantCmd: pb.ClientMessage_SetTopic,
+			WantOp:  &pb.ClientMessage_ClientSetTopic{Topic: "release day"},
+		},
+		{
+			Name:    "remove label",
+			Line:    "/label team",
+			WantCmd: pb.ClientMessage_SetLabel,
+			WantOp:  &pb.ClientMessage_ClientSetLabel{Key: "team"},
+		},
+		{
+			Name:    "direct message",
+			Line:    "/msg bob see you at 5",
+			WantCmd: pb.ClientMessage_DirectMessage,
+			WantOp:  &pb.ClientMessage_ClientDirectMessage{To: "bob", Body: "see you at 5"},
+		},
+		{
+			Name:    "shared read marker",
+			Line:    "/seen 42 lobby",
+			WantCmd: pb.ClientMessage_MarkRead,
+			WantOp:  &pb.ClientMessage_ClientMarkRead{Room: "lobby", MessageId: 42, Share: true},
+		},
+		{
+			Name:    "search",
+			Line:    "/search from:alice make in:dev test_race page:42",
+			WantCmd: pb.ClientMessage_Search,
+			WantOp: &pb.ClientMessage_ClientSearch{Request: &pb.SearchRequest{
+				Query:     "make test_race",
+				Author:    "alice",
+				Room:      "dev",
+				PageToken: "42",
+			}},
+		},
+		{
+			Name:    "history page",
+			Line:    "/history in:dev page:before:42 size:10",
+			WantCmd: pb.ClientMessage_GetHistory,
+			WantOp: &pb.ClientMessage_ClientGetHistory{Request: &pb.GetHistoryRequest{
+				Room:     "dev",
+				Anchor:   &pb.GetHistoryRequest_Cursor{Cursor: "before:42"},
+				PageSize: 10,
+			}},
+		},
+		{
+			Name:    "invalid role",
+			Line:    "/role bob admin",
+			WantErr: true,
+		},
+		{
+			Name:    "unknown command",
+			Line:    "/dance",
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			cMsgP, err := parseCommand(tt.Line)
+			if tt.WantErr {
+				if err == nil {
+					t.Errorf("parseCommand(%q) succeeded; want error", tt.Line)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCommand(%q) failed: %v", tt.Line, err)
+			}
+			if cMsgP.Command != tt.WantCmd {
+				t.Errorf("parseCommand(%q).Command=%s; want %s", tt.Line, cMsgP.Command, tt.WantCmd)
+			}
+			op := proto.Clone(tt.WantOp)
+			op.Reset()
+			if err := pbutils.UnmarshalAny(cMsgP.Operation, op); err != nil {
+				t.Fatalf("UnmarshalAny failed: %v", err)
+			}
+			if !proto.Equal(op, tt.WantOp) {
+				t.Errorf("parseCommand(%q).Operation=%v; want %v", tt.Line, op, tt.WantOp)
+			}
+		})
+	}
+}
+
+func TestParseCommandNotACommand(t *testing.T) {
+	if _, err := parseCommand("hello"); !errors.Is(err, errNotACommand) {
+		t.Errorf("parseCommand(hello)=%v; want %v", err, errNotACommand)
+	}
+}
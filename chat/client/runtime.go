@@ -18,15 +18,45 @@ func Run(author string, stream pb.Chat_RouteChatClient, sigint chan os.Signal, s
 		"booting",
 		fsm.Events{
 			{Name: "pair", Src: []string{"booting"}, Dst: "pairing"},
-			{Name: pb.ServerMessage_ConfirmRoomCheckout.String(), Src: []string{"pairing"}, Dst: "ready"},
+			{Name: pb.ServerMessage_QueuePosition.String(), Src: []string{"pairing", "waiting"}, Dst: "waiting"},
+			{Name: pb.ServerMessage_QueuePosition.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_ConfirmRoomCheckout.String(), Src: []string{"pairing", "waiting"}, Dst: "ready"},
+			{Name: pb.ServerMessage_ConfirmRoomCheckout.String(), Src: []string{"ready"}, Dst: "receiving"},
 			{Name: pb.ServerMessage_ForwardMessage.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_Announcement.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_Error.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_RoomState.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_RoomList.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_InviteCreated.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_RoomDetails.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_TopicChanged.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_DirectMessage.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_DirectMessage.String(), Src: []string{"waiting"}, Dst: "waiting"},
+			{Name: pb.ServerMessage_Mention.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_Mention.String(), Src: []string{"waiting"}, Dst: "waiting"},
+			{Name: pb.ServerMessage_ReadReceipt.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_SearchResults.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ServerMessage_History.String(), Src: []string{"ready"}, Dst: "receiving"},
 			{Name: "readyAgain", Src: []string{"receiving"}, Dst: "ready"},
-			{Name: pb.ServerMessage_Shutdown.String(), Src: []string{"booting", "pairing", "ready", "receiving"}, Dst: "closed"},
+			{Name: pb.ServerMessage_Shutdown.String(), Src: []string{"booting", "pairing", "waiting", "ready", "receiving"}, Dst: "closed"},
 		},
 		fsm.Callbacks{
 			"after_pair": PairHandler(stream, author),
 			utils.AfterEvent(pb.ServerMessage_ConfirmRoomCheckout): ConfirmRoomHandler(stream, sigint),
 			utils.AfterEvent(pb.ServerMessage_ForwardMessage):      ForwardMessageHandler(author),
+			utils.AfterEvent(pb.ServerMessage_Announcement):        AnnouncementHandler(),
+			utils.AfterEvent(pb.ServerMessage_Error):               ErrorHandler(),
+			utils.AfterEvent(pb.ServerMessage_RoomState):           RoomStateHandler(),
+			utils.AfterEvent(pb.ServerMessage_RoomList):            RoomListHandler(),
+			utils.AfterEvent(pb.ServerMessage_InviteCreated):       InviteCreatedHandler(),
+			utils.AfterEvent(pb.ServerMessage_QueuePosition):       QueuePositionHandler(),
+			utils.AfterEvent(pb.ServerMessage_RoomDetails):         RoomDetailsHandler(),
+			utils.AfterEvent(pb.ServerMessage_TopicChanged):        TopicChangedHandler(),
+			utils.AfterEvent(pb.ServerMessage_DirectMessage):       DirectMessageHandler(),
+			utils.AfterEvent(pb.ServerMessage_Mention):             MentionHandler(),
+			utils.AfterEvent(pb.ServerMessage_ReadReceipt):         ReadReceiptHandler(author),
+			utils.AfterEvent(pb.ServerMessage_SearchResults):       SearchResultsHandler(),
+			utils.AfterEvent(pb.ServerMessage_History):             HistoryHandler(),
 			utils.AfterEvent(pb.ServerMessage_Shutdown):            ShutdownHandler(stream, sigint),
 		},
 	)
@@ -48,7 +78,7 @@ func Run(author string, stream pb.Chat_RouteChatClient, sigint chan os.Signal, s
 	g.Go(func() error {
 		for {
 			sMsgP, err := stream.Recv()
-			if errors.As(err, &io.EOF) {
+			if errors.Is(err, io.EOF) {
 				return nil
 			}
 			if err != nil {
@@ -57,7 +87,9 @@ func Run(author string, stream pb.Chat_RouteChatClient, sigint chan os.Signal, s
 
 			cmd := sMsgP.Command.String()
 			log.Debugf("Got %s", cmd)
-			if err := sm.Event(cmd, sMsgP); err != nil {
+			// The position updates keep the waiting state.
+			var noTransitionErr fsm.NoTransitionError
+			if err := sm.Event(cmd, sMsgP); err != nil && !errors.As(err, &noTransitionErr) {
 				log.Errorf("Failed to submit %s: %v", cmd, err)
 			}
 			if sm.Current() == "receiving" {
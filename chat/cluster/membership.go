@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ProbeFunc checks whether a member is alive.
+type ProbeFunc func(ctx context.Context, m Member) error
+
+// Membership tracks the members alive among a static list, probing them
+// periodically. The members are assumed alive until a probe fails. Its
+// methods are safe for concurrent use.
+type Membership struct {
+	self  Member
+	peers []Member
+	probe ProbeFunc
+	// onChange is called with the new ring after each change, from the
+	// goroutine making it.
+	onChange func(*Ring)
+	log      log.FieldLogger
+
+	// alive and ring are guarded by mu.
+	alive map[string]bool
+	ring  *Ring
+	mu    sync.Mutex
+}
+
+func (m *Membership) Self() Member {
+	return m.self
+}
+
+// Ring returns the ring of the members alive, including self.
+func (m *Membership) Ring() *Ring {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.ring
+}
+
+// Lookup returns the member with the given id, alive or not.
+func (m *Membership) Lookup(id string) (Member, bool) {
+	if id == m.self.ID {
+		return m.self, true
+	}
+	for _, p := range m.peers {
+		if p.ID == id {
+			return p, true
+		}
+	}
+
+	return Member{}, false
+}
+
+// SetAlive records whether the peer with the given id is alive. It reports
+// whether the ring changed.
+func (m *Membership) SetAlive(id string, alive bool) bool {
+	m.mu.Lock()
+	if _, ok := m.alive[id]; !ok || m.alive[id] == alive {
+		m.mu.Unlock()
+
+		return false
+	}
+	m.alive[id] = alive
+	members := []Member{m.self}
+	for _, p := range m.peers {
+		if m.alive[p.ID] {
+			members = append(members, p)
+		}
+	}
+	ring := NewRing(members, DefaultVirtualNodes)
+	m.ring = ring
+	m.mu.Unlock()
+
+	if alive {
+		m.log.Infof("Cluster member %s joined", id)
+	} else {
+		m.log.Warnf("Cluster member %s left", id)
+	}
+	if m.onChange != nil {
+		m.onChange(ring)
+	}
+
+	return true
+}
+
+// Run probes the peers every interval until ctx is done.
+func (m *Membership) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			m.probeAll(ctx, interval)
+		}
+	}
+}
+
+func (m *Membership) probeAll(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+	results := make([]error, len(m.peers))
+	for i, p := range m.peers {
+		wg.Add(1)
+		go func(i int, p Member) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			results[i] = m.probe(probeCtx, p)
+		}(i, p)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+	for i, p := range m.peers {
+		if results[i] != nil {
+			m.log.Debugf("Probing %s failed: %v", p, results[i])
+		}
+		m.SetAlive(p.ID, results[i] == nil)
+	}
+}
+
+// NewMembership tracks the peers of self, skipping self if listed. The
+// ring changes are notified to onChange, when not nil, and logged to logger.
+func NewMembership(self Member, peers []Member, probe ProbeFunc, onChange func(*Ring), logger log.FieldLogger) *Membership {
+	m := &Membership{
+		self:     self,
+		probe:    probe,
+		onChange: onChange,
+		log:      logger,
+		alive:    make(map[string]bool),
+	}
+	members := []Member{self}
+	for _, p := range peers {
+		if p.ID == self.ID {
+			continue
+		}
+		m.peers = append(m.peers, p)
+		m.alive[p.ID] = true
+		members = append(members, p)
+	}
+	m.ring = NewRing(members, DefaultVirtualNodes)
+
+	return m
+}
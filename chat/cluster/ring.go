@@ -0,0 +1,91 @@
+// Package cluster assigns the rooms to the nodes of a cluster of chat
+// servers, through consistent hashing over the members alive.
+package cluster
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sort"
+)
+
+// DefaultVirtualNodes is the number of points of each member on the ring.
+const DefaultVirtualNodes = 64
+
+// Member is a node of the cluster.
+type Member struct {
+	// ID identifies the member on the ring, it must be stable across
+	// restarts.
+	ID string
+	// Addr is the address of the cluster service of the member.
+	Addr string
+}
+
+func (m Member) String() string {
+	return fmt.Sprintf("%s(%s)", m.ID, m.Addr)
+}
+
+// Ring maps keys to members by consistent hashing: adding or removing a
+// member only moves the keys it gains or loses. A Ring is immutable.
+type Ring struct {
+	members []Member
+	// points is sorted, owners[i] owns the keys hashed up to points[i].
+	points []uint32
+	owners []Member
+}
+
+// Owner returns the member owning key. It panics when the ring is empty.
+func (r *Ring) Owner(key string) Member {
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.owners[i]
+}
+
+// Members returns the members of the ring, sorted by ID.
+func (r *Ring) Members() []Member {
+	return append([]Member(nil), r.members...)
+}
+
+func (r *Ring) Len() int {
+	return len(r.members)
+}
+
+// NewRing places members on the ring, each with vnodes points.
+func NewRing(members []Member, vnodes int) *Ring {
+	r := &Ring{members: append([]Member(nil), members...)}
+	sort.Slice(r.members, func(i, j int) bool {
+		return r.members[i].ID < r.members[j].ID
+	})
+	type point struct {
+		hash  uint32
+		owner Member
+	}
+	points := make([]point, 0, len(members)*vnodes)
+	for _, m := range r.members {
+		for i := 0; i < vnodes; i++ {
+			points = append(points, point{
+				hash:  crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s#%d", m.ID, i))),
+				owner: m,
+			})
+		}
+	}
+	// The ties are broken by ID, so that every member builds the same ring.
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+
+		return points[i].owner.ID < points[j].owner.ID
+	})
+	for _, p := range points {
+		r.points = append(r.points, p.hash)
+		r.owners = append(r.owners, p.owner)
+	}
+
+	return r
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRingOwner(t *testing.T) {
+	a, b, c := Member{ID: "a"}, Member{ID: "b"}, Member{ID: "c"}
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("room-%d", i)
+	}
+
+	testsTable := []struct {
+		Name   string
+		Before []Member
+		After  []Member
+		// Moved is the member whose keys are the only ones allowed to move.
+		Moved Member
+	}{
+		{
+			Name:   "member joins",
+			Before: []Member{a, b},
+			After:  []Member{a, b, c},
+			Moved:  c,
+		},
+		{
+			Name:   "member leaves",
+			Before: []Member{a, b, c},
+			After:  []Member{c, a},
+			Moved:  b,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			before := NewRing(tt.Before, DefaultVirtualNodes)
+			after := NewRing(tt.After, DefaultVirtualNodes)
+			owned := make(map[string]int)
+			for _, key := range keys {
+				from, to := before.Owner(key), after.Owner(key)
+				owned[to.ID]++
+				if from != to && from != tt.Moved && to != tt.Moved {
+					t.Errorf("%s moved from %s to %s", key, from.ID, to.ID)
+				}
+			}
+			// Each member owns a fair share of the keys.
+			for _, m := range tt.After {
+				if share := owned[m.ID] * len(tt.After); share < len(keys)/2 || share > 2*len(keys) {
+					t.Errorf("%s owns %d keys out of %d", m.ID, owned[m.ID], len(keys))
+				}
+			}
+		})
+	}
+}
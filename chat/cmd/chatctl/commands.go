@@ -0,0 +1,366 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/savo92/playground-go-grpc/chat/client"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func roomsList(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	if len(args) != 0 {
+		return nil, errUsage
+	}
+
+	return c.ListRooms(ctx, &pb.ListRoomsRequest{})
+}
+
+func roomsCreate(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("rooms create", flag.ContinueOnError)
+	owner := fs.String("owner", "", "The username granted the owner role.")
+	private := fs.Bool("private", false, "Hide the room from listings and require an invite to join.")
+	capacity := fs.Uint("capacity", 0, "The maximum number of participants, 0 means unlimited.")
+	queue := fs.Bool("queue", false, "Queue the participants joining the full room instead of rejecting them.")
+	topic := fs.String("topic", "", "The topic of the room.")
+	description := fs.String("description", "", "The description of the room.")
+	labels := labelsFlag{}
+	fs.Var(labels, "label", "A KEY=VALUE label of the room, can be repeated.")
+	ephemeral := fs.Bool("ephemeral", false, "Close the room after being empty for a while.")
+	emptyTTL := fs.Duration("empty-ttl", 0, "How long the ephemeral room is kept while empty, 0 uses the server default.")
+	retention := retentionFlags(fs, "retention-")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return nil, errUsage
+	}
+	req := &pb.CreateRoomRequest{
+		Name:          fs.Arg(0),
+		Owner:         *owner,
+		Capacity:      uint32(*capacity),
+		QueueWhenFull: *queue,
+		Topic:         *topic,
+		Description:   *description,
+		Labels:        labels,
+		Retention:     retention(),
+	}
+	if *private {
+		req.Visibility = pb.RoomVisibility_Private
+	}
+	if *ephemeral {
+		req.Lifecycle = pb.RoomLifecycle_Ephemeral
+		if *emptyTTL > 0 {
+			req.EmptyTtl = durationpb.New(*emptyTTL)
+		}
+	}
+
+	return c.CreateRoom(ctx, req)
+}
+
+func roomsUpdate(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("rooms update", flag.ContinueOnError)
+	topic := fs.String("topic", "", "The new topic of the room, empty to clear it.")
+	description := fs.String("description", "", "The new description of the room, empty to clear it.")
+	labels := labelsFlag{}
+	fs.Var(labels, "label", "A KEY=VALUE label to set, or KEY= to remove it, can be repeated.")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return nil, errUsage
+	}
+	req := &pb.UpdateRoomMetadataRequest{RoomId: fs.Arg(0), Labels: labels}
+	// Only the flags given on the command line are updated.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "topic":
+			req.Topic = topic
+		case "description":
+			req.Description = description
+		}
+	})
+
+	return c.UpdateRoomMetadata(ctx, req)
+}
+
+func roomsRetention(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("rooms retention", flag.ContinueOnError)
+	retention := retentionFlags(fs, "")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return nil, errUsage
+	}
+
+	return c.SetRetention(ctx, &pb.SetRetentionRequest{RoomId: fs.Arg(0), Retention: retention()})
+}
+
+// retentionFlags defines the flags of a retention, named after prefix. The
+// returned function builds the retention once the flags are parsed, nil when
+// no limit is set.
+func retentionFlags(fs *flag.FlagSet, prefix string) func() *pb.Retention {
+	maxAge := fs.Duration(prefix+"max-age", 0, "Drop the messages older than this, 0 disables the limit.")
+	maxMessages := fs.Uint(prefix+"max-messages", 0, "Keep at most this many messages, 0 disables the limit.")
+	maxBytes := fs.Uint64(prefix+"max-bytes", 0, "Keep at most this many bytes of messages, 0 disables the limit.")
+
+	return func() *pb.Retention {
+		if *maxAge == 0 && *maxMessages == 0 && *maxBytes == 0 {
+			return nil
+		}
+		retention := &pb.Retention{
+			MaxMessages: uint32(*maxMessages),
+			MaxBytes:    *maxBytes,
+		}
+		if *maxAge > 0 {
+			retention.MaxAge = durationpb.New(*maxAge)
+		}
+
+		return retention
+	}
+}
+
+func historyPurge(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("history purge", flag.ContinueOnError)
+	roomID := fs.String("room", "", "Purge the history of this room.")
+	author := fs.String("author", "", "Purge the messages written by this username.")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 || (*roomID == "") == (*author == "") {
+		return nil, errUsage
+	}
+	req := &pb.PurgeHistoryRequest{}
+	if *roomID != "" {
+		req.Target = &pb.PurgeHistoryRequest_RoomId{RoomId: *roomID}
+	} else {
+		req.Target = &pb.PurgeHistoryRequest_Author{Author: *author}
+	}
+
+	return c.PurgeHistory(ctx, req)
+}
+
+var transcriptFormats = map[string]pb.TranscriptFormat{
+	"jsonl":    pb.TranscriptFormat_JSONLines,
+	"markdown": pb.TranscriptFormat_Markdown,
+	"html":     pb.TranscriptFormat_HTML,
+}
+
+// historyExport writes the transcript itself, so that it returns no message
+// to print.
+func historyExport(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("history export", flag.ContinueOnError)
+	since := fs.String("since", "", "Export the messages sent since this time, RFC 3339 or a duration ago.")
+	until := fs.String("until", "", "Export the messages sent before this time, RFC 3339 or a duration ago.")
+	format := fs.String("format", "jsonl", "The format of the transcript, jsonl, markdown or html.")
+	out := fs.String("out", "", "The file to write the transcript to. Default: the standard output")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return nil, errUsage
+	}
+	req := &pb.ExportTranscriptRequest{RoomId: fs.Arg(0)}
+	f, ok := transcriptFormats[*format]
+	if !ok {
+		return nil, fmt.Errorf("invalid transcript format %s", *format)
+	}
+	req.Format = f
+	var err error
+	if req.Since, err = parseTime(*since); err != nil {
+		return nil, fmt.Errorf("invalid since: %w", err)
+	}
+	if req.Until, err = parseTime(*until); err != nil {
+		return nil, fmt.Errorf("invalid until: %w", err)
+	}
+
+	stream, err := c.ExportTranscript(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// The first chunk reports the errors of the request before the file is
+	// created.
+	chunk, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	file := os.Stdout
+	if *out != "" {
+		if file, err = os.Create(*out); err != nil {
+			return nil, err
+		}
+		defer file.Close()
+	}
+	for ; chunk != nil; chunk, err = stream.Recv() {
+		if _, err := file.Write(chunk.Data); err != nil {
+			return nil, err
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if *out != "" {
+		return nil, file.Close()
+	}
+
+	return nil, nil
+}
+
+// parseTime parses an RFC 3339 time or a duration before now, nil when s is
+// empty.
+func parseTime(s string) (*timestamppb.Timestamp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return timestamppb.New(t), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither an RFC 3339 time nor a duration", s)
+	}
+
+	return timestamppb.New(time.Now().Add(-d)), nil
+}
+
+// labelsFlag collects repeated KEY=VALUE flags.
+type labelsFlag map[string]string
+
+func (l labelsFlag) String() string {
+	return fmt.Sprint(map[string]string(l))
+}
+
+func (l labelsFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("invalid label %q, want KEY=VALUE", s)
+	}
+	l[s[:i]] = s[i+1:]
+
+	return nil
+}
+
+func roomsClose(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	if len(args) != 1 {
+		return nil, errUsage
+	}
+
+	return c.CloseRoom(ctx, &pb.CloseRoomRequest{RoomId: args[0]})
+}
+
+func roomsArchived(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	if len(args) != 0 {
+		return nil, errUsage
+	}
+
+	return c.ListArchivedRooms(ctx, &pb.ListArchivedRoomsRequest{})
+}
+
+func roomsMode(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errUsage
+	}
+	mode, err := client.ParseRoomMode(args[1])
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.SetRoomModeRequest{
+		RoomId: args[0],
+		Mode:   mode,
+	}
+	if len(args) == 3 {
+		d, err := time.ParseDuration(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid slow mode interval: %w", err)
+		}
+		req.SlowModeInterval = durationpb.New(d)
+	}
+
+	return c.SetRoomMode(ctx, req)
+}
+
+func usersList(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("users list", flag.ContinueOnError)
+	roomID := fs.String("room", "", "Only list the participants of this room.")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return nil, errUsage
+	}
+
+	return c.ListParticipants(ctx, &pb.ListParticipantsRequest{RoomId: *roomID})
+}
+
+func usersRole(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("users role", flag.ContinueOnError)
+	roomID := fs.String("room", "", "The room the role is granted in.")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 2 || *roomID == "" {
+		return nil, errUsage
+	}
+	role, err := client.ParseRole(fs.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SetRole(ctx, &pb.SetRoleRequest{
+		RoomId:   *roomID,
+		Username: fs.Arg(0),
+		Role:     role,
+	})
+}
+
+func usersMute(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	return setMuted(ctx, c, "users mute", args, true)
+}
+
+func usersUnmute(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	return setMuted(ctx, c, "users unmute", args, false)
+}
+
+func setMuted(ctx context.Context, c *client.AdminClient, name string, args []string, muted bool) (proto.Message, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	roomID := fs.String("room", "", "The room the user is muted in.")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 || *roomID == "" {
+		return nil, errUsage
+	}
+
+	return c.SetMuted(ctx, &pb.SetMutedRequest{
+		RoomId:   *roomID,
+		Username: fs.Arg(0),
+		Muted:    muted,
+	})
+}
+
+func usersKick(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("users kick", flag.ContinueOnError)
+	id := fs.String("id", "", "The id of the participant to disconnect.")
+	username := fs.String("username", "", "Disconnect every participant with this username.")
+	reason := fs.String("reason", "", "The reason shown to the disconnected participant.")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return nil, errUsage
+	}
+	if (*id == "") == (*username == "") {
+		return nil, fmt.Errorf("exactly one of -id and -username is required")
+	}
+
+	return c.DisconnectParticipant(ctx, &pb.DisconnectParticipantRequest{
+		ParticipantId: *id,
+		Username:      *username,
+		Reason:        *reason,
+	})
+}
+
+func announce(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	fs := flag.NewFlagSet("announce", flag.ContinueOnError)
+	roomID := fs.String("room", "", "Only announce to this room.")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		return nil, errUsage
+	}
+
+	return c.Announce(ctx, &pb.AnnounceRequest{
+		RoomId: *roomID,
+		Body:   strings.Join(fs.Args(), " "),
+	})
+}
+
+func stats(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error) {
+	if len(args) != 0 {
+		return nil, errUsage
+	}
+
+	return c.GetStats(ctx, &pb.GetStatsRequest{})
+}
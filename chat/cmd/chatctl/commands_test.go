@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommandArguments(t *testing.T) {
+	testsTable := []struct {
+		Command string
+		Args    []string
+		// WantErr is the error returned before calling the server, any error
+		// when nil.
+		WantErr error
+	}{
+		{Command: "rooms list", Args: []string{"news"}, WantErr: errUsage},
+		{Command: "rooms create", Args: []string{"-topic", "News"}, WantErr: errUsage},
+		{Command: "rooms create", Args: []string{"-label", "team", "news"}, WantErr: errUsage},
+		{Command: "rooms close", WantErr: errUsage},
+		{Command: "rooms mode", Args: []string{"1"}, WantErr: errUsage},
+		{Command: "rooms mode", Args: []string{"1", "loud"}},
+		{Command: "rooms mode", Args: []string{"1", "slow", "often"}},
+		{Command: "users role", Args: []string{"alice", "owner"}, WantErr: errUsage},
+		{Command: "users mute", Args: []string{"-room", "1"}, WantErr: errUsage},
+		{Command: "users kick", Args: []string{"-id", "p1", "-username", "alice"}},
+		{Command: "users kick", Args: []string{"alice"}, WantErr: errUsage},
+		{Command: "history purge", Args: []string{"-room", "1", "-author", "alice"}, WantErr: errUsage},
+		{Command: "history export", Args: []string{"-format", "pdf", "1"}},
+		{Command: "history export", Args: []string{"-since", "yesterday", "1"}},
+		{Command: "announce", Args: []string{"-room", "1"}, WantErr: errUsage},
+		{Command: "stats", Args: []string{"now"}, WantErr: errUsage},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Command+" "+strings.Join(tt.Args, " "), func(t *testing.T) {
+			cmd, ok := commands[tt.Command]
+			if !ok {
+				t.Fatalf("no command %s", tt.Command)
+			}
+			// The arguments are rejected before the client is used.
+			_, err := cmd.run(context.Background(), nil, tt.Args)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if tt.WantErr != nil && !errors.Is(err, tt.WantErr) {
+				t.Errorf("got error %v, want %v", err, tt.WantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/savo92/playground-go-grpc/chat/client"
+)
+
+const (
+	defaultAdminAddr = "localhost:8082"
+	defaultOutput    = "table"
+	defaultTimeout   = 10 * time.Second
+)
+
+var (
+	adminAddr = flag.String("admin-addr", defaultAdminAddr, fmt.Sprintf("The address of the chat admin server. Default: %s", defaultAdminAddr))
+	token     = flag.String("token", os.Getenv("CHAT_ADMIN_TOKEN"), "The admin bearer token. Default: $CHAT_ADMIN_TOKEN")
+	output    = flag.String("o", defaultOutput, fmt.Sprintf("The output format, table or json. Default: %s", defaultOutput))
+	timeout   = flag.Duration("timeout", defaultTimeout, fmt.Sprintf("The timeout of the admin call. Default: %s", defaultTimeout))
+)
+
+var errUsage = errors.New("usage")
+
+// command is a chatctl subcommand. run receives the arguments following the
+// command name, and returns the response to print, nil when the command
+// writes its own output.
+type command struct {
+	usage string
+	run   func(ctx context.Context, c *client.AdminClient, args []string) (proto.Message, error)
+}
+
+var commands = map[string]command{
+	"rooms list":      {usage: "rooms list", run: roomsList},
+	"rooms create":    {usage: "rooms create [-owner USERNAME] [-private] [-capacity N [-queue]] [-topic TOPIC] [-description TEXT] [-label KEY=VALUE]... [-ephemeral [-empty-ttl TTL]] [-retention-max-age AGE] [-retention-max-messages N] [-retention-max-bytes N] NAME", run: roomsCreate},
+	"rooms update":    {usage: "rooms update [-topic TOPIC] [-description TEXT] [-label KEY=[VALUE]]... ROOM_ID", run: roomsUpdate},
+	"rooms retention": {usage: "rooms retention [-max-age AGE] [-max-messages N] [-max-bytes N] ROOM_ID", run: roomsRetention},
+	"rooms close":     {usage: "rooms close ROOM_ID", run: roomsClose},
+	"rooms archived":  {usage: "rooms archived", run: roomsArchived},
+	"rooms mode":      {usage: "rooms mode ROOM_ID (normal|slow INTERVAL|readonly|announcementonly)", run: roomsMode},
+	"users list":      {usage: "users list [-room ROOM_ID]", run: usersList},
+	"users role":      {usage: "users role -room ROOM_ID USERNAME (owner|moderator|member)", run: usersRole},
+	"users mute":      {usage: "users mute -room ROOM_ID USERNAME", run: usersMute},
+	"users unmute":    {usage: "users unmute -room ROOM_ID USERNAME", run: usersUnmute},
+	"users kick":      {usage: "users kick [-reason REASON] (-id PARTICIPANT_ID | -username USERNAME)", run: usersKick},
+	"history purge":   {usage: "history purge (-room ROOM_ID | -author USERNAME)", run: historyPurge},
+	"history export":  {usage: "history export [-since TIME] [-until TIME] [-format jsonl|markdown|html] [-out FILE] ROOM_ID", run: historyExport},
+	"announce":        {usage: "announce [-room ROOM_ID] MESSAGE...", run: announce},
+	"stats":           {usage: "stats", run: stats},
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(); err != nil {
+		if errors.Is(err, errUsage) {
+			usage()
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "chatctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("invalid output format %s", *output)
+	}
+
+	cmd, args, ok := lookupCommand(flag.Args())
+	if !ok {
+		return errUsage
+	}
+
+	c, err := client.NewAdminClient(*adminAddr, *token)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.Shutdown(); err != nil {
+			fmt.Fprintf(os.Stderr, "chatctl: failed to close the connection: %v\n", err)
+		}
+	}()
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), *timeout)
+	defer cancelFunc()
+	resp, err := cmd.run(ctx, c, args)
+	if err != nil || resp == nil {
+		return err
+	}
+
+	if *output == "json" {
+		return printJSON(os.Stdout, resp)
+	}
+
+	return printTable(os.Stdout, resp)
+}
+
+func lookupCommand(args []string) (command, []string, bool) {
+	for n := 2; n > 0; n-- {
+		if len(args) < n {
+			continue
+		}
+		if cmd, ok := commands[strings.Join(args[:n], " ")]; ok {
+			return cmd, args[n:], true
+		}
+	}
+
+	return command{}, nil, false
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: chatctl [flags] COMMAND\n\nCommands:\n")
+	usages := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		usages = append(usages, cmd.usage)
+	}
+	sort.Strings(usages)
+	for _, u := range usages {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\n", u)
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupCommand(t *testing.T) {
+	testsTable := []struct {
+		Args     []string
+		Want     string
+		WantArgs []string
+		WantOK   bool
+	}{
+		{Args: []string{"rooms", "list"}, Want: "rooms list", WantOK: true},
+		{Args: []string{"rooms", "close", "1"}, Want: "rooms close ROOM_ID", WantArgs: []string{"1"}, WantOK: true},
+		{Args: []string{"announce", "hello", "all"}, Want: "announce [-room ROOM_ID] MESSAGE...", WantArgs: []string{"hello", "all"}, WantOK: true},
+		{Args: []string{"stats"}, Want: "stats", WantOK: true},
+		{Args: []string{"rooms"}},
+		{Args: []string{"users", "ban", "alice"}},
+		{},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(strings.Join(tt.Args, " "), func(t *testing.T) {
+			cmd, args, ok := lookupCommand(tt.Args)
+			if ok != tt.WantOK {
+				t.Fatalf("got ok %t, want %t", ok, tt.WantOK)
+			}
+			if cmd.usage != tt.Want || strings.Join(args, " ") != strings.Join(tt.WantArgs, " ") {
+				t.Errorf("got %q with arguments %q, want %q with %q", cmd.usage, args, tt.Want, tt.WantArgs)
+			}
+		})
+	}
+}
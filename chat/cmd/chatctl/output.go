@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func printJSON(w io.Writer, msg proto.Message) error {
+	b, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+
+	return err
+}
+
+func printTable(w io.Writer, msg proto.Message) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	switch resp := msg.(type) {
+	case *pb.ListRoomsResponse:
+		printRooms(tw, resp.Rooms...)
+	case *pb.CreateRoomResponse:
+		printRooms(tw, resp.Room)
+	case *pb.SetRoomModeResponse:
+		printRooms(tw, resp.Room)
+	case *pb.UpdateRoomMetadataResponse:
+		printRooms(tw, resp.Room)
+	case *pb.SetRetentionResponse:
+		printRooms(tw, resp.Room)
+	case *pb.PurgeHistoryResponse:
+		fmt.Fprintf(tw, "Purged %d message(s)\n", resp.Purged)
+	case *pb.ListArchivedRoomsResponse:
+		fmt.Fprintln(tw, "ID\tNAME\tCREATOR\tCREATED AT\tARCHIVED AT\tTOPIC")
+		for _, r := range resp.Rooms {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Id, r.Name, r.Creator, r.CreatedAt.AsTime().Format(time.RFC3339), r.ArchivedAt.AsTime().Format(time.RFC3339), r.Topic)
+		}
+	case *pb.CloseRoomResponse:
+		fmt.Fprintln(tw, "Room closed")
+	case *pb.SetRoleResponse:
+		fmt.Fprintln(tw, "Role granted")
+	case *pb.SetMutedResponse:
+		fmt.Fprintln(tw, "Mute updated")
+	case *pb.ListParticipantsResponse:
+		printParticipants(tw, resp.Participants...)
+	case *pb.DisconnectParticipantResponse:
+		printParticipants(tw, resp.Disconnected...)
+	case *pb.AnnounceResponse:
+		fmt.Fprintf(tw, "Announced to %d room(s)\n", resp.Rooms)
+	case *pb.GetStatsResponse:
+		startedAt := resp.StartedAt.AsTime()
+		fmt.Fprintf(tw, "STARTED AT\t%s\n", startedAt.Format(time.RFC3339))
+		fmt.Fprintf(tw, "UPTIME\t%s\n", time.Since(startedAt).Round(time.Second))
+		fmt.Fprintf(tw, "ROOMS\t%d\n", resp.Rooms)
+		fmt.Fprintf(tw, "PARTICIPANTS\t%d\n", resp.Participants)
+		fmt.Fprintf(tw, "WATCHERS\t%d\n", resp.Watchers)
+		fmt.Fprintf(tw, "MESSAGES\t%d\n", resp.Messages)
+	default:
+		return fmt.Errorf("no table output for %T", msg)
+	}
+
+	return tw.Flush()
+}
+
+func printRooms(w io.Writer, rooms ...*pb.RoomInfo) {
+	fmt.Fprintln(w, "ID\tNAME\tVISIBILITY\tLIFECYCLE\tPARTICIPANTS\tWAITING\tWATCHERS\tMODE\tTOPIC")
+	for _, r := range rooms {
+		mode := r.Mode.String()
+		if r.Mode == pb.RoomMode_Slow {
+			mode += fmt.Sprintf(" (%s)", r.SlowModeInterval.AsDuration())
+		}
+		participants := fmt.Sprint(r.Participants)
+		if r.Capacity > 0 {
+			participants += fmt.Sprintf("/%d", r.Capacity)
+		}
+		lifecycle := r.Lifecycle.String()
+		if r.Lifecycle == pb.RoomLifecycle_Ephemeral {
+			lifecycle += fmt.Sprintf(" (%s)", r.EmptyTtl.AsDuration())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n", r.Id, r.Name, r.Visibility, lifecycle, participants, r.Waiting, r.Watchers, mode, r.Topic)
+	}
+}
+
+func printParticipants(w io.Writer, participants ...*pb.ParticipantInfo) {
+	fmt.Fprintln(w, "ID\tUSERNAME\tROOM ID")
+	for _, p := range participants {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Id, p.Username, p.RoomId)
+	}
+}
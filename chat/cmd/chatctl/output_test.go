@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestPrintTable(t *testing.T) {
+	testsTable := []struct {
+		Name    string
+		Msg     proto.Message
+		Want    string
+		WantErr bool
+	}{
+		{
+			Name: "rooms",
+			Msg: &pb.ListRoomsResponse{Rooms: []*pb.RoomInfo{
+				{
+					Id:           "1",
+					Name:         "default",
+					Participants: 2,
+					Topic:        "Welcome",
+				},
+				{
+					Id:               "2",
+					Name:             "news",
+					Visibility:       pb.RoomVisibility_Private,
+					Lifecycle:        pb.RoomLifecycle_Ephemeral,
+					EmptyTtl:         durationpb.New(time.Hour),
+					Participants:     3,
+					Capacity:         10,
+					Waiting:          1,
+					Mode:             pb.RoomMode_Slow,
+					SlowModeInterval: durationpb.New(30 * time.Second),
+				},
+			}},
+			Want: "ID  NAME     VISIBILITY  LIFECYCLE           PARTICIPANTS  WAITING  WATCHERS  MODE        TOPIC\n" +
+				"1   default  Public      Persistent          2             0        0         Normal      Welcome\n" +
+				"2   news     Private     Ephemeral (1h0m0s)  3/10          1        0         Slow (30s)  \n",
+		},
+		{
+			Name: "participants",
+			Msg: &pb.DisconnectParticipantResponse{Disconnected: []*pb.ParticipantInfo{
+				{Id: "p1", Username: "alice", RoomId: "1"},
+			}},
+			Want: "ID  USERNAME  ROOM ID\n" +
+				"p1  alice     1\n",
+		},
+		{
+			Name: "announce",
+			Msg:  &pb.AnnounceResponse{Rooms: 3},
+			Want: "Announced to 3 room(s)\n",
+		},
+		{
+			Name: "close",
+			Msg:  &pb.CloseRoomResponse{},
+			Want: "Room closed\n",
+		},
+		{
+			Name:    "no table",
+			Msg:     &pb.ListRoomsRequest{},
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := printTable(&b, tt.Msg)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			if got := b.String(); got != tt.Want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := printJSON(&b, &pb.AnnounceResponse{}); err != nil {
+		t.Fatalf("printJSON failed: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("got invalid JSON %q: %v", b.String(), err)
+	}
+	// The unpopulated fields are printed too.
+	if rooms, ok := got["rooms"]; !ok || rooms != 0.0 {
+		t.Errorf("got %v, want rooms 0", got)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/savo92/playground-go-grpc/chat/client"
 )
@@ -29,6 +31,8 @@ var (
 	debug       = flag.Bool("debug", defaultDebug, fmt.Sprintf("Enable debug logs. Default: %t", defaultDebug))
 	logDst      = flag.String("log-dst", defaultLogDst, fmt.Sprintf("The destination of logs. Default: %s.", defaultLogDst))
 	logFilename = flag.String("logfile-path", defaultLogFilename, fmt.Sprintf("When log-dst is file, allows to specify a custom name for the logfile. Default: %s", defaultLogFilename))
+	token       = flag.String("token", os.Getenv("CHAT_TOKEN"), "The token authenticating the user, sent as a bearer token. Default: $CHAT_TOKEN")
+	tlsCA       = flag.String("tls-ca", "", "The CA certificate file verifying the server, connecting over TLS. Default: plaintext")
 )
 
 // var (
@@ -54,7 +58,18 @@ func run() error {
 		return err
 	}
 
-	c, err := client.NewClient(*serverAddr)
+	var opts []grpc.DialOption
+	if *tlsCA != "" {
+		creds, err := credentials.NewClientTLSFromFile(*tlsCA, "")
+		if err != nil {
+			return fmt.Errorf("loading the CA certificate: %w", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	}
+	if *token != "" {
+		opts = append(opts, client.WithToken(*token))
+	}
+	c, err := client.NewClient(*serverAddr, opts...)
 	if err != nil {
 		return err
 	}
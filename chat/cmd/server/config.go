@@ -0,0 +1,550 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/server"
+)
+
+// envPrefix prefixes the environment variables overriding the config: the
+// key of a setting is CHAT_<SECTION>_<KEY>, for example
+// CHAT_LIMITS_ROOM_RATE.
+const envPrefix = "CHAT"
+
+// config is the configuration of the server. It is read from the YAML config
+// file, then overridden by the environment and by the command line flags.
+type config struct {
+	Listen     listenConfig     `yaml:"listen"`
+	TLS        tlsConfig        `yaml:"tls"`
+	Auth       authConfig       `yaml:"auth"`
+	Limits     limitsConfig     `yaml:"limits"`
+	Lifecycle  lifecycleConfig  `yaml:"lifecycle"`
+	Retention  retentionConfig  `yaml:"retention"`
+	Mailbox    mailboxConfig    `yaml:"mailbox"`
+	Backplane  backplaneConfig  `yaml:"backplane"`
+	Cluster    clusterConfig    `yaml:"cluster"`
+	Federation federationConfig `yaml:"federation"`
+	Rooms      []roomConfig     `yaml:"rooms"`
+	// DefaultRoom is the room joined by the new participants, declared in
+	// Rooms or not.
+	DefaultRoom string `yaml:"default_room"`
+	// BannedUsers are the usernames banned from the server.
+	BannedUsers []string  `yaml:"banned_users"`
+	Log         logConfig `yaml:"log"`
+}
+
+type listenConfig struct {
+	Address   string `yaml:"address"`
+	Port      int    `yaml:"port"`
+	AdminPort int    `yaml:"admin_port"`
+	HTTPPort  int    `yaml:"http_port"`
+	// HTTPOrigins are the origins allowed to call the HTTP server, *
+	// allowing all.
+	HTTPOrigins []string `yaml:"http_origins"`
+	IRCPort     int      `yaml:"irc_port"`
+}
+
+type tlsConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+type authConfig struct {
+	AdminToken string `yaml:"admin_token"`
+	// Users maps the usernames to their token. When set, the other usernames
+	// are rejected.
+	Users map[string]string `yaml:"users"`
+}
+
+type limitsConfig struct {
+	ParticipantRate      float64       `yaml:"participant_rate"`
+	ParticipantBurst     int           `yaml:"participant_burst"`
+	ParticipantByteRate  float64       `yaml:"participant_byte_rate"`
+	ParticipantByteBurst int           `yaml:"participant_byte_burst"`
+	RoomRate             float64       `yaml:"room_rate"`
+	RoomBurst            int           `yaml:"room_burst"`
+	MaxMessageBytes      int           `yaml:"max_message_bytes"`
+	MuteAfter            int           `yaml:"mute_after"`
+	MuteWindow           time.Duration `yaml:"mute_window"`
+	MuteFor              time.Duration `yaml:"mute_for"`
+}
+
+type lifecycleConfig struct {
+	EmptyRoomTTL      time.Duration `yaml:"empty_room_ttl"`
+	ArchiveAfter      time.Duration `yaml:"archive_after"`
+	RoomSweepInterval time.Duration `yaml:"room_sweep_interval"`
+	CompactInterval   time.Duration `yaml:"compact_interval"`
+}
+
+type retentionConfig struct {
+	MaxAge      time.Duration `yaml:"max_age"`
+	MaxMessages int           `yaml:"max_messages"`
+	MaxBytes    int           `yaml:"max_bytes"`
+}
+
+type mailboxConfig struct {
+	Retention time.Duration `yaml:"retention"`
+	Size      int           `yaml:"size"`
+}
+
+type backplaneConfig struct {
+	Redis string `yaml:"redis"`
+}
+
+type clusterConfig struct {
+	Node string `yaml:"node"`
+	Addr string `yaml:"addr"`
+	// Peers are the nodes of the cluster, as id=addr.
+	Peers []string `yaml:"peers"`
+	// Secret authenticates the nodes of the cluster to each other.
+	Secret string `yaml:"secret"`
+}
+
+type federationConfig struct {
+	// Name identifies the server to its peers, and enables the federation.
+	Name string `yaml:"name"`
+	// Addr is the address of the federation service of the server.
+	Addr  string                 `yaml:"addr"`
+	Peers []federationPeerConfig `yaml:"peers"`
+}
+
+type federationPeerConfig struct {
+	Name   string `yaml:"name"`
+	Addr   string `yaml:"addr"`
+	Secret string `yaml:"secret"`
+	// Rooms maps the names of the local rooms to the names of the rooms of
+	// the peer they are bridged to.
+	Rooms map[string]string `yaml:"rooms"`
+}
+
+type roomConfig struct {
+	Name             string            `yaml:"name"`
+	Topic            string            `yaml:"topic"`
+	Description      string            `yaml:"description"`
+	Labels           map[string]string `yaml:"labels"`
+	Mode             string            `yaml:"mode"`
+	SlowModeInterval time.Duration     `yaml:"slow_mode_interval"`
+	Visibility       string            `yaml:"visibility"`
+	Capacity         int               `yaml:"capacity"`
+	// Queue makes the participants joining the full room wait for a seat.
+	Queue bool `yaml:"queue"`
+	// Persistent, true by default, exempts the room from the lifecycle
+	// policy, otherwise the room is closed once empty for the empty room TTL.
+	Persistent *bool `yaml:"persistent"`
+}
+
+type logConfig struct {
+	Level string `yaml:"level"`
+	// Format is text or json.
+	Format string `yaml:"format"`
+}
+
+func defaultConfig() config {
+	limits := server.DefaultLimits()
+	lifecycle := server.DefaultLifecycle()
+	mailbox := server.DefaultMailbox()
+
+	return config{
+		Listen: listenConfig{
+			Address:   "localhost",
+			Port:      8081,
+			AdminPort: defaultAdminPort,
+		},
+		Auth: authConfig{
+			AdminToken: os.Getenv("CHAT_ADMIN_TOKEN"),
+		},
+		Limits: limitsConfig{
+			ParticipantRate:      limits.ParticipantMessages,
+			ParticipantBurst:     limits.ParticipantMessagesBurst,
+			ParticipantByteRate:  limits.ParticipantBytes,
+			ParticipantByteBurst: limits.ParticipantBytesBurst,
+			RoomRate:             limits.RoomMessages,
+			RoomBurst:            limits.RoomMessagesBurst,
+			MaxMessageBytes:      limits.MaxMessageBytes,
+			MuteAfter:            limits.MuteAfter,
+			MuteWindow:           limits.MuteWindow,
+			MuteFor:              limits.MuteFor,
+		},
+		Lifecycle: lifecycleConfig{
+			EmptyRoomTTL:      lifecycle.EmptyTTL,
+			ArchiveAfter:      lifecycle.ArchiveAfter,
+			RoomSweepInterval: lifecycle.SweepInterval,
+			CompactInterval:   lifecycle.CompactInterval,
+		},
+		Retention: retentionConfig{
+			MaxAge:      lifecycle.Retention.MaxAge,
+			MaxMessages: lifecycle.Retention.MaxMessages,
+			MaxBytes:    lifecycle.Retention.MaxBytes,
+		},
+		Mailbox: mailboxConfig{
+			Retention: mailbox.Retention,
+			Size:      mailbox.MaxItems,
+		},
+		DefaultRoom: "default",
+		Log: logConfig{
+			Level:  log.InfoLevel.String(),
+			Format: "text",
+		},
+	}
+}
+
+// loadConfig reads the config named by the -config flag of args, overridden
+// by the environment and by the other flags of args.
+func loadConfig(args []string) (config, error) {
+	var path string
+	cfg := defaultConfig()
+	if err := newFlagSet(&cfg, &path).Parse(args); err != nil {
+		return cfg, err
+	}
+
+	cfg = defaultConfig()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("reading the config failed: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parsing the config failed: %w", err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem(), envPrefix); err != nil {
+		return cfg, err
+	}
+	if err := newFlagSet(&cfg, &path).Parse(args); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.validate()
+}
+
+// newFlagSet returns the flags overriding cfg, and setting path to the
+// config file.
+func newFlagSet(cfg *config, path *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(path, "config", *path, "A YAML config file, overridden by the CHAT_<SECTION>_<KEY> environment variables and by the flags. Default: none")
+	fs.Var(debugFlag{&cfg.Log.Level}, "debug", "Enable debug logs. Default: false")
+	fs.StringVar(&cfg.Listen.Address, "bind-address", cfg.Listen.Address, "The address for the grpc, HTTP and IRC servers to listen to.")
+	fs.IntVar(&cfg.Listen.Port, "port", cfg.Listen.Port, "A port for the grpc server to listen to.")
+	fs.IntVar(&cfg.Listen.AdminPort, "admin-port", cfg.Listen.AdminPort, fmt.Sprintf("A localhost port for the admin grpc server to listen to, 0 disables it. Default: %d", defaultAdminPort))
+	fs.StringVar(&cfg.DefaultRoom, "default-room", cfg.DefaultRoom, "The room joined by the new participants.")
+	fs.StringVar(&cfg.Auth.AdminToken, "admin-token", cfg.Auth.AdminToken, "A bearer token required by the admin server. Default: $CHAT_ADMIN_TOKEN")
+	fs.StringVar(&cfg.TLS.CertFile, "tls-cert", cfg.TLS.CertFile, "The certificate file of the grpc server, serving TLS with -tls-key. Default: plaintext")
+	fs.StringVar(&cfg.TLS.KeyFile, "tls-key", cfg.TLS.KeyFile, "The private key file of the grpc server.")
+	fs.StringVar(&cfg.Backplane.Redis, "backplane-redis", cfg.Backplane.Redis, "The address of a Redis server sharing the rooms with the other replicas. Default: the rooms are local")
+	fs.StringVar(&cfg.Cluster.Node, "cluster-node", cfg.Cluster.Node, "The id of the node in a cluster sharing the rooms, stable across restarts. Default: no cluster")
+	fs.StringVar(&cfg.Cluster.Addr, "cluster-addr", cfg.Cluster.Addr, "The address of the cluster service of the node, reachable by its peers.")
+	fs.StringVar(&cfg.Cluster.Secret, "cluster-secret", cfg.Cluster.Secret, "The secret shared by the nodes of the cluster. Default: $CHAT_CLUSTER_SECRET")
+	fs.Var(listFlag{&cfg.Cluster.Peers}, "cluster-peers", "The nodes of the cluster, as id=addr separated by commas.")
+	fs.StringVar(&cfg.Federation.Name, "federation-name", cfg.Federation.Name, "The name of the server to its federated peers, declared in the config file. Default: no federation")
+	fs.StringVar(&cfg.Federation.Addr, "federation-addr", cfg.Federation.Addr, "The address of the federation service of the server, reachable by its peers.")
+	fs.IntVar(&cfg.Listen.HTTPPort, "http-port", cfg.Listen.HTTPPort, "A port for the WebSocket, Server-Sent Events and gRPC-Web clients, 0 disables it. Default: 0")
+	fs.Var(listFlag{&cfg.Listen.HTTPOrigins}, "http-origins", "The origins allowed to call the HTTP server, separated by commas, * allowing all. Default: the same origin")
+	fs.IntVar(&cfg.Listen.IRCPort, "irc-port", cfg.Listen.IRCPort, "A port for the IRC clients, 0 disables it. Default: 0")
+	fs.Float64Var(&cfg.Limits.ParticipantRate, "participant-rate", cfg.Limits.ParticipantRate, "The messages per second a participant can write, 0 disables the limit.")
+	fs.IntVar(&cfg.Limits.ParticipantBurst, "participant-burst", cfg.Limits.ParticipantBurst, "The burst of messages a participant can write.")
+	fs.Float64Var(&cfg.Limits.ParticipantByteRate, "participant-byte-rate", cfg.Limits.ParticipantByteRate, "The bytes per second a participant can write, 0 disables the limit.")
+	fs.IntVar(&cfg.Limits.ParticipantByteBurst, "participant-byte-burst", cfg.Limits.ParticipantByteBurst, "The burst of bytes a participant can write.")
+	fs.Float64Var(&cfg.Limits.RoomRate, "room-rate", cfg.Limits.RoomRate, "The messages per second a room forwards, 0 disables the limit.")
+	fs.IntVar(&cfg.Limits.RoomBurst, "room-burst", cfg.Limits.RoomBurst, "The burst of messages a room forwards.")
+	fs.IntVar(&cfg.Limits.MaxMessageBytes, "max-message-bytes", cfg.Limits.MaxMessageBytes, "The maximum size of a message, 0 disables the limit.")
+	fs.DurationVar(&cfg.Lifecycle.EmptyRoomTTL, "empty-room-ttl", cfg.Lifecycle.EmptyRoomTTL, "How long an ephemeral room is kept while empty.")
+	fs.DurationVar(&cfg.Lifecycle.ArchiveAfter, "archive-after", cfg.Lifecycle.ArchiveAfter, "Archive the rooms without participants nor messages for this long, 0 disables the archival.")
+	fs.DurationVar(&cfg.Lifecycle.RoomSweepInterval, "room-sweep-interval", cfg.Lifecycle.RoomSweepInterval, "How often the rooms are checked for expiration and archival, 0 disables the cleanup.")
+	fs.DurationVar(&cfg.Retention.MaxAge, "retention-max-age", cfg.Retention.MaxAge, "The default maximum age of the messages kept, 0 disables the limit.")
+	fs.IntVar(&cfg.Retention.MaxMessages, "retention-max-messages", cfg.Retention.MaxMessages, "The default maximum number of messages kept per room, 0 disables the limit.")
+	fs.IntVar(&cfg.Retention.MaxBytes, "retention-max-bytes", cfg.Retention.MaxBytes, "The default maximum bytes of messages kept per room, 0 disables the limit.")
+	fs.DurationVar(&cfg.Lifecycle.CompactInterval, "compact-interval", cfg.Lifecycle.CompactInterval, "How often the retention of the history is enforced, 0 disables the compaction.")
+	fs.DurationVar(&cfg.Mailbox.Retention, "mailbox-retention", cfg.Mailbox.Retention, "How long the direct messages and mentions are kept for the offline users, 0 keeps them until delivered.")
+	fs.IntVar(&cfg.Mailbox.Size, "mailbox-size", cfg.Mailbox.Size, "The direct messages and mentions kept for each offline user, 0 disables the cap.")
+
+	return fs
+}
+
+// debugFlag sets the log level to debug, or back to info when false.
+type debugFlag struct {
+	level *string
+}
+
+func (f debugFlag) IsBoolFlag() bool {
+	return true
+}
+
+func (f debugFlag) String() string {
+	return strconv.FormatBool(f.level != nil && *f.level == log.DebugLevel.String())
+}
+
+func (f debugFlag) Set(s string) error {
+	debug, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	switch {
+	case debug:
+		*f.level = log.DebugLevel.String()
+	case *f.level == log.DebugLevel.String():
+		*f.level = log.InfoLevel.String()
+	}
+
+	return nil
+}
+
+// listFlag sets a list separated by commas.
+type listFlag struct {
+	items *[]string
+}
+
+func (f listFlag) String() string {
+	if f.items == nil {
+		return ""
+	}
+
+	return strings.Join(*f.items, ",")
+}
+
+func (f listFlag) Set(s string) error {
+	*f.items = splitList(s)
+
+	return nil
+}
+
+// applyEnv overrides the settings of v, a struct, with the environment
+// variables named after their yaml keys under prefix. The lists are separated
+// by commas, the maps and the lists of structs are only read from the file.
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		name := prefix + "_" + strings.ToUpper(key)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return errors.New("only set in the config file")
+		}
+		field.Set(reflect.ValueOf(splitList(value)))
+	default:
+		return errors.New("only set in the config file")
+	}
+
+	return nil
+}
+
+func (cfg config) validate() error {
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return errors.New("TLS requires both a certificate and a private key")
+	}
+	if cfg.Cluster.Node != "" && (cfg.Cluster.Addr == "" || cfg.Cluster.Secret == "") {
+		return errors.New("the cluster address and secret are required with a cluster node")
+	}
+	if cfg.Federation.Name != "" && cfg.Federation.Addr == "" {
+		return errors.New("the federation address is required with a federation name")
+	}
+	if err := cfg.limits().Validate(); err != nil {
+		return err
+	}
+	if cfg.DefaultRoom == "" {
+		return errors.New("a default room is required")
+	}
+	if _, err := log.ParseLevel(cfg.Log.Level); err != nil {
+		return err
+	}
+	if cfg.Log.Format != "text" && cfg.Log.Format != "json" {
+		return fmt.Errorf("unknown log format %q, expected text or json", cfg.Log.Format)
+	}
+	_, err := cfg.rooms()
+
+	return err
+}
+
+func (cfg config) limits() server.Limits {
+	return server.Limits{
+		ParticipantMessages:      cfg.Limits.ParticipantRate,
+		ParticipantMessagesBurst: cfg.Limits.ParticipantBurst,
+		ParticipantBytes:         cfg.Limits.ParticipantByteRate,
+		ParticipantBytesBurst:    cfg.Limits.ParticipantByteBurst,
+		RoomMessages:             cfg.Limits.RoomRate,
+		RoomMessagesBurst:        cfg.Limits.RoomBurst,
+		MaxMessageBytes:          cfg.Limits.MaxMessageBytes,
+		MuteAfter:                cfg.Limits.MuteAfter,
+		MuteWindow:               cfg.Limits.MuteWindow,
+		MuteFor:                  cfg.Limits.MuteFor,
+	}
+}
+
+func (cfg config) lifecycle() server.Lifecycle {
+	lifecycle := server.Lifecycle{
+		EmptyTTL:        cfg.Lifecycle.EmptyRoomTTL,
+		ArchiveAfter:    cfg.Lifecycle.ArchiveAfter,
+		SweepInterval:   cfg.Lifecycle.RoomSweepInterval,
+		CompactInterval: cfg.Lifecycle.CompactInterval,
+	}
+	lifecycle.Retention.MaxAge = cfg.Retention.MaxAge
+	lifecycle.Retention.MaxMessages = cfg.Retention.MaxMessages
+	lifecycle.Retention.MaxBytes = cfg.Retention.MaxBytes
+
+	return lifecycle
+}
+
+func (cfg config) mailbox() server.Mailbox {
+	return server.Mailbox{
+		Retention: cfg.Mailbox.Retention,
+		MaxItems:  cfg.Mailbox.Size,
+	}
+}
+
+// rooms returns the rooms declared by cfg, validated.
+func (cfg config) rooms() ([]server.Room, error) {
+	rooms := make([]server.Room, 0, len(cfg.Rooms))
+	for _, r := range cfg.Rooms {
+		mode, err := parseEnum("mode", r.Mode, pb.RoomMode_value)
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", r.Name, err)
+		}
+		visibility, err := parseEnum("visibility", r.Visibility, pb.RoomVisibility_value)
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", r.Name, err)
+		}
+		rooms = append(rooms, server.Room{
+			Name:             r.Name,
+			Topic:            r.Topic,
+			Description:      r.Description,
+			Labels:           r.Labels,
+			Mode:             pb.RoomMode(mode),
+			SlowModeInterval: r.SlowModeInterval,
+			Visibility:       pb.RoomVisibility(visibility),
+			Capacity:         r.Capacity,
+			Queue:            r.Queue,
+			Ephemeral:        r.Persistent != nil && !*r.Persistent,
+		})
+	}
+
+	return rooms, server.ValidateRooms(rooms)
+}
+
+// federation returns the federation of the server, nil without federation.
+func (cfg config) federation() *server.Federation {
+	if cfg.Federation.Name == "" {
+		return nil
+	}
+	f := &server.Federation{
+		Name: cfg.Federation.Name,
+		Addr: cfg.Federation.Addr,
+	}
+	for _, p := range cfg.Federation.Peers {
+		f.Peers = append(f.Peers, server.FederationPeer{
+			Name:   p.Name,
+			Addr:   p.Addr,
+			Secret: p.Secret,
+			Rooms:  p.Rooms,
+		})
+	}
+
+	return f
+}
+
+// parseEnum returns the value of the enum named s, ignoring the case and the
+// separators: read_only stands for ReadOnly. An empty s is the zero value.
+func parseEnum(kind, s string, values map[string]int32) (int32, error) {
+	if s == "" {
+		return 0, nil
+	}
+	normalize := strings.NewReplacer("_", "", "-", "", " ", "")
+	want := strings.ToLower(normalize.Replace(s))
+	for name, value := range values {
+		if strings.ToLower(name) == want {
+			return value, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown %s %q", kind, s)
+}
+
+// tlsConfig returns the TLS config of the grpc server, nil without TLS.
+func (cfg config) tlsConfig() (*tls.Config, error) {
+	if cfg.TLS.CertFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading the TLS certificate failed: %w", err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// configureLog applies the log settings of cfg to the standard logger.
+func configureLog(cfg config) {
+	level, err := log.ParseLevel(cfg.Log.Level)
+	if err == nil {
+		log.SetLevel(level)
+	}
+	if cfg.Log.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{})
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestLoadConfig(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// File, when set, is the content of the config file.
+		File    string
+		Env     map[string]string
+		Args    []string
+		Check   func(t *testing.T, cfg config)
+		WantErr bool
+	}{
+		{
+			Name: "defaults",
+			Check: func(t *testing.T, cfg config) {
+				if cfg.Listen.Port != 8081 || cfg.DefaultRoom != "default" || cfg.Log.Level != log.InfoLevel.String() {
+					t.Errorf("got %+v, want the defaults", cfg)
+				}
+			},
+		},
+		{
+			Name: "file",
+			File: "listen:\n  port: 9000\nlimits:\n  room_rate: 7\n  mute_for: 1m\n",
+			Check: func(t *testing.T, cfg config) {
+				if cfg.Listen.Port != 9000 || cfg.Limits.RoomRate != 7 || cfg.Limits.MuteFor != time.Minute {
+					t.Errorf("got listen %+v and limits %+v, want the file settings", cfg.Listen, cfg.Limits)
+				}
+				if cfg.Limits.RoomBurst != defaultConfig().Limits.RoomBurst {
+					t.Errorf("got room burst %d, want the default", cfg.Limits.RoomBurst)
+				}
+			},
+		},
+		{
+			Name: "environment over file",
+			File: "listen:\n  port: 9000\n",
+			Env:  map[string]string{"CHAT_LISTEN_PORT": "9001", "CHAT_LIMITS_ROOM_RATE": "7.5"},
+			Check: func(t *testing.T, cfg config) {
+				if cfg.Listen.Port != 9001 || cfg.Limits.RoomRate != 7.5 {
+					t.Errorf("got port %d and room rate %g, want the environment", cfg.Listen.Port, cfg.Limits.RoomRate)
+				}
+			},
+		},
+		{
+			Name: "flags over environment",
+			File: "listen:\n  port: 9000\n",
+			Env:  map[string]string{"CHAT_LISTEN_PORT": "9001"},
+			Args: []string{"-port", "9002"},
+			Check: func(t *testing.T, cfg config) {
+				if cfg.Listen.Port != 9002 {
+					t.Errorf("got port %d, want the flag", cfg.Listen.Port)
+				}
+			},
+		},
+		{
+			Name: "debug",
+			Args: []string{"-debug"},
+			Check: func(t *testing.T, cfg config) {
+				if cfg.Log.Level != log.DebugLevel.String() {
+					t.Errorf("got log level %s, want debug", cfg.Log.Level)
+				}
+			},
+		},
+		{
+			Name: "debug disabled",
+			File: "log:\n  level: debug\n",
+			Args: []string{"-debug=false"},
+			Check: func(t *testing.T, cfg config) {
+				if cfg.Log.Level != log.InfoLevel.String() {
+					t.Errorf("got log level %s, want info", cfg.Log.Level)
+				}
+			},
+		},
+		{
+			Name:    "malformed file",
+			File:    "listen: [",
+			WantErr: true,
+		},
+		{
+			Name:    "invalid environment",
+			Env:     map[string]string{"CHAT_LIMITS_ROOM_RATE": "fast"},
+			WantErr: true,
+		},
+		{
+			Name:    "invalid room",
+			File:    "rooms:\n  - name: news\n    mode: loud\n",
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			for k, v := range tt.Env {
+				t.Setenv(k, v)
+			}
+			args := tt.Args
+			if tt.File != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.File), 0o600); err != nil {
+					t.Fatalf("WriteFile failed: %v", err)
+				}
+				args = append([]string{"-config", path}, args...)
+			}
+
+			cfg, err := loadConfig(args)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			if tt.Check != nil {
+				tt.Check(t, cfg)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("CHAT_LISTEN_HTTP_ORIGINS", "https://a.example,,https://b.example")
+	t.Setenv("CHAT_MAILBOX_RETENTION", "36h")
+	t.Setenv("CHAT_CLUSTER_SECRET", "s3cret")
+	cfg := defaultConfig()
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem(), envPrefix); err != nil {
+		t.Fatalf("applyEnv failed: %v", err)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.Listen.HTTPOrigins, want) {
+		t.Errorf("got origins %v, want %v", cfg.Listen.HTTPOrigins, want)
+	}
+	if cfg.Mailbox.Retention != 36*time.Hour || cfg.Cluster.Secret != "s3cret" {
+		t.Errorf("got mailbox retention %s and cluster secret %q", cfg.Mailbox.Retention, cfg.Cluster.Secret)
+	}
+}
+
+func TestSetField(t *testing.T) {
+	var fields struct {
+		Duration time.Duration
+		Int      int
+		Float    float64
+		Bool     bool
+		List     []string
+		Map      map[string]string
+	}
+	v := reflect.ValueOf(&fields).Elem()
+	testsTable := []struct {
+		Name    string
+		Field   string
+		Value   string
+		Want    interface{}
+		WantErr bool
+	}{
+		{
+			Name:  "duration",
+			Field: "Duration",
+			Value: "1m30s",
+			Want:  90 * time.Second,
+		},
+		{
+			Name:  "int",
+			Field: "Int",
+			Value: "42",
+			Want:  42,
+		},
+		{
+			Name:  "float",
+			Field: "Float",
+			Value: "0.5",
+			Want:  0.5,
+		},
+		{
+			Name:  "bool",
+			Field: "Bool",
+			Value: "true",
+			Want:  true,
+		},
+		{
+			Name:  "list",
+			Field: "List",
+			Value: "a,b",
+			Want:  []string{"a", "b"},
+		},
+		{
+			Name:    "invalid duration",
+			Field:   "Duration",
+			Value:   "90",
+			WantErr: true,
+		},
+		{
+			Name:    "invalid int",
+			Field:   "Int",
+			Value:   "many",
+			WantErr: true,
+		},
+		{
+			Name:    "map",
+			Field:   "Map",
+			Value:   "a=b",
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			field := v.FieldByName(tt.Field)
+			err := setField(field, tt.Value)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			if err == nil && !reflect.DeepEqual(field.Interface(), tt.Want) {
+				t.Errorf("got %v, want %v", field.Interface(), tt.Want)
+			}
+		})
+	}
+}
+
+func TestParseEnum(t *testing.T) {
+	testsTable := []struct {
+		Value   string
+		Want    pb.RoomMode
+		WantErr bool
+	}{
+		{Value: "", Want: pb.RoomMode_Normal},
+		{Value: "ReadOnly", Want: pb.RoomMode_ReadOnly},
+		{Value: "read_only", Want: pb.RoomMode_ReadOnly},
+		{Value: "announcement-only", Want: pb.RoomMode_AnnouncementOnly},
+		{Value: "loud", WantErr: true},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Value, func(t *testing.T) {
+			got, err := parseEnum("mode", tt.Value, pb.RoomMode_value)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			if pb.RoomMode(got) != tt.Want {
+				t.Errorf("got %s, want %s", pb.RoomMode(got), tt.Want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testsTable := []struct {
+		Name    string
+		Change  func(cfg *config)
+		WantErr bool
+	}{
+		{
+			Name:   "default",
+			Change: func(cfg *config) {},
+		},
+		{
+			Name: "certificate without key",
+			Change: func(cfg *config) {
+				cfg.TLS.CertFile = "cert.pem"
+			},
+			WantErr: true,
+		},
+		{
+			Name: "cluster node without secret",
+			Change: func(cfg *config) {
+				cfg.Cluster.Node = "a"
+				cfg.Cluster.Addr = "localhost:9000"
+			},
+			WantErr: true,
+		},
+		{
+			Name: "federation without address",
+			Change: func(cfg *config) {
+				cfg.Federation.Name = "a"
+			},
+			WantErr: true,
+		},
+		{
+			Name: "byte burst lower than a message",
+			Change: func(cfg *config) {
+				cfg.Limits.ParticipantByteBurst = cfg.Limits.MaxMessageBytes - 1
+			},
+			WantErr: true,
+		},
+		{
+			Name: "no default room",
+			Change: func(cfg *config) {
+				cfg.DefaultRoom = ""
+			},
+			WantErr: true,
+		},
+		{
+			Name: "unknown log level",
+			Change: func(cfg *config) {
+				cfg.Log.Level = "loud"
+			},
+			WantErr: true,
+		},
+		{
+			Name: "unknown log format",
+			Change: func(cfg *config) {
+				cfg.Log.Format = "xml"
+			},
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.Change(&cfg)
+			if err := cfg.validate(); (err != nil) != tt.WantErr {
+				t.Errorf("got error %v, want error %t", err, tt.WantErr)
+			}
+		})
+	}
+}
+
+func TestRooms(t *testing.T) {
+	persistent := false
+	testsTable := []struct {
+		Name          string
+		Room          roomConfig
+		WantEphemeral bool
+		WantErr       bool
+	}{
+		{
+			Name: "persistent by default",
+			Room: roomConfig{Name: "news", Mode: "read_only", Visibility: "private"},
+		},
+		{
+			Name:          "not persistent",
+			Room:          roomConfig{Name: "news", Persistent: &persistent},
+			WantEphemeral: true,
+		},
+		{
+			Name:    "slow mode without interval",
+			Room:    roomConfig{Name: "news", Mode: "slow"},
+			WantErr: true,
+		},
+		{
+			Name:    "unknown visibility",
+			Room:    roomConfig{Name: "news", Visibility: "hidden"},
+			WantErr: true,
+		},
+		{
+			Name:    "no name",
+			Room:    roomConfig{Topic: "News"},
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Rooms = []roomConfig{tt.Room}
+			rooms, err := cfg.rooms()
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := rooms[0].Ephemeral; got != tt.WantEphemeral {
+				t.Errorf("got ephemeral %t, want %t", got, tt.WantEphemeral)
+			}
+		})
+	}
+}
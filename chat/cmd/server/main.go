@@ -2,25 +2,22 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	"github.com/savo92/playground-go-grpc/chat/cluster"
 	"github.com/savo92/playground-go-grpc/chat/server"
 )
 
-const (
-	defaultDebug = false
-)
-
-var (
-	port  = flag.Int("port", 8081, "A port for the grpc server to listen to.")
-	debug = flag.Bool("debug", defaultDebug, fmt.Sprintf("Enable debug logs. Default: %t", defaultDebug))
-)
+const defaultAdminPort = 8082
 
 func main() {
 	if err := run(); err != nil {
@@ -29,15 +26,82 @@ func main() {
 }
 
 func run() error {
-	flag.Parse()
-	if *debug {
-		log.SetLevel(log.DebugLevel)
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		return err
 	}
+	configureLog(cfg)
 
-	s, err := server.NewServer(*port)
+	rooms, err := cfg.rooms()
 	if err != nil {
 		return err
 	}
+	opts := []server.Option{
+		server.WithBindAddress(cfg.Listen.Address),
+		server.WithDefaultRoom(cfg.DefaultRoom),
+		server.WithLimits(cfg.limits()),
+		server.WithLifecycle(cfg.lifecycle()),
+		server.WithMailbox(cfg.mailbox()),
+		server.WithRooms(rooms...),
+		server.WithBannedUsers(cfg.BannedUsers...),
+	}
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, server.WithTLS(tlsConfig))
+	}
+	if len(cfg.Auth.Users) > 0 {
+		opts = append(opts, server.WithAuthenticator(server.NewTokenAuthenticator(cfg.Auth.Users)))
+	}
+	if cfg.Backplane.Redis != "" {
+		bp, err := backplane.NewRedis(cfg.Backplane.Redis)
+		if err != nil {
+			return err
+		}
+		defer bp.Close()
+		log.Infof("Sharing the rooms on %s", cfg.Backplane.Redis)
+		opts = append(opts, server.WithBackplane(bp))
+	}
+	if cfg.Cluster.Node != "" {
+		members, err := parsePeers(cfg.Cluster.Peers)
+		if err != nil {
+			return err
+		}
+		log.Infof("Joining the cluster as %s on %s", cfg.Cluster.Node, cfg.Cluster.Addr)
+		opts = append(opts, server.WithCluster(cluster.Member{ID: cfg.Cluster.Node, Addr: cfg.Cluster.Addr}, members, cfg.Cluster.Secret))
+	}
+	if f := cfg.federation(); f != nil {
+		log.Infof("Federating as %s on %s with %d peers", f.Name, f.Addr, len(f.Peers))
+		opts = append(opts, server.WithFederation(*f))
+	}
+	s, err := server.NewServer(cfg.Listen.Port, opts...)
+	if err != nil {
+		return err
+	}
+	var a *server.AdminServer
+	if cfg.Listen.AdminPort != 0 {
+		a, err = server.NewAdminServer(cfg.Listen.AdminPort, cfg.Auth.AdminToken, s)
+		if err != nil {
+			return err
+		}
+	}
+	var h *server.HTTPServer
+	if cfg.Listen.HTTPPort != 0 {
+		h, err = server.NewHTTPServer(cfg.Listen.HTTPPort, s, cfg.Listen.HTTPOrigins...)
+		if err != nil {
+			return err
+		}
+	}
+	var i *server.IRCServer
+	if cfg.Listen.IRCPort != 0 {
+		i, err = server.NewIRCServer(cfg.Listen.IRCPort, s)
+		if err != nil {
+			return err
+		}
+	}
+	go reloadOnSighup(s)
 	go func() {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt)
@@ -46,12 +110,99 @@ func run() error {
 		log.Info("SIGINT received, shutting down")
 		ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancelFunc()
+		if a != nil {
+			if err := a.Shutdown(ctx); err != nil {
+				log.Panic("Unable to shutdown the admin server")
+			}
+		}
+		if h != nil {
+			if err := h.Shutdown(ctx); err != nil {
+				log.Panic("Unable to shutdown the HTTP server")
+			}
+		}
+		if i != nil {
+			if err := i.Shutdown(ctx); err != nil {
+				log.Panic("Unable to shutdown the IRC server")
+			}
+		}
 		if err := s.Shutdown(ctx); err != nil {
 			log.Panic("Unable to shutdown the server")
 		}
 	}()
 
-	log.Infof("Starting server on port %d", *port)
+	g := new(errgroup.Group)
+	if a != nil {
+		log.Infof("Starting admin server on port %d", cfg.Listen.AdminPort)
+		g.Go(a.Serve)
+	}
+	if h != nil {
+		log.Infof("Starting HTTP server on %s:%d", cfg.Listen.Address, cfg.Listen.HTTPPort)
+		g.Go(h.Serve)
+	}
+	if i != nil {
+		log.Infof("Starting IRC server on %s:%d", cfg.Listen.Address, cfg.Listen.IRCPort)
+		g.Go(i.Serve)
+	}
+	log.Infof("Starting server on %s:%d", cfg.Listen.Address, cfg.Listen.Port)
+	g.Go(s.Serve)
+
+	return g.Wait()
+}
+
+// reloadOnSighup reloads the config on SIGHUP, applying the limits, the
+// rooms, the log settings and the banned users to s. The other settings,
+// including the default room, require a restart, as does a maximum message
+// size larger than the gRPC server accepts.
+func reloadOnSighup(s *server.Server) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	for range sighup {
+		log.Info("SIGHUP received, reloading the config")
+		// The whole config is validated before any of it is applied.
+		cfg, err := loadConfig(os.Args[1:])
+		var rooms []server.Room
+		if err == nil {
+			rooms, err = cfg.rooms()
+		}
+		if err != nil {
+			log.Errorf("Reloading the config failed, keeping the current one: %v", err)
+
+			continue
+		}
+		configureLog(cfg)
+		if err := s.SetLimits(cfg.limits()); err != nil {
+			log.Errorf("Reloading the limits failed: %v", err)
+		}
+		s.SetBannedUsers(cfg.BannedUsers)
+		if err := s.SetRooms(rooms); err != nil {
+			log.Errorf("Provisioning the rooms failed: %v", err)
+		}
+		log.Info("Config reloaded, the other settings apply on restart")
+	}
+}
+
+// splitList splits a list separated by commas, dropping the empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// parsePeers parses a list of id=addr.
+func parsePeers(peers []string) ([]cluster.Member, error) {
+	var members []cluster.Member
+	for _, peer := range peers {
+		parts := strings.SplitN(peer, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid cluster peer %q, expected id=addr", peer)
+		}
+		members = append(members, cluster.Member{ID: parts[0], Addr: parts[1]})
+	}
 
-	return s.Serve()
+	return members, nil
 }
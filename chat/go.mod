@@ -5,11 +5,13 @@ go 1.17
 require (
 	github.com/golang/protobuf v1.4.3
 	github.com/google/uuid v1.1.2
+	github.com/gorilla/websocket v1.5.3
 	github.com/looplab/fsm v0.3.0
 	github.com/sirupsen/logrus v1.8.1
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58
 	google.golang.org/grpc v1.43.0
 	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,637 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.2
+// source: pbuf/admin.proto
+
+package pbuf
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ChatAdminClient is the client API for ChatAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ChatAdminClient interface {
+	ListRooms(ctx context.Context, in *ListRoomsRequest, opts ...grpc.CallOption) (*ListRoomsResponse, error)
+	CreateRoom(ctx context.Context, in *CreateRoomRequest, opts ...grpc.CallOption) (*CreateRoomResponse, error)
+	CloseRoom(ctx context.Context, in *CloseRoomRequest, opts ...grpc.CallOption) (*CloseRoomResponse, error)
+	ListArchivedRooms(ctx context.Context, in *ListArchivedRoomsRequest, opts ...grpc.CallOption) (*ListArchivedRoomsResponse, error)
+	SetRoomMode(ctx context.Context, in *SetRoomModeRequest, opts ...grpc.CallOption) (*SetRoomModeResponse, error)
+	UpdateRoomMetadata(ctx context.Context, in *UpdateRoomMetadataRequest, opts ...grpc.CallOption) (*UpdateRoomMetadataResponse, error)
+	SetRetention(ctx context.Context, in *SetRetentionRequest, opts ...grpc.CallOption) (*SetRetentionResponse, error)
+	PurgeHistory(ctx context.Context, in *PurgeHistoryRequest, opts ...grpc.CallOption) (*PurgeHistoryResponse, error)
+	ExportTranscript(ctx context.Context, in *ExportTranscriptRequest, opts ...grpc.CallOption) (ChatAdmin_ExportTranscriptClient, error)
+	SetRole(ctx context.Context, in *SetRoleRequest, opts ...grpc.CallOption) (*SetRoleResponse, error)
+	SetMuted(ctx context.Context, in *SetMutedRequest, opts ...grpc.CallOption) (*SetMutedResponse, error)
+	ListParticipants(ctx context.Context, in *ListParticipantsRequest, opts ...grpc.CallOption) (*ListParticipantsResponse, error)
+	DisconnectParticipant(ctx context.Context, in *DisconnectParticipantRequest, opts ...grpc.CallOption) (*DisconnectParticipantResponse, error)
+	Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+}
+
+type chatAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatAdminClient(cc grpc.ClientConnInterface) ChatAdminClient {
+	return &chatAdminClient{cc}
+}
+
+func (c *chatAdminClient) ListRooms(ctx context.Context, in *ListRoomsRequest, opts ...grpc.CallOption) (*ListRoomsResponse, error) {
+	out := new(ListRoomsResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/ListRooms", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) CreateRoom(ctx context.Context, in *CreateRoomRequest, opts ...grpc.CallOption) (*CreateRoomResponse, error) {
+	out := new(CreateRoomResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/CreateRoom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) CloseRoom(ctx context.Context, in *CloseRoomRequest, opts ...grpc.CallOption) (*CloseRoomResponse, error) {
+	out := new(CloseRoomResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/CloseRoom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) ListArchivedRooms(ctx context.Context, in *ListArchivedRoomsRequest, opts ...grpc.CallOption) (*ListArchivedRoomsResponse, error) {
+	out := new(ListArchivedRoomsResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/ListArchivedRooms", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) SetRoomMode(ctx context.Context, in *SetRoomModeRequest, opts ...grpc.CallOption) (*SetRoomModeResponse, error) {
+	out := new(SetRoomModeResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/SetRoomMode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) UpdateRoomMetadata(ctx context.Context, in *UpdateRoomMetadataRequest, opts ...grpc.CallOption) (*UpdateRoomMetadataResponse, error) {
+	out := new(UpdateRoomMetadataResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/UpdateRoomMetadata", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) SetRetention(ctx context.Context, in *SetRetentionRequest, opts ...grpc.CallOption) (*SetRetentionResponse, error) {
+	out := new(SetRetentionResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/SetRetention", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) PurgeHistory(ctx context.Context, in *PurgeHistoryRequest, opts ...grpc.CallOption) (*PurgeHistoryResponse, error) {
+	out := new(PurgeHistoryResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/PurgeHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) ExportTranscript(ctx context.Context, in *ExportTranscriptRequest, opts ...grpc.CallOption) (ChatAdmin_ExportTranscriptClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChatAdmin_ServiceDesc.Streams[0], "/pbuf.ChatAdmin/ExportTranscript", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatAdminExportTranscriptClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChatAdmin_ExportTranscriptClient interface {
+	Recv() (*ExportTranscriptChunk, error)
+	grpc.ClientStream
+}
+
+type chatAdminExportTranscriptClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatAdminExportTranscriptClient) Recv() (*ExportTranscriptChunk, error) {
+	m := new(ExportTranscriptChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatAdminClient) SetRole(ctx context.Context, in *SetRoleRequest, opts ...grpc.CallOption) (*SetRoleResponse, error) {
+	out := new(SetRoleResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/SetRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) SetMuted(ctx context.Context, in *SetMutedRequest, opts ...grpc.CallOption) (*SetMutedResponse, error) {
+	out := new(SetMutedResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/SetMuted", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) ListParticipants(ctx context.Context, in *ListParticipantsRequest, opts ...grpc.CallOption) (*ListParticipantsResponse, error) {
+	out := new(ListParticipantsResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/ListParticipants", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) DisconnectParticipant(ctx context.Context, in *DisconnectParticipantRequest, opts ...grpc.CallOption) (*DisconnectParticipantResponse, error) {
+	out := new(DisconnectParticipantResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/DisconnectParticipant", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) Announce(ctx context.Context, in *AnnounceRequest, opts ...grpc.CallOption) (*AnnounceResponse, error) {
+	out := new(AnnounceResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/Announce", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatAdminClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatAdmin/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChatAdminServer is the server API for ChatAdmin service.
+// All implementations must embed UnimplementedChatAdminServer
+// for forward compatibility
+type ChatAdminServer interface {
+	ListRooms(context.Context, *ListRoomsRequest) (*ListRoomsResponse, error)
+	CreateRoom(context.Context, *CreateRoomRequest) (*CreateRoomResponse, error)
+	CloseRoom(context.Context, *CloseRoomRequest) (*CloseRoomResponse, error)
+	ListArchivedRooms(context.Context, *ListArchivedRoomsRequest) (*ListArchivedRoomsResponse, error)
+	SetRoomMode(context.Context, *SetRoomModeRequest) (*SetRoomModeResponse, error)
+	UpdateRoomMetadata(context.Context, *UpdateRoomMetadataRequest) (*UpdateRoomMetadataResponse, error)
+	SetRetention(context.Context, *SetRetentionRequest) (*SetRetentionResponse, error)
+	PurgeHistory(context.Context, *PurgeHistoryRequest) (*PurgeHistoryResponse, error)
+	ExportTranscript(*ExportTranscriptRequest, ChatAdmin_ExportTranscriptServer) error
+	SetRole(context.Context, *SetRoleRequest) (*SetRoleResponse, error)
+	SetMuted(context.Context, *SetMutedRequest) (*SetMutedResponse, error)
+	ListParticipants(context.Context, *ListParticipantsRequest) (*ListParticipantsResponse, error)
+	DisconnectParticipant(context.Context, *DisconnectParticipantRequest) (*DisconnectParticipantResponse, error)
+	Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	mustEmbedUnimplementedChatAdminServer()
+}
+
+// UnimplementedChatAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedChatAdminServer struct {
+}
+
+func (UnimplementedChatAdminServer) ListRooms(context.Context, *ListRoomsRequest) (*ListRoomsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRooms not implemented")
+}
+func (UnimplementedChatAdminServer) CreateRoom(context.Context, *CreateRoomRequest) (*CreateRoomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRoom not implemented")
+}
+func (UnimplementedChatAdminServer) CloseRoom(context.Context, *CloseRoomRequest) (*CloseRoomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseRoom not implemented")
+}
+func (UnimplementedChatAdminServer) ListArchivedRooms(context.Context, *ListArchivedRoomsRequest) (*ListArchivedRoomsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListArchivedRooms not implemented")
+}
+func (UnimplementedChatAdminServer) SetRoomMode(context.Context, *SetRoomModeRequest) (*SetRoomModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRoomMode not implemented")
+}
+func (UnimplementedChatAdminServer) UpdateRoomMetadata(context.Context, *UpdateRoomMetadataRequest) (*UpdateRoomMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRoomMetadata not implemented")
+}
+func (UnimplementedChatAdminServer) SetRetention(context.Context, *SetRetentionRequest) (*SetRetentionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRetention not implemented")
+}
+func (UnimplementedChatAdminServer) PurgeHistory(context.Context, *PurgeHistoryRequest) (*PurgeHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeHistory not implemented")
+}
+func (UnimplementedChatAdminServer) ExportTranscript(*ExportTranscriptRequest, ChatAdmin_ExportTranscriptServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportTranscript not implemented")
+}
+func (UnimplementedChatAdminServer) SetRole(context.Context, *SetRoleRequest) (*SetRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRole not implemented")
+}
+func (UnimplementedChatAdminServer) SetMuted(context.Context, *SetMutedRequest) (*SetMutedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMuted not implemented")
+}
+func (UnimplementedChatAdminServer) ListParticipants(context.Context, *ListParticipantsRequest) (*ListParticipantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListParticipants not implemented")
+}
+func (UnimplementedChatAdminServer) DisconnectParticipant(context.Context, *DisconnectParticipantRequest) (*DisconnectParticipantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisconnectParticipant not implemented")
+}
+func (UnimplementedChatAdminServer) Announce(context.Context, *AnnounceRequest) (*AnnounceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Announce not implemented")
+}
+func (UnimplementedChatAdminServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedChatAdminServer) mustEmbedUnimplementedChatAdminServer() {}
+
+// UnsafeChatAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChatAdminServer will
+// result in compilation errors.
+type UnsafeChatAdminServer interface {
+	mustEmbedUnimplementedChatAdminServer()
+}
+
+func RegisterChatAdminServer(s grpc.ServiceRegistrar, srv ChatAdminServer) {
+	s.RegisterService(&ChatAdmin_ServiceDesc, srv)
+}
+
+func _ChatAdmin_ListRooms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRoomsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).ListRooms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/ListRooms",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).ListRooms(ctx, req.(*ListRoomsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_CreateRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRoomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).CreateRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/CreateRoom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).CreateRoom(ctx, req.(*CreateRoomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_CloseRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseRoomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).CloseRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/CloseRoom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).CloseRoom(ctx, req.(*CloseRoomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_ListArchivedRooms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListArchivedRoomsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).ListArchivedRooms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/ListArchivedRooms",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).ListArchivedRooms(ctx, req.(*ListArchivedRoomsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_SetRoomMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRoomModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).SetRoomMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/SetRoomMode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).SetRoomMode(ctx, req.(*SetRoomModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_UpdateRoomMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRoomMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).UpdateRoomMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/UpdateRoomMetadata",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).UpdateRoomMetadata(ctx, req.(*UpdateRoomMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_SetRetention_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRetentionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).SetRetention(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/SetRetention",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).SetRetention(ctx, req.(*SetRetentionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_PurgeHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).PurgeHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/PurgeHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).PurgeHistory(ctx, req.(*PurgeHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_ExportTranscript_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTranscriptRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatAdminServer).ExportTranscript(m, &chatAdminExportTranscriptServer{stream})
+}
+
+type ChatAdmin_ExportTranscriptServer interface {
+	Send(*ExportTranscriptChunk) error
+	grpc.ServerStream
+}
+
+type chatAdminExportTranscriptServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatAdminExportTranscriptServer) Send(m *ExportTranscriptChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ChatAdmin_SetRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).SetRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/SetRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).SetRole(ctx, req.(*SetRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_SetMuted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMutedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).SetMuted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/SetMuted",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).SetMuted(ctx, req.(*SetMutedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_ListParticipants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListParticipantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).ListParticipants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/ListParticipants",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).ListParticipants(ctx, req.(*ListParticipantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_DisconnectParticipant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectParticipantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).DisconnectParticipant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/DisconnectParticipant",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).DisconnectParticipant(ctx, req.(*DisconnectParticipantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_Announce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnnounceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).Announce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/Announce",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).Announce(ctx, req.(*AnnounceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatAdmin_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatAdminServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatAdmin/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatAdminServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChatAdmin_ServiceDesc is the grpc.ServiceDesc for ChatAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChatAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pbuf.ChatAdmin",
+	HandlerType: (*ChatAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRooms",
+			Handler:    _ChatAdmin_ListRooms_Handler,
+		},
+		{
+			MethodName: "CreateRoom",
+			Handler:    _ChatAdmin_CreateRoom_Handler,
+		},
+		{
+			MethodName: "CloseRoom",
+			Handler:    _ChatAdmin_CloseRoom_Handler,
+		},
+		{
+			MethodName: "ListArchivedRooms",
+			Handler:    _ChatAdmin_ListArchivedRooms_Handler,
+		},
+		{
+			MethodName: "SetRoomMode",
+			Handler:    _ChatAdmin_SetRoomMode_Handler,
+		},
+		{
+			MethodName: "UpdateRoomMetadata",
+			Handler:    _ChatAdmin_UpdateRoomMetadata_Handler,
+		},
+		{
+			MethodName: "SetRetention",
+			Handler:    _ChatAdmin_SetRetention_Handler,
+		},
+		{
+			MethodName: "PurgeHistory",
+			Handler:    _ChatAdmin_PurgeHistory_Handler,
+		},
+		{
+			MethodName: "SetRole",
+			Handler:    _ChatAdmin_SetRole_Handler,
+		},
+		{
+			MethodName: "SetMuted",
+			Handler:    _ChatAdmin_SetMuted_Handler,
+		},
+		{
+			MethodName: "ListParticipants",
+			Handler:    _ChatAdmin_ListParticipants_Handler,
+		},
+		{
+			MethodName: "DisconnectParticipant",
+			Handler:    _ChatAdmin_DisconnectParticipant_Handler,
+		},
+		{
+			MethodName: "Announce",
+			Handler:    _ChatAdmin_Announce_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _ChatAdmin_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportTranscript",
+			Handler:       _ChatAdmin_ExportTranscript_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pbuf/admin.proto",
+}
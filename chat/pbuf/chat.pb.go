@@ -10,6 +10,8 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -21,25 +23,278 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Role int32
+
+const (
+	Role_Member Role = 0
+	// Deprecated: muting is not a role, see ClientMute.
+	Role_Muted     Role = 1
+	Role_Moderator Role = 2
+	Role_Owner     Role = 3
+)
+
+// Enum value maps for Role.
+var (
+	Role_name = map[int32]string{
+		0: "Member",
+		1: "Muted",
+		2: "Moderator",
+		3: "Owner",
+	}
+	Role_value = map[string]int32{
+		"Member":    0,
+		"Muted":     1,
+		"Moderator": 2,
+		"Owner":     3,
+	}
+)
+
+func (x Role) Enum() *Role {
+	p := new(Role)
+	*p = x
+	return p
+}
+
+func (x Role) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Role) Descriptor() protoreflect.EnumDescriptor {
+	return file_pbuf_chat_proto_enumTypes[0].Descriptor()
+}
+
+func (Role) Type() protoreflect.EnumType {
+	return &file_pbuf_chat_proto_enumTypes[0]
+}
+
+func (x Role) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Role.Descriptor instead.
+func (Role) EnumDescriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0}
+}
+
+type RoomMode int32
+
+const (
+	// Everybody can write.
+	RoomMode_Normal RoomMode = 0
+	// Members can write one message per slow mode interval.
+	RoomMode_Slow RoomMode = 1
+	// Nobody can write.
+	RoomMode_ReadOnly RoomMode = 2
+	// Only owners and moderators can write.
+	RoomMode_AnnouncementOnly RoomMode = 3
+)
+
+// Enum value maps for RoomMode.
+var (
+	RoomMode_name = map[int32]string{
+		0: "Normal",
+		1: "Slow",
+		2: "ReadOnly",
+		3: "AnnouncementOnly",
+	}
+	RoomMode_value = map[string]int32{
+		"Normal":           0,
+		"Slow":             1,
+		"ReadOnly":         2,
+		"AnnouncementOnly": 3,
+	}
+)
+
+func (x RoomMode) Enum() *RoomMode {
+	p := new(RoomMode)
+	*p = x
+	return p
+}
+
+func (x RoomMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RoomMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_pbuf_chat_proto_enumTypes[1].Descriptor()
+}
+
+func (RoomMode) Type() protoreflect.EnumType {
+	return &file_pbuf_chat_proto_enumTypes[1]
+}
+
+func (x RoomMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RoomMode.Descriptor instead.
+func (RoomMode) EnumDescriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1}
+}
+
+type RoomVisibility int32
+
+const (
+	RoomVisibility_Public RoomVisibility = 0
+	// Private rooms are hidden from the listings of non-members and require an
+	// invite to join.
+	RoomVisibility_Private RoomVisibility = 1
+)
+
+// Enum value maps for RoomVisibility.
+var (
+	RoomVisibility_name = map[int32]string{
+		0: "Public",
+		1: "Private",
+	}
+	RoomVisibility_value = map[string]int32{
+		"Public":  0,
+		"Private": 1,
+	}
+)
+
+func (x RoomVisibility) Enum() *RoomVisibility {
+	p := new(RoomVisibility)
+	*p = x
+	return p
+}
+
+func (x RoomVisibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RoomVisibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_pbuf_chat_proto_enumTypes[2].Descriptor()
+}
+
+func (RoomVisibility) Type() protoreflect.EnumType {
+	return &file_pbuf_chat_proto_enumTypes[2]
+}
+
+func (x RoomVisibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RoomVisibility.Descriptor instead.
+func (RoomVisibility) EnumDescriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{2}
+}
+
+type RoomLifecycle int32
+
+const (
+	// Persistent rooms are kept until closed.
+	RoomLifecycle_Persistent RoomLifecycle = 0
+	// Ephemeral rooms are closed after being empty for a while.
+	RoomLifecycle_Ephemeral RoomLifecycle = 1
+)
+
+// Enum value maps for RoomLifecycle.
+var (
+	RoomLifecycle_name = map[int32]string{
+		0: "Persistent",
+		1: "Ephemeral",
+	}
+	RoomLifecycle_value = map[string]int32{
+		"Persistent": 0,
+		"Ephemeral":  1,
+	}
+)
+
+func (x RoomLifecycle) Enum() *RoomLifecycle {
+	p := new(RoomLifecycle)
+	*p = x
+	return p
+}
+
+func (x RoomLifecycle) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RoomLifecycle) Descriptor() protoreflect.EnumDescriptor {
+	return file_pbuf_chat_proto_enumTypes[3].Descriptor()
+}
+
+func (RoomLifecycle) Type() protoreflect.EnumType {
+	return &file_pbuf_chat_proto_enumTypes[3]
+}
+
+func (x RoomLifecycle) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RoomLifecycle.Descriptor instead.
+func (RoomLifecycle) EnumDescriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{3}
+}
+
 type ClientMessage_ClientCommand int32
 
 const (
-	ClientMessage_Helo         ClientMessage_ClientCommand = 0
-	ClientMessage_Quit         ClientMessage_ClientCommand = 1
-	ClientMessage_WriteMessage ClientMessage_ClientCommand = 2
+	ClientMessage_Helo           ClientMessage_ClientCommand = 0
+	ClientMessage_Quit           ClientMessage_ClientCommand = 1
+	ClientMessage_WriteMessage   ClientMessage_ClientCommand = 2
+	ClientMessage_Kick           ClientMessage_ClientCommand = 3
+	ClientMessage_Ban            ClientMessage_ClientCommand = 4
+	ClientMessage_Mute           ClientMessage_ClientCommand = 5
+	ClientMessage_SetRole        ClientMessage_ClientCommand = 6
+	ClientMessage_SetRoomMode    ClientMessage_ClientCommand = 7
+	ClientMessage_ListRooms      ClientMessage_ClientCommand = 8
+	ClientMessage_JoinRoom       ClientMessage_ClientCommand = 9
+	ClientMessage_CreateInvite   ClientMessage_ClientCommand = 10
+	ClientMessage_GetRoomInfo    ClientMessage_ClientCommand = 11
+	ClientMessage_SetTopic       ClientMessage_ClientCommand = 12
+	ClientMessage_SetDescription ClientMessage_ClientCommand = 13
+	ClientMessage_SetLabel       ClientMessage_ClientCommand = 14
+	ClientMessage_DirectMessage  ClientMessage_ClientCommand = 15
+	ClientMessage_MarkRead       ClientMessage_ClientCommand = 16
+	ClientMessage_Search         ClientMessage_ClientCommand = 17
+	ClientMessage_GetHistory     ClientMessage_ClientCommand = 18
 )
 
 // Enum value maps for ClientMessage_ClientCommand.
 var (
 	ClientMessage_ClientCommand_name = map[int32]string{
-		0: "Helo",
-		1: "Quit",
-		2: "WriteMessage",
+		0:  "Helo",
+		1:  "Quit",
+		2:  "WriteMessage",
+		3:  "Kick",
+		4:  "Ban",
+		5:  "Mute",
+		6:  "SetRole",
+		7:  "SetRoomMode",
+		8:  "ListRooms",
+		9:  "JoinRoom",
+		10: "CreateInvite",
+		11: "GetRoomInfo",
+		12: "SetTopic",
+		13: "SetDescription",
+		14: "SetLabel",
+		15: "DirectMessage",
+		16: "MarkRead",
+		17: "Search",
+		18: "GetHistory",
 	}
 	ClientMessage_ClientCommand_value = map[string]int32{
-		"Helo":         0,
-		"Quit":         1,
-		"WriteMessage": 2,
+		"Helo":           0,
+		"Quit":           1,
+		"WriteMessage":   2,
+		"Kick":           3,
+		"Ban":            4,
+		"Mute":           5,
+		"SetRole":        6,
+		"SetRoomMode":    7,
+		"ListRooms":      8,
+		"JoinRoom":       9,
+		"CreateInvite":   10,
+		"GetRoomInfo":    11,
+		"SetTopic":       12,
+		"SetDescription": 13,
+		"SetLabel":       14,
+		"DirectMessage":  15,
+		"MarkRead":       16,
+		"Search":         17,
+		"GetHistory":     18,
 	}
 )
 
@@ -54,11 +309,11 @@ func (x ClientMessage_ClientCommand) String() string {
 }
 
 func (ClientMessage_ClientCommand) Descriptor() protoreflect.EnumDescriptor {
-	return file_pbuf_chat_proto_enumTypes[0].Descriptor()
+	return file_pbuf_chat_proto_enumTypes[4].Descriptor()
 }
 
 func (ClientMessage_ClientCommand) Type() protoreflect.EnumType {
-	return &file_pbuf_chat_proto_enumTypes[0]
+	return &file_pbuf_chat_proto_enumTypes[4]
 }
 
 func (x ClientMessage_ClientCommand) Number() protoreflect.EnumNumber {
@@ -76,19 +331,58 @@ const (
 	ServerMessage_Shutdown            ServerMessage_ServerCommand = 0
 	ServerMessage_ForwardMessage      ServerMessage_ServerCommand = 1
 	ServerMessage_ConfirmRoomCheckout ServerMessage_ServerCommand = 2
+	ServerMessage_Announcement        ServerMessage_ServerCommand = 3
+	ServerMessage_Error               ServerMessage_ServerCommand = 4
+	ServerMessage_RoomState           ServerMessage_ServerCommand = 5
+	ServerMessage_RoomList            ServerMessage_ServerCommand = 6
+	ServerMessage_InviteCreated       ServerMessage_ServerCommand = 7
+	ServerMessage_QueuePosition       ServerMessage_ServerCommand = 8
+	ServerMessage_RoomDetails         ServerMessage_ServerCommand = 9
+	ServerMessage_TopicChanged        ServerMessage_ServerCommand = 10
+	ServerMessage_DirectMessage       ServerMessage_ServerCommand = 11
+	ServerMessage_Mention             ServerMessage_ServerCommand = 12
+	ServerMessage_ReadReceipt         ServerMessage_ServerCommand = 13
+	ServerMessage_SearchResults       ServerMessage_ServerCommand = 14
+	ServerMessage_History             ServerMessage_ServerCommand = 15
 )
 
 // Enum value maps for ServerMessage_ServerCommand.
 var (
 	ServerMessage_ServerCommand_name = map[int32]string{
-		0: "Shutdown",
-		1: "ForwardMessage",
-		2: "ConfirmRoomCheckout",
+		0:  "Shutdown",
+		1:  "ForwardMessage",
+		2:  "ConfirmRoomCheckout",
+		3:  "Announcement",
+		4:  "Error",
+		5:  "RoomState",
+		6:  "RoomList",
+		7:  "InviteCreated",
+		8:  "QueuePosition",
+		9:  "RoomDetails",
+		10: "TopicChanged",
+		11: "DirectMessage",
+		12: "Mention",
+		13: "ReadReceipt",
+		14: "SearchResults",
+		15: "History",
 	}
 	ServerMessage_ServerCommand_value = map[string]int32{
 		"Shutdown":            0,
 		"ForwardMessage":      1,
 		"ConfirmRoomCheckout": 2,
+		"Announcement":        3,
+		"Error":               4,
+		"RoomState":           5,
+		"RoomList":            6,
+		"InviteCreated":       7,
+		"QueuePosition":       8,
+		"RoomDetails":         9,
+		"TopicChanged":        10,
+		"DirectMessage":       11,
+		"Mention":             12,
+		"ReadReceipt":         13,
+		"SearchResults":       14,
+		"History":             15,
 	}
 )
 
@@ -103,11 +397,11 @@ func (x ServerMessage_ServerCommand) String() string {
 }
 
 func (ServerMessage_ServerCommand) Descriptor() protoreflect.EnumDescriptor {
-	return file_pbuf_chat_proto_enumTypes[1].Descriptor()
+	return file_pbuf_chat_proto_enumTypes[5].Descriptor()
 }
 
 func (ServerMessage_ServerCommand) Type() protoreflect.EnumType {
-	return &file_pbuf_chat_proto_enumTypes[1]
+	return &file_pbuf_chat_proto_enumTypes[5]
 }
 
 func (x ServerMessage_ServerCommand) Number() protoreflect.EnumNumber {
@@ -119,6 +413,67 @@ func (ServerMessage_ServerCommand) EnumDescriptor() ([]byte, []int) {
 	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 0}
 }
 
+type ServerMessage_ServerError_Code int32
+
+const (
+	ServerMessage_ServerError_Internal         ServerMessage_ServerError_Code = 0
+	ServerMessage_ServerError_InvalidArgument  ServerMessage_ServerError_Code = 1
+	ServerMessage_ServerError_NotFound         ServerMessage_ServerError_Code = 2
+	ServerMessage_ServerError_PermissionDenied ServerMessage_ServerError_Code = 3
+	ServerMessage_ServerError_Banned           ServerMessage_ServerError_Code = 4
+	ServerMessage_ServerError_RateLimited      ServerMessage_ServerError_Code = 5
+	ServerMessage_ServerError_RoomFull         ServerMessage_ServerError_Code = 6
+)
+
+// Enum value maps for ServerMessage_ServerError_Code.
+var (
+	ServerMessage_ServerError_Code_name = map[int32]string{
+		0: "Internal",
+		1: "InvalidArgument",
+		2: "NotFound",
+		3: "PermissionDenied",
+		4: "Banned",
+		5: "RateLimited",
+		6: "RoomFull",
+	}
+	ServerMessage_ServerError_Code_value = map[string]int32{
+		"Internal":         0,
+		"InvalidArgument":  1,
+		"NotFound":         2,
+		"PermissionDenied": 3,
+		"Banned":           4,
+		"RateLimited":      5,
+		"RoomFull":         6,
+	}
+)
+
+func (x ServerMessage_ServerError_Code) Enum() *ServerMessage_ServerError_Code {
+	p := new(ServerMessage_ServerError_Code)
+	*p = x
+	return p
+}
+
+func (x ServerMessage_ServerError_Code) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ServerMessage_ServerError_Code) Descriptor() protoreflect.EnumDescriptor {
+	return file_pbuf_chat_proto_enumTypes[6].Descriptor()
+}
+
+func (ServerMessage_ServerError_Code) Type() protoreflect.EnumType {
+	return &file_pbuf_chat_proto_enumTypes[6]
+}
+
+func (x ServerMessage_ServerError_Code) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ServerMessage_ServerError_Code.Descriptor instead.
+func (ServerMessage_ServerError_Code) EnumDescriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 4, 0}
+}
+
 type ClientMessage struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -229,16 +584,21 @@ func (x *ServerMessage) GetCommand() ServerMessage_ServerCommand {
 	return ServerMessage_Shutdown
 }
 
-type ClientMessage_ClientHelo struct {
+// Retention limits the history kept for a room. The zero values disable the
+// corresponding limits.
+type Retention struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Author string `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	MaxAge      *durationpb.Duration `protobuf:"bytes,1,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`
+	MaxMessages uint32               `protobuf:"varint,2,opt,name=max_messages,json=maxMessages,proto3" json:"max_messages,omitempty"`
+	// The total size of the bodies of the messages.
+	MaxBytes uint64 `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
 }
 
-func (x *ClientMessage_ClientHelo) Reset() {
-	*x = ClientMessage_ClientHelo{}
+func (x *Retention) Reset() {
+	*x = Retention{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pbuf_chat_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -246,13 +606,13 @@ func (x *ClientMessage_ClientHelo) Reset() {
 	}
 }
 
-func (x *ClientMessage_ClientHelo) String() string {
+func (x *Retention) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientMessage_ClientHelo) ProtoMessage() {}
+func (*Retention) ProtoMessage() {}
 
-func (x *ClientMessage_ClientHelo) ProtoReflect() protoreflect.Message {
+func (x *Retention) ProtoReflect() protoreflect.Message {
 	mi := &file_pbuf_chat_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -264,26 +624,69 @@ func (x *ClientMessage_ClientHelo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientMessage_ClientHelo.ProtoReflect.Descriptor instead.
-func (*ClientMessage_ClientHelo) Descriptor() ([]byte, []int) {
-	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 0}
+// Deprecated: Use Retention.ProtoReflect.Descriptor instead.
+func (*Retention) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ClientMessage_ClientHelo) GetAuthor() string {
+func (x *Retention) GetMaxAge() *durationpb.Duration {
 	if x != nil {
-		return x.Author
+		return x.MaxAge
 	}
-	return ""
+	return nil
 }
 
-type ClientMessage_ClientQuit struct {
+func (x *Retention) GetMaxMessages() uint32 {
+	if x != nil {
+		return x.MaxMessages
+	}
+	return 0
+}
+
+func (x *Retention) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+type RoomInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Id               string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Participants     uint32               `protobuf:"varint,3,opt,name=participants,proto3" json:"participants,omitempty"`
+	Mode             RoomMode             `protobuf:"varint,4,opt,name=mode,proto3,enum=pbuf.RoomMode" json:"mode,omitempty"`
+	SlowModeInterval *durationpb.Duration `protobuf:"bytes,5,opt,name=slow_mode_interval,json=slowModeInterval,proto3" json:"slow_mode_interval,omitempty"`
+	Visibility       RoomVisibility       `protobuf:"varint,6,opt,name=visibility,proto3,enum=pbuf.RoomVisibility" json:"visibility,omitempty"`
+	// The maximum number of participants, zero means unlimited.
+	Capacity uint32 `protobuf:"varint,7,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Whether the participants joining a full room wait in a queue.
+	QueueWhenFull bool                   `protobuf:"varint,8,opt,name=queue_when_full,json=queueWhenFull,proto3" json:"queue_when_full,omitempty"`
+	Waiting       uint32                 `protobuf:"varint,9,opt,name=waiting,proto3" json:"waiting,omitempty"`
+	Topic         string                 `protobuf:"bytes,10,opt,name=topic,proto3" json:"topic,omitempty"`
+	Description   string                 `protobuf:"bytes,11,opt,name=description,proto3" json:"description,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// The username of the creator, empty for the rooms created by the server.
+	Creator   string            `protobuf:"bytes,13,opt,name=creator,proto3" json:"creator,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,14,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Lifecycle RoomLifecycle     `protobuf:"varint,15,opt,name=lifecycle,proto3,enum=pbuf.RoomLifecycle" json:"lifecycle,omitempty"`
+	// How long an ephemeral room is kept while empty.
+	EmptyTtl *durationpb.Duration `protobuf:"bytes,16,opt,name=empty_ttl,json=emptyTtl,proto3" json:"empty_ttl,omitempty"`
+	// The time of the last message, or the creation time.
+	LastActivityAt *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	// The messages the participant listing the rooms has not read, zero in
+	// the other replies.
+	Unread    uint32     `protobuf:"varint,18,opt,name=unread,proto3" json:"unread,omitempty"`
+	Retention *Retention `protobuf:"bytes,19,opt,name=retention,proto3" json:"retention,omitempty"`
+	// The watchers of the room, who are not participants.
+	Watchers uint32 `protobuf:"varint,20,opt,name=watchers,proto3" json:"watchers,omitempty"`
 }
 
-func (x *ClientMessage_ClientQuit) Reset() {
-	*x = ClientMessage_ClientQuit{}
+func (x *RoomInfo) Reset() {
+	*x = RoomInfo{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pbuf_chat_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -291,13 +694,13 @@ func (x *ClientMessage_ClientQuit) Reset() {
 	}
 }
 
-func (x *ClientMessage_ClientQuit) String() string {
+func (x *RoomInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ClientMessage_ClientQuit) ProtoMessage() {}
+func (*RoomInfo) ProtoMessage() {}
 
-func (x *ClientMessage_ClientQuit) ProtoReflect() protoreflect.Message {
+func (x *RoomInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_pbuf_chat_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -309,107 +712,354 @@ func (x *ClientMessage_ClientQuit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ClientMessage_ClientQuit.ProtoReflect.Descriptor instead.
-func (*ClientMessage_ClientQuit) Descriptor() ([]byte, []int) {
-	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 1}
+// Deprecated: Use RoomInfo.ProtoReflect.Descriptor instead.
+func (*RoomInfo) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{3}
 }
 
-type ClientMessage_ClientWriteMessage struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *RoomInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
 
-	Body string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+func (x *RoomInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (x *ClientMessage_ClientWriteMessage) Reset() {
-	*x = ClientMessage_ClientWriteMessage{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pbuf_chat_proto_msgTypes[4]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *RoomInfo) GetParticipants() uint32 {
+	if x != nil {
+		return x.Participants
 	}
+	return 0
 }
 
-func (x *ClientMessage_ClientWriteMessage) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *RoomInfo) GetMode() RoomMode {
+	if x != nil {
+		return x.Mode
+	}
+	return RoomMode_Normal
 }
 
-func (*ClientMessage_ClientWriteMessage) ProtoMessage() {}
+func (x *RoomInfo) GetSlowModeInterval() *durationpb.Duration {
+	if x != nil {
+		return x.SlowModeInterval
+	}
+	return nil
+}
 
-func (x *ClientMessage_ClientWriteMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_pbuf_chat_proto_msgTypes[4]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *RoomInfo) GetVisibility() RoomVisibility {
+	if x != nil {
+		return x.Visibility
 	}
-	return mi.MessageOf(x)
+	return RoomVisibility_Public
 }
 
-// Deprecated: Use ClientMessage_ClientWriteMessage.ProtoReflect.Descriptor instead.
-func (*ClientMessage_ClientWriteMessage) Descriptor() ([]byte, []int) {
-	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 2}
+func (x *RoomInfo) GetCapacity() uint32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
 }
 
-func (x *ClientMessage_ClientWriteMessage) GetBody() string {
+func (x *RoomInfo) GetQueueWhenFull() bool {
 	if x != nil {
-		return x.Body
+		return x.QueueWhenFull
 	}
-	return ""
+	return false
 }
 
-type ServerMessage_ServerShutdown struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *RoomInfo) GetWaiting() uint32 {
+	if x != nil {
+		return x.Waiting
+	}
+	return 0
 }
 
-func (x *ServerMessage_ServerShutdown) Reset() {
-	*x = ServerMessage_ServerShutdown{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pbuf_chat_proto_msgTypes[5]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *RoomInfo) GetTopic() string {
+	if x != nil {
+		return x.Topic
 	}
+	return ""
 }
 
-func (x *ServerMessage_ServerShutdown) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *RoomInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
 }
 
-func (*ServerMessage_ServerShutdown) ProtoMessage() {}
+func (x *RoomInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
 
-func (x *ServerMessage_ServerShutdown) ProtoReflect() protoreflect.Message {
-	mi := &file_pbuf_chat_proto_msgTypes[5]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *RoomInfo) GetCreator() string {
+	if x != nil {
+		return x.Creator
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use ServerMessage_ServerShutdown.ProtoReflect.Descriptor instead.
-func (*ServerMessage_ServerShutdown) Descriptor() ([]byte, []int) {
-	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 0}
+func (x *RoomInfo) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
 }
 
-type ServerMessage_ServerForwardMessage struct {
-	state         protoimpl.MessageState
+func (x *RoomInfo) GetLifecycle() RoomLifecycle {
+	if x != nil {
+		return x.Lifecycle
+	}
+	return RoomLifecycle_Persistent
+}
+
+func (x *RoomInfo) GetEmptyTtl() *durationpb.Duration {
+	if x != nil {
+		return x.EmptyTtl
+	}
+	return nil
+}
+
+func (x *RoomInfo) GetLastActivityAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return nil
+}
+
+func (x *RoomInfo) GetUnread() uint32 {
+	if x != nil {
+		return x.Unread
+	}
+	return 0
+}
+
+func (x *RoomInfo) GetRetention() *Retention {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *RoomInfo) GetWatchers() uint32 {
+	if x != nil {
+		return x.Watchers
+	}
+	return 0
+}
+
+// StoredMessage is a message of the history of a room.
+type StoredMessage struct {
+	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Body   string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Id       uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	RoomId   string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	RoomName string                 `protobuf:"bytes,3,opt,name=room_name,json=roomName,proto3" json:"room_name,omitempty"`
+	Author   string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Body     string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	SentAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+}
+
+func (x *StoredMessage) Reset() {
+	*x = StoredMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoredMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredMessage) ProtoMessage() {}
+
+func (x *StoredMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredMessage.ProtoReflect.Descriptor instead.
+func (*StoredMessage) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StoredMessage) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StoredMessage) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetRoomName() string {
+	if x != nil {
+		return x.RoomName
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *StoredMessage) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+// SearchRequest searches the history of the rooms the caller is a member of,
+// having joined them, been invited to them or been granted a role in them.
+// The empty fields match every message.
+type SearchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Keywords that must all appear in the messages, case-insensitively.
+	Query  string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
 	Author string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	// The id or the name of a room.
+	Room  string                 `protobuf:"bytes,3,opt,name=room,proto3" json:"room,omitempty"`
+	Since *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Until *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=until,proto3" json:"until,omitempty"`
+	// Defaults to 20, up to 100.
+	PageSize uint32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// The next_page_token of the previous page.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
-func (x *ServerMessage_ServerForwardMessage) Reset() {
-	*x = ServerMessage_ServerForwardMessage{}
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The matching messages, newest first.
+	Messages []*StoredMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_pbuf_chat_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -417,184 +1067,3063 @@ func (x *ServerMessage_ServerForwardMessage) Reset() {
 	}
 }
 
-func (x *ServerMessage_ServerForwardMessage) String() string {
+func (x *SearchResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ServerMessage_ServerForwardMessage) ProtoMessage() {}
+func (*SearchResponse) ProtoMessage() {}
 
-func (x *ServerMessage_ServerForwardMessage) ProtoReflect() protoreflect.Message {
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_pbuf_chat_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use ServerMessage_ServerForwardMessage.ProtoReflect.Descriptor instead.
-func (*ServerMessage_ServerForwardMessage) Descriptor() ([]byte, []int) {
-	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 1}
-}
-
-func (x *ServerMessage_ServerForwardMessage) GetBody() string {
-	if x != nil {
-		return x.Body
-	}
-	return ""
-}
-
-func (x *ServerMessage_ServerForwardMessage) GetAuthor() string {
-	if x != nil {
-		return x.Author
-	}
-	return ""
-}
-
-type ServerMessage_ServerConfirmRoomCheckout struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-}
-
-func (x *ServerMessage_ServerConfirmRoomCheckout) Reset() {
-	*x = ServerMessage_ServerConfirmRoomCheckout{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pbuf_chat_proto_msgTypes[7]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
-}
-
-func (x *ServerMessage_ServerConfirmRoomCheckout) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*ServerMessage_ServerConfirmRoomCheckout) ProtoMessage() {}
-
-func (x *ServerMessage_ServerConfirmRoomCheckout) ProtoReflect() protoreflect.Message {
-	mi := &file_pbuf_chat_proto_msgTypes[7]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchResponse) GetMessages() []*StoredMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// GetHistoryRequest reads a page of the history of a room the caller can
+// see. Without anchor, the page holds the last messages of the room.
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id or the name of the room.
+	Room string `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// Types that are assignable to Anchor:
+	//	*GetHistoryRequest_BeforeId
+	//	*GetHistoryRequest_AfterId
+	//	*GetHistoryRequest_Before
+	//	*GetHistoryRequest_After
+	//	*GetHistoryRequest_Cursor
+	Anchor isGetHistoryRequest_Anchor `protobuf_oneof:"anchor"`
+	// Defaults to 50, up to 200.
+	PageSize uint32 `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetHistoryRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (m *GetHistoryRequest) GetAnchor() isGetHistoryRequest_Anchor {
+	if m != nil {
+		return m.Anchor
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetBeforeId() uint64 {
+	if x, ok := x.GetAnchor().(*GetHistoryRequest_BeforeId); ok {
+		return x.BeforeId
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetAfterId() uint64 {
+	if x, ok := x.GetAnchor().(*GetHistoryRequest_AfterId); ok {
+		return x.AfterId
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetBefore() *timestamppb.Timestamp {
+	if x, ok := x.GetAnchor().(*GetHistoryRequest_Before); ok {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetAfter() *timestamppb.Timestamp {
+	if x, ok := x.GetAnchor().(*GetHistoryRequest_After); ok {
+		return x.After
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetCursor() string {
+	if x, ok := x.GetAnchor().(*GetHistoryRequest_Cursor); ok {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type isGetHistoryRequest_Anchor interface {
+	isGetHistoryRequest_Anchor()
+}
+
+type GetHistoryRequest_BeforeId struct {
+	// The messages before or after a message.
+	BeforeId uint64 `protobuf:"varint,2,opt,name=before_id,json=beforeId,proto3,oneof"`
+}
+
+type GetHistoryRequest_AfterId struct {
+	AfterId uint64 `protobuf:"varint,3,opt,name=after_id,json=afterId,proto3,oneof"`
+}
+
+type GetHistoryRequest_Before struct {
+	// The messages before or after a time.
+	Before *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=before,proto3,oneof"`
+}
+
+type GetHistoryRequest_After struct {
+	After *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=after,proto3,oneof"`
+}
+
+type GetHistoryRequest_Cursor struct {
+	// The next_cursor of the previous page.
+	Cursor string `protobuf:"bytes,6,opt,name=cursor,proto3,oneof"`
+}
+
+func (*GetHistoryRequest_BeforeId) isGetHistoryRequest_Anchor() {}
+
+func (*GetHistoryRequest_AfterId) isGetHistoryRequest_Anchor() {}
+
+func (*GetHistoryRequest_Before) isGetHistoryRequest_Anchor() {}
+
+func (*GetHistoryRequest_After) isGetHistoryRequest_Anchor() {}
+
+func (*GetHistoryRequest_Cursor) isGetHistoryRequest_Anchor() {}
+
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The messages of the page, oldest first.
+	Messages []*StoredMessage `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Continues in the same direction as the request: backward, unless it
+	// was anchored after a message or a time. Empty when there is no more
+	// message that way.
+	NextCursor string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetHistoryResponse) GetMessages() []*StoredMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type WatchRoomRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id or the name of the room.
+	Room string `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+}
+
+func (x *WatchRoomRequest) Reset() {
+	*x = WatchRoomRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchRoomRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRoomRequest) ProtoMessage() {}
+
+func (x *WatchRoomRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRoomRequest.ProtoReflect.Descriptor instead.
+func (*WatchRoomRequest) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchRoomRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+type ClientMessage_ClientHelo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Author string `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+}
+
+func (x *ClientMessage_ClientHelo) Reset() {
+	*x = ClientMessage_ClientHelo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientHelo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientHelo) ProtoMessage() {}
+
+func (x *ClientMessage_ClientHelo) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientHelo.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientHelo) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *ClientMessage_ClientHelo) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+type ClientMessage_ClientQuit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ClientMessage_ClientQuit) Reset() {
+	*x = ClientMessage_ClientQuit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientQuit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientQuit) ProtoMessage() {}
+
+func (x *ClientMessage_ClientQuit) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientQuit.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientQuit) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 1}
+}
+
+type ClientMessage_ClientWriteMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Body string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (x *ClientMessage_ClientWriteMessage) Reset() {
+	*x = ClientMessage_ClientWriteMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientWriteMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientWriteMessage) ProtoMessage() {}
+
+func (x *ClientMessage_ClientWriteMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientWriteMessage.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientWriteMessage) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 2}
+}
+
+func (x *ClientMessage_ClientWriteMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type ClientMessage_ClientKick struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Reason   string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *ClientMessage_ClientKick) Reset() {
+	*x = ClientMessage_ClientKick{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientKick) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientKick) ProtoMessage() {}
+
+func (x *ClientMessage_ClientKick) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientKick.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientKick) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 3}
+}
+
+func (x *ClientMessage_ClientKick) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientKick) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ClientMessage_ClientBan struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// A zero duration bans forever.
+	Duration *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason   string               `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *ClientMessage_ClientBan) Reset() {
+	*x = ClientMessage_ClientBan{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientBan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientBan) ProtoMessage() {}
+
+func (x *ClientMessage_ClientBan) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientBan.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientBan) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 4}
+}
+
+func (x *ClientMessage_ClientBan) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientBan) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *ClientMessage_ClientBan) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ClientMessage_ClientMute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Muted    bool   `protobuf:"varint,2,opt,name=muted,proto3" json:"muted,omitempty"`
+}
+
+func (x *ClientMessage_ClientMute) Reset() {
+	*x = ClientMessage_ClientMute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientMute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientMute) ProtoMessage() {}
+
+func (x *ClientMessage_ClientMute) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientMute.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientMute) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 5}
+}
+
+func (x *ClientMessage_ClientMute) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientMute) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+type ClientMessage_ClientSetRole struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Role     Role   `protobuf:"varint,2,opt,name=role,proto3,enum=pbuf.Role" json:"role,omitempty"`
+}
+
+func (x *ClientMessage_ClientSetRole) Reset() {
+	*x = ClientMessage_ClientSetRole{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientSetRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientSetRole) ProtoMessage() {}
+
+func (x *ClientMessage_ClientSetRole) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientSetRole.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientSetRole) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 6}
+}
+
+func (x *ClientMessage_ClientSetRole) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientSetRole) GetRole() Role {
+	if x != nil {
+		return x.Role
+	}
+	return Role_Member
+}
+
+type ClientMessage_ClientSetRoomMode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Mode RoomMode `protobuf:"varint,1,opt,name=mode,proto3,enum=pbuf.RoomMode" json:"mode,omitempty"`
+	// Required by the Slow mode.
+	SlowModeInterval *durationpb.Duration `protobuf:"bytes,2,opt,name=slow_mode_interval,json=slowModeInterval,proto3" json:"slow_mode_interval,omitempty"`
+}
+
+func (x *ClientMessage_ClientSetRoomMode) Reset() {
+	*x = ClientMessage_ClientSetRoomMode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientSetRoomMode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientSetRoomMode) ProtoMessage() {}
+
+func (x *ClientMessage_ClientSetRoomMode) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientSetRoomMode.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientSetRoomMode) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 7}
+}
+
+func (x *ClientMessage_ClientSetRoomMode) GetMode() RoomMode {
+	if x != nil {
+		return x.Mode
+	}
+	return RoomMode_Normal
+}
+
+func (x *ClientMessage_ClientSetRoomMode) GetSlowModeInterval() *durationpb.Duration {
+	if x != nil {
+		return x.SlowModeInterval
+	}
+	return nil
+}
+
+type ClientMessage_ClientListRooms struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ClientMessage_ClientListRooms) Reset() {
+	*x = ClientMessage_ClientListRooms{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientListRooms) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientListRooms) ProtoMessage() {}
+
+func (x *ClientMessage_ClientListRooms) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientListRooms.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientListRooms) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 8}
+}
+
+type ClientMessage_ClientJoinRoom struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id or the name of the room. When empty, the room is the one of the
+	// invite code.
+	Room string `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// Required by private rooms the participant is not a member of.
+	InviteCode string `protobuf:"bytes,2,opt,name=invite_code,json=inviteCode,proto3" json:"invite_code,omitempty"`
+}
+
+func (x *ClientMessage_ClientJoinRoom) Reset() {
+	*x = ClientMessage_ClientJoinRoom{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientJoinRoom) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientJoinRoom) ProtoMessage() {}
+
+func (x *ClientMessage_ClientJoinRoom) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientJoinRoom.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientJoinRoom) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 9}
+}
+
+func (x *ClientMessage_ClientJoinRoom) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientJoinRoom) GetInviteCode() string {
+	if x != nil {
+		return x.InviteCode
+	}
+	return ""
+}
+
+type ClientMessage_ClientCreateInvite struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Zero means unlimited uses.
+	MaxUses uint32 `protobuf:"varint,1,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+	// When zero, the server picks a default.
+	Ttl *durationpb.Duration `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (x *ClientMessage_ClientCreateInvite) Reset() {
+	*x = ClientMessage_ClientCreateInvite{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientCreateInvite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientCreateInvite) ProtoMessage() {}
+
+func (x *ClientMessage_ClientCreateInvite) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientCreateInvite.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientCreateInvite) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 10}
+}
+
+func (x *ClientMessage_ClientCreateInvite) GetMaxUses() uint32 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+func (x *ClientMessage_ClientCreateInvite) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type ClientMessage_ClientGetRoomInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ClientMessage_ClientGetRoomInfo) Reset() {
+	*x = ClientMessage_ClientGetRoomInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientGetRoomInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientGetRoomInfo) ProtoMessage() {}
+
+func (x *ClientMessage_ClientGetRoomInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientGetRoomInfo.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientGetRoomInfo) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 11}
+}
+
+type ClientMessage_ClientSetTopic struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// An empty topic clears it.
+	Topic string `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+}
+
+func (x *ClientMessage_ClientSetTopic) Reset() {
+	*x = ClientMessage_ClientSetTopic{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientSetTopic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientSetTopic) ProtoMessage() {}
+
+func (x *ClientMessage_ClientSetTopic) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientSetTopic.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientSetTopic) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 12}
+}
+
+func (x *ClientMessage_ClientSetTopic) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type ClientMessage_ClientSetDescription struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Description string `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+}
+
+func (x *ClientMessage_ClientSetDescription) Reset() {
+	*x = ClientMessage_ClientSetDescription{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientSetDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientSetDescription) ProtoMessage() {}
+
+func (x *ClientMessage_ClientSetDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientSetDescription.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientSetDescription) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 13}
+}
+
+func (x *ClientMessage_ClientSetDescription) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type ClientMessage_ClientSetLabel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// An empty value removes the label.
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *ClientMessage_ClientSetLabel) Reset() {
+	*x = ClientMessage_ClientSetLabel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientSetLabel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientSetLabel) ProtoMessage() {}
+
+func (x *ClientMessage_ClientSetLabel) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientSetLabel.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientSetLabel) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 14}
+}
+
+func (x *ClientMessage_ClientSetLabel) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientSetLabel) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type ClientMessage_ClientDirectMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	To   string `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"`
+	Body string `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (x *ClientMessage_ClientDirectMessage) Reset() {
+	*x = ClientMessage_ClientDirectMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientDirectMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientDirectMessage) ProtoMessage() {}
+
+func (x *ClientMessage_ClientDirectMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientDirectMessage.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientDirectMessage) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 15}
+}
+
+func (x *ClientMessage_ClientDirectMessage) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientDirectMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type ClientMessage_ClientSearch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Request *SearchRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+}
+
+func (x *ClientMessage_ClientSearch) Reset() {
+	*x = ClientMessage_ClientSearch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientSearch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientSearch) ProtoMessage() {}
+
+func (x *ClientMessage_ClientSearch) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientSearch.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientSearch) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 16}
+}
+
+func (x *ClientMessage_ClientSearch) GetRequest() *SearchRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+type ClientMessage_ClientGetHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The room defaults to the current one.
+	Request *GetHistoryRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+}
+
+func (x *ClientMessage_ClientGetHistory) Reset() {
+	*x = ClientMessage_ClientGetHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientGetHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientGetHistory) ProtoMessage() {}
+
+func (x *ClientMessage_ClientGetHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientGetHistory.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientGetHistory) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 17}
+}
+
+func (x *ClientMessage_ClientGetHistory) GetRequest() *GetHistoryRequest {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+type ClientMessage_ClientMarkRead struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id or the name of the room, the current room when empty.
+	Room string `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// The last message read, the last message of the room when zero.
+	MessageId uint64 `protobuf:"varint,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Broadcast a read receipt to the participants of the room.
+	Share bool `protobuf:"varint,3,opt,name=share,proto3" json:"share,omitempty"`
+}
+
+func (x *ClientMessage_ClientMarkRead) Reset() {
+	*x = ClientMessage_ClientMarkRead{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClientMessage_ClientMarkRead) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientMessage_ClientMarkRead) ProtoMessage() {}
+
+func (x *ClientMessage_ClientMarkRead) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientMessage_ClientMarkRead.ProtoReflect.Descriptor instead.
+func (*ClientMessage_ClientMarkRead) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{0, 18}
+}
+
+func (x *ClientMessage_ClientMarkRead) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *ClientMessage_ClientMarkRead) GetMessageId() uint64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *ClientMessage_ClientMarkRead) GetShare() bool {
+	if x != nil {
+		return x.Share
+	}
+	return false
+}
+
+type ServerMessage_ServerShutdown struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *ServerMessage_ServerShutdown) Reset() {
+	*x = ServerMessage_ServerShutdown{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerShutdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerShutdown) ProtoMessage() {}
+
+func (x *ServerMessage_ServerShutdown) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerShutdown.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerShutdown) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *ServerMessage_ServerShutdown) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ServerMessage_ServerForwardMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Body   string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	Author string `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	// Assigned by the history store, zero when the message was not stored.
+	Id     uint64                 `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	SentAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+}
+
+func (x *ServerMessage_ServerForwardMessage) Reset() {
+	*x = ServerMessage_ServerForwardMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerForwardMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerForwardMessage) ProtoMessage() {}
+
+func (x *ServerMessage_ServerForwardMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerForwardMessage.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerForwardMessage) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 1}
+}
+
+func (x *ServerMessage_ServerForwardMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerForwardMessage) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerForwardMessage) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ServerMessage_ServerForwardMessage) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+type ServerMessage_ServerConfirmRoomCheckout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Room *RoomInfo `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+}
+
+func (x *ServerMessage_ServerConfirmRoomCheckout) Reset() {
+	*x = ServerMessage_ServerConfirmRoomCheckout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerConfirmRoomCheckout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerConfirmRoomCheckout) ProtoMessage() {}
+
+func (x *ServerMessage_ServerConfirmRoomCheckout) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerConfirmRoomCheckout.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerConfirmRoomCheckout) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 2}
+}
+
+func (x *ServerMessage_ServerConfirmRoomCheckout) GetRoom() *RoomInfo {
+	if x != nil {
+		return x.Room
+	}
+	return nil
+}
+
+type ServerMessage_ServerAnnouncement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Body string `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (x *ServerMessage_ServerAnnouncement) Reset() {
+	*x = ServerMessage_ServerAnnouncement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerAnnouncement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerAnnouncement) ProtoMessage() {}
+
+func (x *ServerMessage_ServerAnnouncement) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerAnnouncement.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerAnnouncement) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 3}
+}
+
+func (x *ServerMessage_ServerAnnouncement) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+type ServerMessage_ServerError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code    ServerMessage_ServerError_Code `protobuf:"varint,1,opt,name=code,proto3,enum=pbuf.ServerMessage_ServerError_Code" json:"code,omitempty"`
+	Message string                         `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// The command that caused the error.
+	Command ClientMessage_ClientCommand `protobuf:"varint,3,opt,name=command,proto3,enum=pbuf.ClientMessage_ClientCommand" json:"command,omitempty"`
+}
+
+func (x *ServerMessage_ServerError) Reset() {
+	*x = ServerMessage_ServerError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerError) ProtoMessage() {}
+
+func (x *ServerMessage_ServerError) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerError.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerError) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 4}
+}
+
+func (x *ServerMessage_ServerError) GetCode() ServerMessage_ServerError_Code {
+	if x != nil {
+		return x.Code
+	}
+	return ServerMessage_ServerError_Internal
+}
+
+func (x *ServerMessage_ServerError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerError) GetCommand() ClientMessage_ClientCommand {
+	if x != nil {
+		return x.Command
+	}
+	return ClientMessage_Helo
+}
+
+type ServerMessage_ServerRoomState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoomId           string               `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Mode             RoomMode             `protobuf:"varint,2,opt,name=mode,proto3,enum=pbuf.RoomMode" json:"mode,omitempty"`
+	SlowModeInterval *durationpb.Duration `protobuf:"bytes,3,opt,name=slow_mode_interval,json=slowModeInterval,proto3" json:"slow_mode_interval,omitempty"`
+}
+
+func (x *ServerMessage_ServerRoomState) Reset() {
+	*x = ServerMessage_ServerRoomState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerRoomState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerRoomState) ProtoMessage() {}
+
+func (x *ServerMessage_ServerRoomState) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerRoomState.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerRoomState) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 5}
+}
+
+func (x *ServerMessage_ServerRoomState) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerRoomState) GetMode() RoomMode {
+	if x != nil {
+		return x.Mode
+	}
+	return RoomMode_Normal
+}
+
+func (x *ServerMessage_ServerRoomState) GetSlowModeInterval() *durationpb.Duration {
+	if x != nil {
+		return x.SlowModeInterval
+	}
+	return nil
+}
+
+type ServerMessage_ServerRoomList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rooms []*RoomInfo `protobuf:"bytes,1,rep,name=rooms,proto3" json:"rooms,omitempty"`
+}
+
+func (x *ServerMessage_ServerRoomList) Reset() {
+	*x = ServerMessage_ServerRoomList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerRoomList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerRoomList) ProtoMessage() {}
+
+func (x *ServerMessage_ServerRoomList) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerRoomList.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerRoomList) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 6}
+}
+
+func (x *ServerMessage_ServerRoomList) GetRooms() []*RoomInfo {
+	if x != nil {
+		return x.Rooms
+	}
+	return nil
+}
+
+type ServerMessage_ServerInviteCreated struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoomId    string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Code      string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Zero means unlimited uses.
+	MaxUses uint32 `protobuf:"varint,4,opt,name=max_uses,json=maxUses,proto3" json:"max_uses,omitempty"`
+}
+
+func (x *ServerMessage_ServerInviteCreated) Reset() {
+	*x = ServerMessage_ServerInviteCreated{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerInviteCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerInviteCreated) ProtoMessage() {}
+
+func (x *ServerMessage_ServerInviteCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerInviteCreated.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerInviteCreated) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 7}
+}
+
+func (x *ServerMessage_ServerInviteCreated) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerInviteCreated) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerInviteCreated) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ServerMessage_ServerInviteCreated) GetMaxUses() uint32 {
+	if x != nil {
+		return x.MaxUses
+	}
+	return 0
+}
+
+type ServerMessage_ServerQueuePosition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Room *RoomInfo `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// The 1-based position in the waiting queue of the room.
+	Position uint32 `protobuf:"varint,2,opt,name=position,proto3" json:"position,omitempty"`
+}
+
+func (x *ServerMessage_ServerQueuePosition) Reset() {
+	*x = ServerMessage_ServerQueuePosition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerQueuePosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerQueuePosition) ProtoMessage() {}
+
+func (x *ServerMessage_ServerQueuePosition) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerQueuePosition.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerQueuePosition) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 8}
+}
+
+func (x *ServerMessage_ServerQueuePosition) GetRoom() *RoomInfo {
+	if x != nil {
+		return x.Room
+	}
+	return nil
+}
+
+func (x *ServerMessage_ServerQueuePosition) GetPosition() uint32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+type ServerMessage_ServerRoomDetails struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Room *RoomInfo `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+}
+
+func (x *ServerMessage_ServerRoomDetails) Reset() {
+	*x = ServerMessage_ServerRoomDetails{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerRoomDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerRoomDetails) ProtoMessage() {}
+
+func (x *ServerMessage_ServerRoomDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerRoomDetails.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerRoomDetails) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 9}
+}
+
+func (x *ServerMessage_ServerRoomDetails) GetRoom() *RoomInfo {
+	if x != nil {
+		return x.Room
+	}
+	return nil
+}
+
+type ServerMessage_ServerTopicChanged struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoomId    string `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Topic     string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	ChangedBy string `protobuf:"bytes,3,opt,name=changed_by,json=changedBy,proto3" json:"changed_by,omitempty"`
+}
+
+func (x *ServerMessage_ServerTopicChanged) Reset() {
+	*x = ServerMessage_ServerTopicChanged{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerTopicChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerTopicChanged) ProtoMessage() {}
+
+func (x *ServerMessage_ServerTopicChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerTopicChanged.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerTopicChanged) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 10}
+}
+
+func (x *ServerMessage_ServerTopicChanged) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerTopicChanged) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerTopicChanged) GetChangedBy() string {
+	if x != nil {
+		return x.ChangedBy
+	}
+	return ""
+}
+
+type ServerMessage_ServerDirectMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From   string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Body   string                 `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	SentAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	// Set when the message was kept in the mailbox while the recipient was
+	// offline.
+	Offline bool `protobuf:"varint,4,opt,name=offline,proto3" json:"offline,omitempty"`
+}
+
+func (x *ServerMessage_ServerDirectMessage) Reset() {
+	*x = ServerMessage_ServerDirectMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerDirectMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerDirectMessage) ProtoMessage() {}
+
+func (x *ServerMessage_ServerDirectMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerDirectMessage.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerDirectMessage) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 11}
+}
+
+func (x *ServerMessage_ServerDirectMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerDirectMessage) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerDirectMessage) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+func (x *ServerMessage_ServerDirectMessage) GetOffline() bool {
+	if x != nil {
+		return x.Offline
+	}
+	return false
+}
+
+type ServerMessage_ServerMention struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoomId    string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	RoomName  string                 `protobuf:"bytes,2,opt,name=room_name,json=roomName,proto3" json:"room_name,omitempty"`
+	Author    string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Body      string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	MessageId uint64                 `protobuf:"varint,5,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	SentAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=sent_at,json=sentAt,proto3" json:"sent_at,omitempty"`
+	Offline   bool                   `protobuf:"varint,7,opt,name=offline,proto3" json:"offline,omitempty"`
+}
+
+func (x *ServerMessage_ServerMention) Reset() {
+	*x = ServerMessage_ServerMention{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerMention) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerMention) ProtoMessage() {}
+
+func (x *ServerMessage_ServerMention) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerMention.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerMention) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 12}
+}
+
+func (x *ServerMessage_ServerMention) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerMention) GetRoomName() string {
+	if x != nil {
+		return x.RoomName
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerMention) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerMention) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerMention) GetMessageId() uint64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *ServerMessage_ServerMention) GetSentAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SentAt
+	}
+	return nil
+}
+
+func (x *ServerMessage_ServerMention) GetOffline() bool {
+	if x != nil {
+		return x.Offline
+	}
+	return false
+}
+
+type ServerMessage_ServerSearchResults struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Response *SearchResponse `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+}
+
+func (x *ServerMessage_ServerSearchResults) Reset() {
+	*x = ServerMessage_ServerSearchResults{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerSearchResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerSearchResults) ProtoMessage() {}
+
+func (x *ServerMessage_ServerSearchResults) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerSearchResults.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerSearchResults) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 13}
+}
+
+func (x *ServerMessage_ServerSearchResults) GetResponse() *SearchResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+type ServerMessage_ServerHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoomId   string              `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Response *GetHistoryResponse `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+}
+
+func (x *ServerMessage_ServerHistory) Reset() {
+	*x = ServerMessage_ServerHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerHistory) ProtoMessage() {}
+
+func (x *ServerMessage_ServerHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerHistory.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerHistory) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 14}
+}
+
+func (x *ServerMessage_ServerHistory) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerHistory) GetResponse() *GetHistoryResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+type ServerMessage_ServerReadReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoomId    string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Username  string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	MessageId uint64                 `protobuf:"varint,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	ReadAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=read_at,json=readAt,proto3" json:"read_at,omitempty"`
+}
+
+func (x *ServerMessage_ServerReadReceipt) Reset() {
+	*x = ServerMessage_ServerReadReceipt{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pbuf_chat_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ServerMessage_ServerReadReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerMessage_ServerReadReceipt) ProtoMessage() {}
+
+func (x *ServerMessage_ServerReadReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_pbuf_chat_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerMessage_ServerReadReceipt.ProtoReflect.Descriptor instead.
+func (*ServerMessage_ServerReadReceipt) Descriptor() ([]byte, []int) {
+	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 15}
+}
+
+func (x *ServerMessage_ServerReadReceipt) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerReadReceipt) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ServerMessage_ServerReadReceipt) GetMessageId() uint64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *ServerMessage_ServerReadReceipt) GetReadAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReadAt
+	}
+	return nil
+}
+
+var File_pbuf_chat_proto protoreflect.FileDescriptor
+
+var file_pbuf_chat_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x70, 0x62, 0x75, 0x66, 0x2f, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x04, 0x70, 0x62, 0x75, 0x66, 0x1a, 0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xcd, 0x0c, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x09,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x70, 0x62, 0x75,
+	0x66, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x24, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x48, 0x65, 0x6c, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x1a, 0x0c, 0x0a, 0x0a,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x69, 0x74, 0x1a, 0x28, 0x0a, 0x12, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x62, 0x6f, 0x64, 0x79, 0x1a, 0x40, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4b, 0x69,
+	0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x1a, 0x76, 0x0a, 0x09, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x42, 0x61, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x35, 0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x1a, 0x3e,
+	0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x75, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08,
+	0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x75, 0x74, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x6d, 0x75, 0x74, 0x65, 0x64, 0x1a, 0x4b,
+	0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x12,
+	0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x04, 0x72,
+	0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x70, 0x62, 0x75, 0x66,
+	0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x1a, 0x80, 0x01, 0x0a, 0x11,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x6f, 0x6d, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x22, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0e, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x47, 0x0a, 0x12, 0x73, 0x6c, 0x6f, 0x77, 0x5f, 0x6d, 0x6f,
+	0x64, 0x65, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x73, 0x6c,
+	0x6f, 0x77, 0x4d, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x1a, 0x11,
+	0x0a, 0x0f, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x6f, 0x6f, 0x6d,
+	0x73, 0x1a, 0x45, 0x0a, 0x0e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4a, 0x6f, 0x69, 0x6e, 0x52,
+	0x6f, 0x6f, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x76, 0x69, 0x74,
+	0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x6e,
+	0x76, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x1a, 0x5c, 0x0a, 0x12, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x12, 0x19,
+	0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x6d, 0x61, 0x78, 0x55, 0x73, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x03, 0x74, 0x74, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x1a, 0x13, 0x0a, 0x11, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x47, 0x65, 0x74, 0x52, 0x6f, 0x6f, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x26, 0x0a, 0x0e, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x70, 0x69, 0x63, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f,
+	0x70, 0x69, 0x63, 0x1a, 0x38, 0x0a, 0x14, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x74,
+	0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x38, 0x0a,
+	0x0e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x74, 0x4c, 0x61, 0x62, 0x65, 0x6c, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x1a, 0x39, 0x0a, 0x13, 0x43, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x0e,
+	0x0a, 0x02, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x12,
+	0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f,
+	0x64, 0x79, 0x1a, 0x3d, 0x0a, 0x0c, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x12, 0x2d, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x45, 0x0a, 0x10, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x47, 0x65, 0x74, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x47, 0x65,
+	0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52,
+	0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x59, 0x0a, 0x0e, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x4d, 0x61, 0x72, 0x6b, 0x52, 0x65, 0x61, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f,
+	0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x22, 0x9d, 0x02, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x65, 0x6c, 0x6f, 0x10, 0x00, 0x12,
+	0x08, 0x0a, 0x04, 0x51, 0x75, 0x69, 0x74, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x4b,
+	0x69, 0x63, 0x6b, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x42, 0x61, 0x6e, 0x10, 0x04, 0x12, 0x08,
+	0x0a, 0x04, 0x4d, 0x75, 0x74, 0x65, 0x10, 0x05, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x65, 0x74, 0x52,
+	0x6f, 0x6c, 0x65, 0x10, 0x06, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x52, 0x6f, 0x6f, 0x6d,
+	0x4d, 0x6f, 0x64, 0x65, 0x10, 0x07, 0x12, 0x0d, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x6f,
+	0x6f, 0x6d, 0x73, 0x10, 0x08, 0x12, 0x0c, 0x0a, 0x08, 0x4a, 0x6f, 0x69, 0x6e, 0x52, 0x6f, 0x6f,
+	0x6d, 0x10, 0x09, 0x12, 0x10, 0x0a, 0x0c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x76,
+	0x69, 0x74, 0x65, 0x10, 0x0a, 0x12, 0x0f, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x6f, 0x6d,
+	0x49, 0x6e, 0x66, 0x6f, 0x10, 0x0b, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x54, 0x6f, 0x70,
+	0x69, 0x63, 0x10, 0x0c, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x44, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x0d, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x65, 0x74, 0x4c,
+	0x61, 0x62, 0x65, 0x6c, 0x10, 0x0e, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x10, 0x0f, 0x12, 0x0c, 0x0a, 0x08, 0x4d, 0x61, 0x72,
+	0x6b, 0x52, 0x65, 0x61, 0x64, 0x10, 0x10, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x10, 0x11, 0x12, 0x0e, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x10, 0x12, 0x22, 0xf5, 0x11, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x09,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x70, 0x62, 0x75,
+	0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x28, 0x0a, 0x0e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x1a, 0x87, 0x01, 0x0a, 0x14, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x46, 0x6f, 0x72, 0x77, 0x61,
+	0x72, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x16, 0x0a,
+	0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x74, 0x41, 0x74, 0x1a, 0x3f, 0x0a, 0x19, 0x53, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x52, 0x6f, 0x6f, 0x6d, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x12, 0x22, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f,
+	0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x1a, 0x28, 0x0a, 0x12, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x62, 0x6f, 0x64, 0x79, 0x1a, 0x98, 0x02, 0x0a, 0x0b, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x38, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x24, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3b, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x70, 0x62, 0x75,
+	0x66, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
+	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x78, 0x0a, 0x04, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x0c,
+	0x0a, 0x08, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f,
+	0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x41, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x10,
+	0x01, 0x12, 0x0c, 0x0a, 0x08, 0x4e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x10, 0x02, 0x12,
+	0x14, 0x0a, 0x10, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x6e,
+	0x69, 0x65, 0x64, 0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x42, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x10,
+	0x04, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x64,
+	0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x6f, 0x6f, 0x6d, 0x46, 0x75, 0x6c, 0x6c, 0x10, 0x06,
+	0x1a, 0x97, 0x01, 0x0a, 0x0f, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x6f, 0x6f, 0x6d, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x22, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x62,
+	0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64,
+	0x65, 0x12, 0x47, 0x0a, 0x12, 0x73, 0x6c, 0x6f, 0x77, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x5f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x73, 0x6c, 0x6f, 0x77, 0x4d, 0x6f,
+	0x64, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x1a, 0x36, 0x0a, 0x0e, 0x53, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x52, 0x6f, 0x6f, 0x6d, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x24, 0x0a, 0x05,
+	0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62,
+	0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x72, 0x6f, 0x6f,
+	0x6d, 0x73, 0x1a, 0x98, 0x01, 0x0a, 0x13, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x6e, 0x76,
+	0x69, 0x74, 0x65, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f,
+	0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f,
+	0x6d, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73,
+	0x41, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x75, 0x73, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x55, 0x73, 0x65, 0x73, 0x1a, 0x55, 0x0a,
+	0x13, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x22, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x37, 0x0a, 0x11, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x6f,
+	0x6f, 0x6d, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x22, 0x0a, 0x04, 0x72, 0x6f, 0x6f,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52,
+	0x6f, 0x6f, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x1a, 0x62, 0x0a,
+	0x12, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x6f, 0x70, 0x69, 0x63, 0x43, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x70,
+	0x69, 0x63, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x5f, 0x62, 0x79,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x42,
+	0x79, 0x1a, 0x8c, 0x01, 0x0a, 0x13, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x44, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x12, 0x0a,
+	0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64,
+	0x79, 0x12, 0x33, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x06,
+	0x73, 0x65, 0x6e, 0x74, 0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65,
+	0x1a, 0xdf, 0x01, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65, 0x6e, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x72,
+	0x6f, 0x6f, 0x6d, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x72, 0x6f, 0x6f, 0x6d, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x62, 0x6f, 0x64, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x74, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x06, 0x73, 0x65, 0x6e, 0x74, 0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x66, 0x66, 0x6c,
+	0x69, 0x6e, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x6f, 0x66, 0x66, 0x6c, 0x69,
+	0x6e, 0x65, 0x1a, 0x47, 0x0a, 0x13, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x30, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x62,
+	0x75, 0x66, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x1a, 0x5e, 0x0a, 0x0d, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x17, 0x0a, 0x07,
+	0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x6f, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x47,
+	0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x1a, 0x9c, 0x01, 0x0a, 0x11,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73,
+	0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73,
+	0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x06, 0x72, 0x65, 0x61, 0x64, 0x41, 0x74, 0x22, 0x9e, 0x02, 0x0a, 0x0d, 0x53,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0c, 0x0a, 0x08,
+	0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x6f,
+	0x72, 0x77, 0x61, 0x72, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x10, 0x01, 0x12, 0x17,
+	0x0a, 0x13, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x52, 0x6f, 0x6f, 0x6d, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x6f, 0x75, 0x74, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x41, 0x6e, 0x6e, 0x6f, 0x75,
+	0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x10, 0x04, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x6f, 0x6f, 0x6d, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x6f, 0x6f, 0x6d, 0x4c, 0x69, 0x73, 0x74, 0x10,
+	0x06, 0x12, 0x11, 0x0a, 0x0d, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x10, 0x07, 0x12, 0x11, 0x0a, 0x0d, 0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x08, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x6f, 0x6f, 0x6d, 0x44,
+	0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x10, 0x09, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x6f, 0x70, 0x69,
+	0x63, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x10, 0x0a, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x10, 0x0b, 0x12, 0x0b, 0x0a,
+	0x07, 0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x0c, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x65,
+	0x61, 0x64, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x10, 0x0d, 0x12, 0x11, 0x0a, 0x0d, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x10, 0x0e, 0x12, 0x0b,
+	0x0a, 0x07, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x10, 0x0f, 0x22, 0x7f, 0x0a, 0x09, 0x52,
+	0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f,
+	0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6d, 0x61, 0x78, 0x41, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12,
+	0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0xe3, 0x06, 0x0a,
+	0x08, 0x52, 0x6f, 0x6f, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a,
+	0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74,
+	0x73, 0x12, 0x22, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0e, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x47, 0x0a, 0x12, 0x73, 0x6c, 0x6f, 0x77, 0x5f, 0x6d, 0x6f,
+	0x64, 0x65, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x73, 0x6c,
+	0x6f, 0x77, 0x4d, 0x6f, 0x64, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x34,
+	0x0a, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x14, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x56, 0x69,
+	0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79,
+	0x12, 0x26, 0x0a, 0x0f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x77, 0x68, 0x65, 0x6e, 0x5f, 0x66,
+	0x75, 0x6c, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x57, 0x68, 0x65, 0x6e, 0x46, 0x75, 0x6c, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x77, 0x61, 0x69, 0x74,
+	0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x77, 0x61, 0x69, 0x74, 0x69,
+	0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x12,
+	0x32, 0x0a, 0x06, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f, 0x6f, 0x6d, 0x49, 0x6e, 0x66, 0x6f, 0x2e,
+	0x4c, 0x61, 0x62, 0x65, 0x6c, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x73, 0x12, 0x31, 0x0a, 0x09, 0x6c, 0x69, 0x66, 0x65, 0x63, 0x79, 0x63, 0x6c, 0x65,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x6f,
+	0x6f, 0x6d, 0x4c, 0x69, 0x66, 0x65, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x52, 0x09, 0x6c, 0x69, 0x66,
+	0x65, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x09, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f,
+	0x74, 0x74, 0x6c, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x54, 0x74, 0x6c, 0x12, 0x44,
+	0x0a, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x5f,
+	0x61, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69,
+	0x74, 0x79, 0x41, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x64, 0x18, 0x12,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x75, 0x6e, 0x72, 0x65, 0x61, 0x64, 0x12, 0x2d, 0x0a, 0x09,
+	0x72, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0f, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x52, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x09, 0x72, 0x65, 0x74, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x77,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x77,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x4c, 0x61, 0x62, 0x65, 0x6c,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xb6, 0x01, 0x0a, 0x0d, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x1b, 0x0a,
+	0x09, 0x72, 0x6f, 0x6f, 0x6d, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x72, 0x6f, 0x6f, 0x6d, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75,
+	0x74, 0x68, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x33, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x74, 0x5f, 0x61,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x06, 0x73, 0x65, 0x6e, 0x74, 0x41, 0x74, 0x22, 0xf1, 0x01, 0x0a, 0x0d,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x6f, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x12,
+	0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63,
+	0x65, 0x12, 0x30, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x75, 0x6e,
+	0x74, 0x69, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22,
+	0x69, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x2f, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65,
+	0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78,
+	0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x8e, 0x02, 0x0a, 0x11, 0x47,
+	0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x72, 0x6f, 0x6f, 0x6d, 0x12, 0x1d, 0x0a, 0x09, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x08, 0x62, 0x65, 0x66, 0x6f, 0x72,
+	0x65, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x08, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00, 0x52, 0x07, 0x61, 0x66, 0x74, 0x65, 0x72, 0x49, 0x64,
+	0x12, 0x34, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x48, 0x00, 0x52, 0x06,
+	0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x32, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x48, 0x00, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x06, 0x63, 0x75,
+	0x72, 0x73, 0x6f, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x63, 0x75,
+	0x72, 0x73, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a,
+	0x65, 0x42, 0x08, 0x0a, 0x06, 0x61, 0x6e, 0x63, 0x68, 0x6f, 0x72, 0x22, 0x66, 0x0a, 0x12, 0x47,
+	0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x2f, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65,
+	0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72,
+	0x73, 0x6f, 0x72, 0x22, 0x26, 0x0a, 0x10, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x6f, 0x6f, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x6d, 0x2a, 0x37, 0x0a, 0x04, 0x52,
+	0x6f, 0x6c, 0x65, 0x12, 0x0a, 0x0a, 0x06, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x10, 0x00, 0x12,
+	0x09, 0x0a, 0x05, 0x4d, 0x75, 0x74, 0x65, 0x64, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x4d, 0x6f,
+	0x64, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x10, 0x02, 0x12, 0x09, 0x0a, 0x05, 0x4f, 0x77, 0x6e,
+	0x65, 0x72, 0x10, 0x03, 0x2a, 0x44, 0x0a, 0x08, 0x52, 0x6f, 0x6f, 0x6d, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04,
+	0x53, 0x6c, 0x6f, 0x77, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x52, 0x65, 0x61, 0x64, 0x4f, 0x6e,
+	0x6c, 0x79, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x4f, 0x6e, 0x6c, 0x79, 0x10, 0x03, 0x2a, 0x29, 0x0a, 0x0e, 0x52, 0x6f,
+	0x6f, 0x6d, 0x56, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x0a, 0x0a, 0x06,
+	0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x72, 0x69, 0x76,
+	0x61, 0x74, 0x65, 0x10, 0x01, 0x2a, 0x2e, 0x0a, 0x0d, 0x52, 0x6f, 0x6f, 0x6d, 0x4c, 0x69, 0x66,
+	0x65, 0x63, 0x79, 0x63, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x50, 0x65, 0x72, 0x73, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x74, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x45, 0x70, 0x68, 0x65, 0x6d, 0x65,
+	0x72, 0x61, 0x6c, 0x10, 0x01, 0x32, 0xfb, 0x01, 0x0a, 0x04, 0x43, 0x68, 0x61, 0x74, 0x12, 0x3b,
+	0x0a, 0x09, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x43, 0x68, 0x61, 0x74, 0x12, 0x13, 0x2e, 0x70, 0x62,
+	0x75, 0x66, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x1a, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x35, 0x0a, 0x06, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x75,
+	0x66, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x41, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x12, 0x17, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x62, 0x75, 0x66,
+	0x2e, 0x47, 0x65, 0x74, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x3c, 0x0a, 0x09, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x6f,
+	0x6f, 0x6d, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52,
+	0x6f, 0x6f, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x75,
+	0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22,
+	0x00, 0x30, 0x01, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x73, 0x61, 0x76, 0x6f, 0x39, 0x32, 0x2f, 0x70, 0x6c, 0x61, 0x79, 0x67, 0x72, 0x6f,
+	0x75, 0x6e, 0x64, 0x2d, 0x67, 0x6f, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x63, 0x68, 0x61, 0x74,
+	0x2f, 0x70, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pbuf_chat_proto_rawDescOnce sync.Once
+	file_pbuf_chat_proto_rawDescData = file_pbuf_chat_proto_rawDesc
+)
+
+func file_pbuf_chat_proto_rawDescGZIP() []byte {
+	file_pbuf_chat_proto_rawDescOnce.Do(func() {
+		file_pbuf_chat_proto_rawDescData = protoimpl.X.CompressGZIP(file_pbuf_chat_proto_rawDescData)
+	})
+	return file_pbuf_chat_proto_rawDescData
+}
+
+var file_pbuf_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_pbuf_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
+var file_pbuf_chat_proto_goTypes = []interface{}{
+	(Role)(0),                                       // 0: pbuf.Role
+	(RoomMode)(0),                                   // 1: pbuf.RoomMode
+	(RoomVisibility)(0),                             // 2: pbuf.RoomVisibility
+	(RoomLifecycle)(0),                              // 3: pbuf.RoomLifecycle
+	(ClientMessage_ClientCommand)(0),                // 4: pbuf.ClientMessage.ClientCommand
+	(ServerMessage_ServerCommand)(0),                // 5: pbuf.ServerMessage.ServerCommand
+	(ServerMessage_ServerError_Code)(0),             // 6: pbuf.ServerMessage.ServerError.Code
+	(*ClientMessage)(nil),                           // 7: pbuf.ClientMessage
+	(*ServerMessage)(nil),                           // 8: pbuf.ServerMessage
+	(*Retention)(nil),                               // 9: pbuf.Retention
+	(*RoomInfo)(nil),                                // 10: pbuf.RoomInfo
+	(*StoredMessage)(nil),                           // 11: pbuf.StoredMessage
+	(*SearchRequest)(nil),                           // 12: pbuf.SearchRequest
+	(*SearchResponse)(nil),                          // 13: pbuf.SearchResponse
+	(*GetHistoryRequest)(nil),                       // 14: pbuf.GetHistoryRequest
+	(*GetHistoryResponse)(nil),                      // 15: pbuf.GetHistoryResponse
+	(*WatchRoomRequest)(nil),                        // 16: pbuf.WatchRoomRequest
+	(*ClientMessage_ClientHelo)(nil),                // 17: pbuf.ClientMessage.ClientHelo
+	(*ClientMessage_ClientQuit)(nil),                // 18: pbuf.ClientMessage.ClientQuit
+	(*ClientMessage_ClientWriteMessage)(nil),        // 19: pbuf.ClientMessage.ClientWriteMessage
+	(*ClientMessage_ClientKick)(nil),                // 20: pbuf.ClientMessage.ClientKick
+	(*ClientMessage_ClientBan)(nil),                 // 21: pbuf.ClientMessage.ClientBan
+	(*ClientMessage_ClientMute)(nil),                // 22: pbuf.ClientMessage.ClientMute
+	(*ClientMessage_ClientSetRole)(nil),             // 23: pbuf.ClientMessage.ClientSetRole
+	(*ClientMessage_ClientSetRoomMode)(nil),         // 24: pbuf.ClientMessage.ClientSetRoomMode
+	(*ClientMessage_ClientListRooms)(nil),           // 25: pbuf.ClientMessage.ClientListRooms
+	(*ClientMessage_ClientJoinRoom)(nil),            // 26: pbuf.ClientMessage.ClientJoinRoom
+	(*ClientMessage_ClientCreateInvite)(nil),        // 27: pbuf.ClientMessage.ClientCreateInvite
+	(*ClientMessage_ClientGetRoomInfo)(nil),         // 28: pbuf.ClientMessage.ClientGetRoomInfo
+	(*ClientMessage_ClientSetTopic)(nil),            // 29: pbuf.ClientMessage.ClientSetTopic
+	(*ClientMessage_ClientSetDescription)(nil),      // 30: pbuf.ClientMessage.ClientSetDescription
+	(*ClientMessage_ClientSetLabel)(nil),            // 31: pbuf.ClientMessage.ClientSetLabel
+	(*ClientMessage_ClientDirectMessage)(nil),       // 32: pbuf.ClientMessage.ClientDirectMessage
+	(*ClientMessage_ClientSearch)(nil),              // 33: pbuf.ClientMessage.ClientSearch
+	(*ClientMessage_ClientGetHistory)(nil),          // 34: pbuf.ClientMessage.ClientGetHistory
+	(*ClientMessage_ClientMarkRead)(nil),            // 35: pbuf.ClientMessage.ClientMarkRead
+	(*ServerMessage_ServerShutdown)(nil),            // 36: pbuf.ServerMessage.ServerShutdown
+	(*ServerMessage_ServerForwardMessage)(nil),      // 37: pbuf.ServerMessage.ServerForwardMessage
+	(*ServerMessage_ServerConfirmRoomCheckout)(nil), // 38: pbuf.ServerMessage.ServerConfirmRoomCheckout
+	(*ServerMessage_ServerAnnouncement)(nil),        // 39: pbuf.ServerMessage.ServerAnnouncement
+	(*ServerMessage_ServerError)(nil),               // 40: pbuf.ServerMessage.ServerError
+	(*ServerMessage_ServerRoomState)(nil),           // 41: pbuf.ServerMessage.ServerRoomState
+	(*ServerMessage_ServerRoomList)(nil),            // 42: pbuf.ServerMessage.ServerRoomList
+	(*ServerMessage_ServerInviteCreated)(nil),       // 43: pbuf.ServerMessage.ServerInviteCreated
+	(*ServerMessage_ServerQueuePosition)(nil),       // 44: pbuf.ServerMessage.ServerQueuePosition
+	(*ServerMessage_ServerRoomDetails)(nil),         // 45: pbuf.ServerMessage.ServerRoomDetails
+	(*ServerMessage_ServerTopicChanged)(nil),        // 46: pbuf.ServerMessage.ServerTopicChanged
+	(*ServerMessage_ServerDirectMessage)(nil),       // 47: pbuf.ServerMessage.ServerDirectMessage
+	(*ServerMessage_ServerMention)(nil),             // 48: pbuf.ServerMessage.ServerMention
+	(*ServerMessage_ServerSearchResults)(nil),       // 49: pbuf.ServerMessage.ServerSearchResults
+	(*ServerMessage_ServerHistory)(nil),             // 50: pbuf.ServerMessage.ServerHistory
+	(*ServerMessage_ServerReadReceipt)(nil),         // 51: pbuf.ServerMessage.ServerReadReceipt
+	nil,                                             // 52: pbuf.RoomInfo.LabelsEntry
+	(*anypb.Any)(nil),                               // 53: google.protobuf.Any
+	(*durationpb.Duration)(nil),                     // 54: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),                   // 55: google.protobuf.Timestamp
+}
+var file_pbuf_chat_proto_depIdxs = []int32{
+	53, // 0: pbuf.ClientMessage.operation:type_name -> google.protobuf.Any
+	4,  // 1: pbuf.ClientMessage.command:type_name -> pbuf.ClientMessage.ClientCommand
+	53, // 2: pbuf.ServerMessage.operation:type_name -> google.protobuf.Any
+	5,  // 3: pbuf.ServerMessage.command:type_name -> pbuf.ServerMessage.ServerCommand
+	54, // 4: pbuf.Retention.max_age:type_name -> google.protobuf.Duration
+	1,  // 5: pbuf.RoomInfo.mode:type_name -> pbuf.RoomMode
+	54, // 6: pbuf.RoomInfo.slow_mode_interval:type_name -> google.protobuf.Duration
+	2,  // 7: pbuf.RoomInfo.visibility:type_name -> pbuf.RoomVisibility
+	55, // 8: pbuf.RoomInfo.created_at:type_name -> google.protobuf.Timestamp
+	52, // 9: pbuf.RoomInfo.labels:type_name -> pbuf.RoomInfo.LabelsEntry
+	3,  // 10: pbuf.RoomInfo.lifecycle:type_name -> pbuf.RoomLifecycle
+	54, // 11: pbuf.RoomInfo.empty_ttl:type_name -> google.protobuf.Duration
+	55, // 12: pbuf.RoomInfo.last_activity_at:type_name -> google.protobuf.Timestamp
+	9,  // 13: pbuf.RoomInfo.retention:type_name -> pbuf.Retention
+	55, // 14: pbuf.StoredMessage.sent_at:type_name -> google.protobuf.Timestamp
+	55, // 15: pbuf.SearchRequest.since:type_name -> google.protobuf.Timestamp
+	55, // 16: pbuf.SearchRequest.until:type_name -> google.protobuf.Timestamp
+	11, // 17: pbuf.SearchResponse.messages:type_name -> pbuf.StoredMessage
+	55, // 18: pbuf.GetHistoryRequest.before:type_name -> google.protobuf.Timestamp
+	55, // 19: pbuf.GetHistoryRequest.after:type_name -> google.protobuf.Timestamp
+	11, // 20: pbuf.GetHistoryResponse.messages:type_name -> pbuf.StoredMessage
+	54, // 21: pbuf.ClientMessage.ClientBan.duration:type_name -> google.protobuf.Duration
+	0,  // 22: pbuf.ClientMessage.ClientSetRole.role:type_name -> pbuf.Role
+	1,  // 23: pbuf.ClientMessage.ClientSetRoomMode.mode:type_name -> pbuf.RoomMode
+	54, // 24: pbuf.ClientMessage.ClientSetRoomMode.slow_mode_interval:type_name -> google.protobuf.Duration
+	54, // 25: pbuf.ClientMessage.ClientCreateInvite.ttl:type_name -> google.protobuf.Duration
+	12, // 26: pbuf.ClientMessage.ClientSearch.request:type_name -> pbuf.SearchRequest
+	14, // 27: pbuf.ClientMessage.ClientGetHistory.request:type_name -> pbuf.GetHistoryRequest
+	55, // 28: pbuf.ServerMessage.ServerForwardMessage.sent_at:type_name -> google.protobuf.Timestamp
+	10, // 29: pbuf.ServerMessage.ServerConfirmRoomCheckout.room:type_name -> pbuf.RoomInfo
+	6,  // 30: pbuf.ServerMessage.ServerError.code:type_name -> pbuf.ServerMessage.ServerError.Code
+	4,  // 31: pbuf.ServerMessage.ServerError.command:type_name -> pbuf.ClientMessage.ClientCommand
+	1,  // 32: pbuf.ServerMessage.ServerRoomState.mode:type_name -> pbuf.RoomMode
+	54, // 33: pbuf.ServerMessage.ServerRoomState.slow_mode_interval:type_name -> google.protobuf.Duration
+	10, // 34: pbuf.ServerMessage.ServerRoomList.rooms:type_name -> pbuf.RoomInfo
+	55, // 35: pbuf.ServerMessage.ServerInviteCreated.expires_at:type_name -> google.protobuf.Timestamp
+	10, // 36: pbuf.ServerMessage.ServerQueuePosition.room:type_name -> pbuf.RoomInfo
+	10, // 37: pbuf.ServerMessage.ServerRoomDetails.room:type_name -> pbuf.RoomInfo
+	55, // 38: pbuf.ServerMessage.ServerDirectMessage.sent_at:type_name -> google.protobuf.Timestamp
+	55, // 39: pbuf.ServerMessage.ServerMention.sent_at:type_name -> google.protobuf.Timestamp
+	13, // 40: pbuf.ServerMessage.ServerSearchResults.response:type_name -> pbuf.SearchResponse
+	15, // 41: pbuf.ServerMessage.ServerHistory.response:type_name -> pbuf.GetHistoryResponse
+	55, // 42: pbuf.ServerMessage.ServerReadReceipt.read_at:type_name -> google.protobuf.Timestamp
+	7,  // 43: pbuf.Chat.RouteChat:input_type -> pbuf.ClientMessage
+	12, // 44: pbuf.Chat.Search:input_type -> pbuf.SearchRequest
+	14, // 45: pbuf.Chat.GetHistory:input_type -> pbuf.GetHistoryRequest
+	16, // 46: pbuf.Chat.WatchRoom:input_type -> pbuf.WatchRoomRequest
+	8,  // 47: pbuf.Chat.RouteChat:output_type -> pbuf.ServerMessage
+	13, // 48: pbuf.Chat.Search:output_type -> pbuf.SearchResponse
+	15, // 49: pbuf.Chat.GetHistory:output_type -> pbuf.GetHistoryResponse
+	8,  // 50: pbuf.Chat.WatchRoom:output_type -> pbuf.ServerMessage
+	47, // [47:51] is the sub-list for method output_type
+	43, // [43:47] is the sub-list for method input_type
+	43, // [43:43] is the sub-list for extension type_name
+	43, // [43:43] is the sub-list for extension extendee
+	0,  // [0:43] is the sub-list for field type_name
+}
+
+func init() { file_pbuf_chat_proto_init() }
+func file_pbuf_chat_proto_init() {
+	if File_pbuf_chat_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pbuf_chat_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Retention); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoomInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoredMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetHistoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchRoomRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientHelo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientQuit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientWriteMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientKick); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientBan); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientMute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientSetRole); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientSetRoomMode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientListRooms); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientJoinRoom); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientCreateInvite); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientGetRoomInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientSetTopic); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use ServerMessage_ServerConfirmRoomCheckout.ProtoReflect.Descriptor instead.
-func (*ServerMessage_ServerConfirmRoomCheckout) Descriptor() ([]byte, []int) {
-	return file_pbuf_chat_proto_rawDescGZIP(), []int{1, 2}
-}
-
-var File_pbuf_chat_proto protoreflect.FileDescriptor
-
-var file_pbuf_chat_proto_rawDesc = []byte{
-	0x0a, 0x0f, 0x70, 0x62, 0x75, 0x66, 0x2f, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x04, 0x70, 0x62, 0x75, 0x66, 0x1a, 0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x95, 0x02, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x09, 0x6f,
-	0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x70, 0x62, 0x75, 0x66,
-	0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x43,
-	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x24, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x48,
-	0x65, 0x6c, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x1a, 0x0c, 0x0a, 0x0a, 0x43,
-	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x69, 0x74, 0x1a, 0x28, 0x0a, 0x12, 0x43, 0x6c, 0x69,
-	0x65, 0x6e, 0x74, 0x57, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
-	0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62,
-	0x6f, 0x64, 0x79, 0x22, 0x35, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x65, 0x6c, 0x6f, 0x10, 0x00, 0x12, 0x08,
-	0x0a, 0x04, 0x51, 0x75, 0x69, 0x74, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x57, 0x72, 0x69, 0x74,
-	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x10, 0x02, 0x22, 0xbf, 0x02, 0x0a, 0x0d, 0x53,
-	0x65, 0x72, 0x76, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x32, 0x0a, 0x09,
-	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x09, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x3b, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x21, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x10, 0x0a,
-	0x0e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x1a,
-	0x42, 0x0a, 0x14, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x61,
-	0x75, 0x74, 0x68, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x75, 0x74,
-	0x68, 0x6f, 0x72, 0x1a, 0x1b, 0x0a, 0x19, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6e,
-	0x66, 0x69, 0x72, 0x6d, 0x52, 0x6f, 0x6f, 0x6d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x6f, 0x75, 0x74,
-	0x22, 0x4a, 0x0a, 0x0d, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
-	0x64, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12,
-	0x12, 0x0a, 0x0e, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x52, 0x6f,
-	0x6f, 0x6d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x10, 0x02, 0x32, 0x43, 0x0a, 0x04,
-	0x43, 0x68, 0x61, 0x74, 0x12, 0x3b, 0x0a, 0x09, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x43, 0x68, 0x61,
-	0x74, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x13, 0x2e, 0x70, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30,
-	0x01, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x73, 0x61, 0x76, 0x6f, 0x39, 0x32, 0x2f, 0x70, 0x6c, 0x61, 0x79, 0x67, 0x72, 0x6f, 0x75, 0x6e,
-	0x64, 0x2d, 0x67, 0x6f, 0x2d, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x63, 0x68, 0x61, 0x74, 0x2f, 0x70,
-	0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
-
-var (
-	file_pbuf_chat_proto_rawDescOnce sync.Once
-	file_pbuf_chat_proto_rawDescData = file_pbuf_chat_proto_rawDesc
-)
-
-func file_pbuf_chat_proto_rawDescGZIP() []byte {
-	file_pbuf_chat_proto_rawDescOnce.Do(func() {
-		file_pbuf_chat_proto_rawDescData = protoimpl.X.CompressGZIP(file_pbuf_chat_proto_rawDescData)
-	})
-	return file_pbuf_chat_proto_rawDescData
-}
-
-var file_pbuf_chat_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_pbuf_chat_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
-var file_pbuf_chat_proto_goTypes = []interface{}{
-	(ClientMessage_ClientCommand)(0),                // 0: pbuf.ClientMessage.ClientCommand
-	(ServerMessage_ServerCommand)(0),                // 1: pbuf.ServerMessage.ServerCommand
-	(*ClientMessage)(nil),                           // 2: pbuf.ClientMessage
-	(*ServerMessage)(nil),                           // 3: pbuf.ServerMessage
-	(*ClientMessage_ClientHelo)(nil),                // 4: pbuf.ClientMessage.ClientHelo
-	(*ClientMessage_ClientQuit)(nil),                // 5: pbuf.ClientMessage.ClientQuit
-	(*ClientMessage_ClientWriteMessage)(nil),        // 6: pbuf.ClientMessage.ClientWriteMessage
-	(*ServerMessage_ServerShutdown)(nil),            // 7: pbuf.ServerMessage.ServerShutdown
-	(*ServerMessage_ServerForwardMessage)(nil),      // 8: pbuf.ServerMessage.ServerForwardMessage
-	(*ServerMessage_ServerConfirmRoomCheckout)(nil), // 9: pbuf.ServerMessage.ServerConfirmRoomCheckout
-	(*anypb.Any)(nil),                               // 10: google.protobuf.Any
-}
-var file_pbuf_chat_proto_depIdxs = []int32{
-	10, // 0: pbuf.ClientMessage.operation:type_name -> google.protobuf.Any
-	0,  // 1: pbuf.ClientMessage.command:type_name -> pbuf.ClientMessage.ClientCommand
-	10, // 2: pbuf.ServerMessage.operation:type_name -> google.protobuf.Any
-	1,  // 3: pbuf.ServerMessage.command:type_name -> pbuf.ServerMessage.ServerCommand
-	2,  // 4: pbuf.Chat.RouteChat:input_type -> pbuf.ClientMessage
-	3,  // 5: pbuf.Chat.RouteChat:output_type -> pbuf.ServerMessage
-	5,  // [5:6] is the sub-list for method output_type
-	4,  // [4:5] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
-}
-
-func init() { file_pbuf_chat_proto_init() }
-func file_pbuf_chat_proto_init() {
-	if File_pbuf_chat_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_pbuf_chat_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClientMessage); i {
+		file_pbuf_chat_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientSetDescription); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -605,8 +4134,8 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ServerMessage); i {
+		file_pbuf_chat_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientSetLabel); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -617,8 +4146,8 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClientMessage_ClientHelo); i {
+		file_pbuf_chat_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientDirectMessage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -629,8 +4158,8 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClientMessage_ClientQuit); i {
+		file_pbuf_chat_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientSearch); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -641,8 +4170,8 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClientMessage_ClientWriteMessage); i {
+		file_pbuf_chat_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientGetHistory); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -653,7 +4182,19 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_pbuf_chat_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientMessage_ClientMarkRead); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ServerMessage_ServerShutdown); i {
 			case 0:
 				return &v.state
@@ -665,7 +4206,7 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_pbuf_chat_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ServerMessage_ServerForwardMessage); i {
 			case 0:
 				return &v.state
@@ -677,7 +4218,7 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
-		file_pbuf_chat_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_pbuf_chat_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ServerMessage_ServerConfirmRoomCheckout); i {
 			case 0:
 				return &v.state
@@ -689,14 +4230,177 @@ func file_pbuf_chat_proto_init() {
 				return nil
 			}
 		}
+		file_pbuf_chat_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerAnnouncement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerRoomState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerRoomList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerInviteCreated); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerQueuePosition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerRoomDetails); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerTopicChanged); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerDirectMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerMention); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerSearchResults); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pbuf_chat_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerMessage_ServerReadReceipt); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pbuf_chat_proto_msgTypes[7].OneofWrappers = []interface{}{
+		(*GetHistoryRequest_BeforeId)(nil),
+		(*GetHistoryRequest_AfterId)(nil),
+		(*GetHistoryRequest_Before)(nil),
+		(*GetHistoryRequest_After)(nil),
+		(*GetHistoryRequest_Cursor)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pbuf_chat_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      7,
+			NumMessages:   46,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
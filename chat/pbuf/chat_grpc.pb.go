@@ -1,4 +1,8 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.2
+// source: pbuf/chat.proto
 
 package pbuf
 
@@ -19,6 +23,15 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ChatClient interface {
 	RouteChat(ctx context.Context, opts ...grpc.CallOption) (Chat_RouteChatClient, error)
+	// Search requires the chat-username metadata, identifying the caller as
+	// the Helo does.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// GetHistory requires the chat-username metadata too.
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// WatchRoom streams the messages, announcements, topic and mode changes
+	// of a public room, without joining it. It is read-only, and the watchers
+	// are not members of the room.
+	WatchRoom(ctx context.Context, in *WatchRoomRequest, opts ...grpc.CallOption) (Chat_WatchRoomClient, error)
 }
 
 type chatClient struct {
@@ -60,11 +73,70 @@ func (x *chatRouteChatClient) Recv() (*ServerMessage, error) {
 	return m, nil
 }
 
+func (c *chatClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.Chat/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.Chat/GetHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClient) WatchRoom(ctx context.Context, in *WatchRoomRequest, opts ...grpc.CallOption) (Chat_WatchRoomClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Chat_ServiceDesc.Streams[1], "/pbuf.Chat/WatchRoom", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatWatchRoomClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Chat_WatchRoomClient interface {
+	Recv() (*ServerMessage, error)
+	grpc.ClientStream
+}
+
+type chatWatchRoomClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatWatchRoomClient) Recv() (*ServerMessage, error) {
+	m := new(ServerMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ChatServer is the server API for Chat service.
 // All implementations must embed UnimplementedChatServer
 // for forward compatibility
 type ChatServer interface {
 	RouteChat(Chat_RouteChatServer) error
+	// Search requires the chat-username metadata, identifying the caller as
+	// the Helo does.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// GetHistory requires the chat-username metadata too.
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// WatchRoom streams the messages, announcements, topic and mode changes
+	// of a public room, without joining it. It is read-only, and the watchers
+	// are not members of the room.
+	WatchRoom(*WatchRoomRequest, Chat_WatchRoomServer) error
 	mustEmbedUnimplementedChatServer()
 }
 
@@ -75,6 +147,15 @@ type UnimplementedChatServer struct {
 func (UnimplementedChatServer) RouteChat(Chat_RouteChatServer) error {
 	return status.Errorf(codes.Unimplemented, "method RouteChat not implemented")
 }
+func (UnimplementedChatServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedChatServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedChatServer) WatchRoom(*WatchRoomRequest, Chat_WatchRoomServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRoom not implemented")
+}
 func (UnimplementedChatServer) mustEmbedUnimplementedChatServer() {}
 
 // UnsafeChatServer may be embedded to opt out of forward compatibility for this service.
@@ -114,13 +195,79 @@ func (x *chatRouteChatServer) Recv() (*ClientMessage, error) {
 	return m, nil
 }
 
+func _Chat_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.Chat/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.Chat/GetHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Chat_WatchRoom_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRoomRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServer).WatchRoom(m, &chatWatchRoomServer{stream})
+}
+
+type Chat_WatchRoomServer interface {
+	Send(*ServerMessage) error
+	grpc.ServerStream
+}
+
+type chatWatchRoomServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatWatchRoomServer) Send(m *ServerMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Chat_ServiceDesc is the grpc.ServiceDesc for Chat service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var Chat_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "pbuf.Chat",
 	HandlerType: (*ChatServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _Chat_Search_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _Chat_GetHistory_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "RouteChat",
@@ -128,6 +275,11 @@ var Chat_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchRoom",
+			Handler:       _Chat_WatchRoom_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pbuf/chat.proto",
 }
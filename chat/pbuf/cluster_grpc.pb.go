@@ -0,0 +1,260 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.2
+// source: pbuf/cluster.proto
+
+package pbuf
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ChatClusterClient is the client API for ChatCluster service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ChatClusterClient interface {
+	// ProxyChat carries the RouteChat session of a participant connected to
+	// another node. The session lands in the room named by the
+	// chat-proxy-room metadata, joined with the chat-proxy-invite metadata,
+	// instead of the default room.
+	ProxyChat(ctx context.Context, opts ...grpc.CallOption) (ChatCluster_ProxyChatClient, error)
+	// MigrateRoom hands a room over to its new owner. A room with the same id
+	// on the owner is replaced.
+	MigrateRoom(ctx context.Context, in *MigrateRoomRequest, opts ...grpc.CallOption) (*MigrateRoomResponse, error)
+	// Leave tells the peers that a node is leaving the cluster.
+	Leave(ctx context.Context, in *LeaveRequest, opts ...grpc.CallOption) (*LeaveResponse, error)
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+}
+
+type chatClusterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatClusterClient(cc grpc.ClientConnInterface) ChatClusterClient {
+	return &chatClusterClient{cc}
+}
+
+func (c *chatClusterClient) ProxyChat(ctx context.Context, opts ...grpc.CallOption) (ChatCluster_ProxyChatClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChatCluster_ServiceDesc.Streams[0], "/pbuf.ChatCluster/ProxyChat", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatClusterProxyChatClient{stream}
+	return x, nil
+}
+
+type ChatCluster_ProxyChatClient interface {
+	Send(*ClientMessage) error
+	Recv() (*ServerMessage, error)
+	grpc.ClientStream
+}
+
+type chatClusterProxyChatClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatClusterProxyChatClient) Send(m *ClientMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatClusterProxyChatClient) Recv() (*ServerMessage, error) {
+	m := new(ServerMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatClusterClient) MigrateRoom(ctx context.Context, in *MigrateRoomRequest, opts ...grpc.CallOption) (*MigrateRoomResponse, error) {
+	out := new(MigrateRoomResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatCluster/MigrateRoom", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClusterClient) Leave(ctx context.Context, in *LeaveRequest, opts ...grpc.CallOption) (*LeaveResponse, error) {
+	out := new(LeaveResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatCluster/Leave", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatClusterClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatCluster/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChatClusterServer is the server API for ChatCluster service.
+// All implementations must embed UnimplementedChatClusterServer
+// for forward compatibility
+type ChatClusterServer interface {
+	// ProxyChat carries the RouteChat session of a participant connected to
+	// another node. The session lands in the room named by the
+	// chat-proxy-room metadata, joined with the chat-proxy-invite metadata,
+	// instead of the default room.
+	ProxyChat(ChatCluster_ProxyChatServer) error
+	// MigrateRoom hands a room over to its new owner. A room with the same id
+	// on the owner is replaced.
+	MigrateRoom(context.Context, *MigrateRoomRequest) (*MigrateRoomResponse, error)
+	// Leave tells the peers that a node is leaving the cluster.
+	Leave(context.Context, *LeaveRequest) (*LeaveResponse, error)
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	mustEmbedUnimplementedChatClusterServer()
+}
+
+// UnimplementedChatClusterServer must be embedded to have forward compatible implementations.
+type UnimplementedChatClusterServer struct {
+}
+
+func (UnimplementedChatClusterServer) ProxyChat(ChatCluster_ProxyChatServer) error {
+	return status.Errorf(codes.Unimplemented, "method ProxyChat not implemented")
+}
+func (UnimplementedChatClusterServer) MigrateRoom(context.Context, *MigrateRoomRequest) (*MigrateRoomResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateRoom not implemented")
+}
+func (UnimplementedChatClusterServer) Leave(context.Context, *LeaveRequest) (*LeaveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Leave not implemented")
+}
+func (UnimplementedChatClusterServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedChatClusterServer) mustEmbedUnimplementedChatClusterServer() {}
+
+// UnsafeChatClusterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChatClusterServer will
+// result in compilation errors.
+type UnsafeChatClusterServer interface {
+	mustEmbedUnimplementedChatClusterServer()
+}
+
+func RegisterChatClusterServer(s grpc.ServiceRegistrar, srv ChatClusterServer) {
+	s.RegisterService(&ChatCluster_ServiceDesc, srv)
+}
+
+func _ChatCluster_ProxyChat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatClusterServer).ProxyChat(&chatClusterProxyChatServer{stream})
+}
+
+type ChatCluster_ProxyChatServer interface {
+	Send(*ServerMessage) error
+	Recv() (*ClientMessage, error)
+	grpc.ServerStream
+}
+
+type chatClusterProxyChatServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatClusterProxyChatServer) Send(m *ServerMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatClusterProxyChatServer) Recv() (*ClientMessage, error) {
+	m := new(ClientMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ChatCluster_MigrateRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MigrateRoomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatClusterServer).MigrateRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatCluster/MigrateRoom",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatClusterServer).MigrateRoom(ctx, req.(*MigrateRoomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatCluster_Leave_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatClusterServer).Leave(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatCluster/Leave",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatClusterServer).Leave(ctx, req.(*LeaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatCluster_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatClusterServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatCluster/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatClusterServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChatCluster_ServiceDesc is the grpc.ServiceDesc for ChatCluster service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChatCluster_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pbuf.ChatCluster",
+	HandlerType: (*ChatClusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MigrateRoom",
+			Handler:    _ChatCluster_MigrateRoom_Handler,
+		},
+		{
+			MethodName: "Leave",
+			Handler:    _ChatCluster_Leave_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _ChatCluster_Ping_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ProxyChat",
+			Handler:       _ChatCluster_ProxyChat_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pbuf/cluster.proto",
+}
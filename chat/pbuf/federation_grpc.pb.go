@@ -0,0 +1,109 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.2
+// source: pbuf/federation.proto
+
+package pbuf
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ChatFederationClient is the client API for ChatFederation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ChatFederationClient interface {
+	// Relay writes a message of a bridged room in the room of the server
+	// called.
+	Relay(ctx context.Context, in *RelayRequest, opts ...grpc.CallOption) (*RelayResponse, error)
+}
+
+type chatFederationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChatFederationClient(cc grpc.ClientConnInterface) ChatFederationClient {
+	return &chatFederationClient{cc}
+}
+
+func (c *chatFederationClient) Relay(ctx context.Context, in *RelayRequest, opts ...grpc.CallOption) (*RelayResponse, error) {
+	out := new(RelayResponse)
+	err := c.cc.Invoke(ctx, "/pbuf.ChatFederation/Relay", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChatFederationServer is the server API for ChatFederation service.
+// All implementations must embed UnimplementedChatFederationServer
+// for forward compatibility
+type ChatFederationServer interface {
+	// Relay writes a message of a bridged room in the room of the server
+	// called.
+	Relay(context.Context, *RelayRequest) (*RelayResponse, error)
+	mustEmbedUnimplementedChatFederationServer()
+}
+
+// UnimplementedChatFederationServer must be embedded to have forward compatible implementations.
+type UnimplementedChatFederationServer struct {
+}
+
+func (UnimplementedChatFederationServer) Relay(context.Context, *RelayRequest) (*RelayResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Relay not implemented")
+}
+func (UnimplementedChatFederationServer) mustEmbedUnimplementedChatFederationServer() {}
+
+// UnsafeChatFederationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChatFederationServer will
+// result in compilation errors.
+type UnsafeChatFederationServer interface {
+	mustEmbedUnimplementedChatFederationServer()
+}
+
+func RegisterChatFederationServer(s grpc.ServiceRegistrar, srv ChatFederationServer) {
+	s.RegisterService(&ChatFederation_ServiceDesc, srv)
+}
+
+func _ChatFederation_Relay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatFederationServer).Relay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pbuf.ChatFederation/Relay",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatFederationServer).Relay(ctx, req.(*RelayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChatFederation_ServiceDesc is the grpc.ServiceDesc for ChatFederation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChatFederation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pbuf.ChatFederation",
+	HandlerType: (*ChatFederationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Relay",
+			Handler:    _ChatFederation_Relay_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pbuf/federation.proto",
+}
@@ -0,0 +1,193 @@
+// Package search implements an in-memory inverted index over the messages of
+// the rooms.
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// Query selects the messages of a search. The zero values disable the
+// corresponding filters.
+type Query struct {
+	// Terms must all appear in the messages, case-insensitively.
+	Terms  string
+	Author string
+	// RoomIDs restricts the search to the given rooms. A nil map matches no
+	// room.
+	RoomIDs map[string]bool
+	Since   time.Time
+	Until   time.Time
+	// Before only matches the messages with a lower ID, paginating the
+	// results.
+	Before store.MessageID
+	Limit  int
+}
+
+// Index is an inverted index of messages, safe for concurrent use.
+type Index struct {
+	mu sync.RWMutex
+	// ids lists the indexed messages, in increasing order.
+	ids      []store.MessageID
+	messages map[store.MessageID]store.Message
+	// postings maps the terms to the messages containing them, in
+	// increasing order.
+	postings map[string][]store.MessageID
+}
+
+func (ix *Index) Add(m store.Message) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.ids = insert(ix.ids, m.ID)
+	ix.messages[m.ID] = m
+	for _, term := range uniqueTerms(m.Body) {
+		ix.postings[term] = insert(ix.postings[term], m.ID)
+	}
+}
+
+// Remove drops messages from the index.
+func (ix *Index) Remove(messages []store.Message) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	removed := make(map[store.MessageID]bool, len(messages))
+	terms := make(map[string]bool)
+	for _, m := range messages {
+		removed[m.ID] = true
+		delete(ix.messages, m.ID)
+		for _, term := range uniqueTerms(m.Body) {
+			terms[term] = true
+		}
+	}
+	ix.ids = without(ix.ids, removed)
+	for term := range terms {
+		if ids := without(ix.postings[term], removed); len(ids) > 0 {
+			ix.postings[term] = ids
+		} else {
+			delete(ix.postings, term)
+		}
+	}
+}
+
+// Search returns the messages matching q, newest first, and whether more
+// messages match beyond q.Limit.
+func (ix *Index) Search(q Query) ([]store.Message, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	candidates := ix.ids
+	for _, term := range uniqueTerms(q.Terms) {
+		candidates = intersect(candidates, ix.postings[term])
+	}
+
+	var results []store.Message
+	end := len(candidates)
+	if q.Before > 0 {
+		end = sort.Search(len(candidates), func(i int) bool {
+			return candidates[i] >= q.Before
+		})
+	}
+	for i := end - 1; i >= 0; i-- {
+		m := ix.messages[candidates[i]]
+		if !q.matches(m) {
+			continue
+		}
+		if q.Limit > 0 && len(results) == q.Limit {
+			return results, true
+		}
+		results = append(results, m)
+	}
+
+	return results, false
+}
+
+func (q Query) matches(m store.Message) bool {
+	switch {
+	case !q.RoomIDs[m.RoomID]:
+		return false
+	case q.Author != "" && m.Author != q.Author:
+		return false
+	case !q.Since.IsZero() && m.SentAt.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !m.SentAt.Before(q.Until):
+		return false
+	default:
+		return true
+	}
+}
+
+// uniqueTerms splits s into lowercase terms of letters and digits, once
+// each.
+func uniqueTerms(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(fields))
+	terms := fields[:0]
+	for _, f := range fields {
+		if !seen[f] {
+			seen[f] = true
+			terms = append(terms, f)
+		}
+	}
+
+	return terms
+}
+
+// insert adds id to the sorted ids. The rooms add their messages
+// concurrently, so that they are mostly but not always in order.
+func insert(ids []store.MessageID, id store.MessageID) []store.MessageID {
+	i := len(ids)
+	for i > 0 && ids[i-1] > id {
+		i--
+	}
+	ids = append(ids, 0)
+	copy(ids[i+1:], ids[i:])
+	ids[i] = id
+
+	return ids
+}
+
+// without filters the removed IDs out of ids, in place.
+func without(ids []store.MessageID, removed map[store.MessageID]bool) []store.MessageID {
+	kept := ids[:0]
+	for _, id := range ids {
+		if !removed[id] {
+			kept = append(kept, id)
+		}
+	}
+
+	return kept
+}
+
+// intersect returns the IDs in both a and b, which are sorted.
+func intersect(a, b []store.MessageID) []store.MessageID {
+	var ids []store.MessageID
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			ids = append(ids, a[i])
+			i++
+			j++
+		}
+	}
+
+	return ids
+}
+
+func NewIndex() *Index {
+	return &Index{
+		messages: make(map[store.MessageID]store.Message),
+		postings: make(map[string][]store.MessageID),
+	}
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestIndexSearch(t *testing.T) {
+	start := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	messages := []store.Message{
+		{ID: 1, RoomID: "dev", Author: "alice", Body: "run make test_race please", SentAt: start},
+		{ID: 2, RoomID: "dev", Author: "bob", Body: "Make sure to RUN it twice", SentAt: start.Add(time.Hour)},
+		{ID: 4, RoomID: "ops", Author: "alice", Body: "run the deploy", SentAt: start.Add(2 * time.Hour)},
+		// Added out of order, as concurrent rooms can.
+		{ID: 3, RoomID: "dev", Author: "alice", Body: "lunch?", SentAt: start.Add(90 * time.Minute)},
+	}
+	removed := []store.Message{
+		{ID: 5, RoomID: "ops", Author: "bob", Body: "the deploy token is hunter2", SentAt: start.Add(3 * time.Hour)},
+	}
+	both := map[string]bool{"dev": true, "ops": true}
+
+	testsTable := []struct {
+		Name     string
+		Query    Query
+		WantIDs  []store.MessageID
+		WantMore bool
+	}{
+		{
+			Name:    "all terms case-insensitively",
+			Query:   Query{Terms: "RUN make", RoomIDs: both},
+			WantIDs: []store.MessageID{2, 1},
+		},
+		{
+			Name:    "allowed rooms only",
+			Query:   Query{Terms: "run", RoomIDs: map[string]bool{"dev": true}},
+			WantIDs: []store.MessageID{2, 1},
+		},
+		{
+			Name:    "author and time range",
+			Query:   Query{Author: "alice", RoomIDs: both, Since: start.Add(time.Minute), Until: start.Add(2 * time.Hour)},
+			WantIDs: []store.MessageID{3},
+		},
+		{
+			Name:     "first page",
+			Query:    Query{RoomIDs: both, Limit: 2},
+			WantIDs:  []store.MessageID{4, 3},
+			WantMore: true,
+		},
+		{
+			Name:    "last page",
+			Query:   Query{RoomIDs: both, Limit: 2, Before: 3},
+			WantIDs: []store.MessageID{2, 1},
+		},
+		{
+			Name:    "removed",
+			Query:   Query{Terms: "deploy", RoomIDs: both},
+			WantIDs: []store.MessageID{4},
+		},
+		{
+			Name:  "unknown term",
+			Query: Query{Terms: "pasted", RoomIDs: both},
+		},
+	}
+
+	ix := NewIndex()
+	for _, m := range append(messages, removed...) {
+		ix.Add(m)
+	}
+	ix.Remove(removed)
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			results, more := ix.Search(tt.Query)
+			var ids []store.MessageID
+			for _, m := range results {
+				ids = append(ids, m.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.WantIDs) || more != tt.WantMore {
+				t.Errorf("got %v (more: %t), want %v (more: %t)", ids, more, tt.WantIDs, tt.WantMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+// AdminServer exposes the ChatAdmin service of a Server on its own listener,
+// so that it can be kept private to the operators.
+type AdminServer struct {
+	pb.UnimplementedChatAdminServer
+
+	listener   net.Listener
+	gRPCServer *grpc.Server
+
+	s *Server
+}
+
+func (a *AdminServer) Serve() error {
+	return a.gRPCServer.Serve(a.listener)
+}
+
+func (a *AdminServer) Shutdown(ctx context.Context) error {
+	return shutdownGRPC(ctx, a.s.log, a.gRPCServer, a.listener, func() {})
+}
+
+func (a *AdminServer) ListRooms(ctx context.Context, req *pb.ListRoomsRequest) (*pb.ListRoomsResponse, error) {
+	rooms := a.s.rm.ListRooms()
+	resp := &pb.ListRoomsResponse{
+		Rooms: make([]*pb.RoomInfo, len(rooms)),
+	}
+	for i, r := range rooms {
+		resp.Rooms[i] = r.Info()
+	}
+
+	return resp, nil
+}
+
+func (a *AdminServer) CreateRoom(ctx context.Context, req *pb.CreateRoomRequest) (*pb.CreateRoomResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	opts, err := a.s.placeRoom(req.Name)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	opts = append(
+		opts,
+		internal.WithVisibility(req.Visibility),
+		internal.WithCapacity(int(req.Capacity), req.QueueWhenFull),
+		internal.WithTopic(req.Topic),
+		internal.WithDescription(req.Description),
+		internal.WithLabels(req.Labels),
+		internal.WithCreator(req.Owner),
+		internal.WithLifecycle(req.Lifecycle, req.EmptyTtl.AsDuration()),
+		internal.WithRetention(internal.RetentionFromProto(req.Retention)),
+	)
+	if req.Owner != "" {
+		// The owner is announced to the replicas with the room.
+		opts = append(opts, internal.WithOwner(req.Owner))
+	}
+	rID, err := a.s.rm.CreateRoom(req.Name, opts...)
+	if errors.Is(err, internal.ErrRoomExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if errors.Is(err, internal.ErrInvalidArgument) {
+		return nil, toStatus(err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "room creation failed: %v", err)
+	}
+	r, ok := a.s.rm.GetRoom(rID)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "room %s vanished after creation", rID)
+	}
+
+	return &pb.CreateRoomResponse{Room: r.Info()}, nil
+}
+
+func (a *AdminServer) CloseRoom(ctx context.Context, req *pb.CloseRoomRequest) (*pb.CloseRoomResponse, error) {
+	if internal.RoomID(req.RoomId) == a.s.defaultRoom {
+		return nil, status.Error(codes.FailedPrecondition, "the default room cannot be closed")
+	}
+	if err := a.s.rm.CloseRoom(internal.RoomID(req.RoomId)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.CloseRoomResponse{}, nil
+}
+
+func (a *AdminServer) ListArchivedRooms(ctx context.Context, req *pb.ListArchivedRoomsRequest) (*pb.ListArchivedRoomsResponse, error) {
+	rooms, err := a.s.store.ArchivedRooms()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "listing the archived rooms failed: %v", err)
+	}
+	resp := &pb.ListArchivedRoomsResponse{
+		Rooms: make([]*pb.ArchivedRoomInfo, len(rooms)),
+	}
+	for i, r := range rooms {
+		resp.Rooms[i] = &pb.ArchivedRoomInfo{
+			Id:          r.ID,
+			Name:        r.Name,
+			Topic:       r.Topic,
+			Description: r.Description,
+			Creator:     r.Creator,
+			Labels:      r.Labels,
+			CreatedAt:   timestamppb.New(r.CreatedAt),
+			ArchivedAt:  timestamppb.New(r.ArchivedAt),
+		}
+	}
+
+	return resp, nil
+}
+
+func (a *AdminServer) SetRoomMode(ctx context.Context, req *pb.SetRoomModeRequest) (*pb.SetRoomModeResponse, error) {
+	r, ok := a.s.rm.GetRoom(internal.RoomID(req.RoomId))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", internal.ErrRoomNotFound, req.RoomId)
+	}
+	if err := r.SetMode(nil, req.Mode, req.SlowModeInterval.AsDuration()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.SetRoomModeResponse{Room: r.Info()}, nil
+}
+
+func (a *AdminServer) UpdateRoomMetadata(ctx context.Context, req *pb.UpdateRoomMetadataRequest) (*pb.UpdateRoomMetadataResponse, error) {
+	r, ok := a.s.rm.GetRoom(internal.RoomID(req.RoomId))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", internal.ErrRoomNotFound, req.RoomId)
+	}
+	if req.Topic != nil {
+		if err := r.SetTopic(nil, *req.Topic); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	if req.Description != nil {
+		if err := r.SetDescription(nil, *req.Description); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+	for k, v := range req.Labels {
+		if err := r.SetLabel(nil, k, v); err != nil {
+			return nil, toStatus(err)
+		}
+	}
+
+	return &pb.UpdateRoomMetadataResponse{Room: r.Info()}, nil
+}
+
+func (a *AdminServer) SetRetention(ctx context.Context, req *pb.SetRetentionRequest) (*pb.SetRetentionResponse, error) {
+	r, ok := a.s.rm.GetRoom(internal.RoomID(req.RoomId))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", internal.ErrRoomNotFound, req.RoomId)
+	}
+	if err := r.SetRetention(internal.RetentionFromProto(req.Retention)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.SetRetentionResponse{Room: r.Info()}, nil
+}
+
+func (a *AdminServer) PurgeHistory(ctx context.Context, req *pb.PurgeHistoryRequest) (*pb.PurgeHistoryResponse, error) {
+	var purged int
+	var err error
+	switch target := req.Target.(type) {
+	case *pb.PurgeHistoryRequest_RoomId:
+		if target.RoomId == "" {
+			return nil, status.Error(codes.InvalidArgument, "room_id is required")
+		}
+		purged, err = a.s.rm.PurgeRoom(target.RoomId)
+	case *pb.PurgeHistoryRequest_Author:
+		purged, err = a.s.rm.PurgeAuthor(target.Author)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "a room_id or an author is required")
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	a.s.log.Infof("Purged %d messages from the history", purged)
+
+	return &pb.PurgeHistoryResponse{Purged: uint32(purged)}, nil
+}
+
+func (a *AdminServer) SetRole(ctx context.Context, req *pb.SetRoleRequest) (*pb.SetRoleResponse, error) {
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+	r, ok := a.s.rm.GetRoom(internal.RoomID(req.RoomId))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", internal.ErrRoomNotFound, req.RoomId)
+	}
+	if err := r.SetRole(nil, req.Username, req.Role); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.SetRoleResponse{}, nil
+}
+
+func (a *AdminServer) SetMuted(ctx context.Context, req *pb.SetMutedRequest) (*pb.SetMutedResponse, error) {
+	if req.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+	r, ok := a.s.rm.GetRoom(internal.RoomID(req.RoomId))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", internal.ErrRoomNotFound, req.RoomId)
+	}
+	if err := r.Mute(nil, req.Username, req.Muted); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.SetMutedResponse{}, nil
+}
+
+func (a *AdminServer) ListParticipants(ctx context.Context, req *pb.ListParticipantsRequest) (*pb.ListParticipantsResponse, error) {
+	resp := &pb.ListParticipantsResponse{}
+	for _, r := range a.s.rm.ListRooms() {
+		if req.RoomId != "" && r.ID() != internal.RoomID(req.RoomId) {
+			continue
+		}
+		for _, p := range r.Participants() {
+			resp.Participants = append(resp.Participants, p.Info())
+		}
+	}
+
+	return resp, nil
+}
+
+func (a *AdminServer) DisconnectParticipant(ctx context.Context, req *pb.DisconnectParticipantRequest) (*pb.DisconnectParticipantResponse, error) {
+	reason := req.Reason
+	if reason == "" {
+		reason = "disconnected by an administrator"
+	}
+
+	ids := []string{req.ParticipantId}
+	if req.ParticipantId == "" {
+		if req.Username == "" {
+			return nil, status.Error(codes.InvalidArgument, "either participant_id or username is required")
+		}
+		ids = nil
+		for _, r := range a.s.rm.ListRooms() {
+			for _, p := range r.Participants() {
+				if p.Username() == req.Username {
+					ids = append(ids, p.String())
+				}
+			}
+		}
+		if len(ids) == 0 {
+			return nil, status.Errorf(codes.NotFound, "no participant named %s", req.Username)
+		}
+	}
+
+	resp := &pb.DisconnectParticipantResponse{}
+	for _, id := range ids {
+		p, err := a.s.rm.DisconnectParticipant(id, reason)
+		if err != nil {
+			return resp, toStatus(err)
+		}
+		resp.Disconnected = append(resp.Disconnected, p.Info())
+	}
+
+	return resp, nil
+}
+
+func (a *AdminServer) Announce(ctx context.Context, req *pb.AnnounceRequest) (*pb.AnnounceResponse, error) {
+	if req.Body == "" {
+		return nil, status.Error(codes.InvalidArgument, "body is required")
+	}
+
+	resp := &pb.AnnounceResponse{}
+	for _, r := range a.s.rm.ListRooms() {
+		if req.RoomId != "" && r.ID() != internal.RoomID(req.RoomId) {
+			continue
+		}
+		if err := r.Announce(req.Body); err != nil {
+			return resp, status.Errorf(codes.Internal, "announce to room %s failed: %v", r.ID(), err)
+		}
+		resp.Rooms++
+	}
+	if req.RoomId != "" && resp.Rooms == 0 {
+		return nil, status.Errorf(codes.NotFound, "%v: %s", internal.ErrRoomNotFound, req.RoomId)
+	}
+
+	return resp, nil
+}
+
+func (a *AdminServer) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	stats := a.s.rm.Stats()
+
+	return &pb.GetStatsResponse{
+		StartedAt:    timestamppb.New(a.s.startedAt),
+		Rooms:        uint32(stats.Rooms),
+		Participants: uint32(stats.Participants),
+		Messages:     stats.Messages,
+		Watchers:     uint32(stats.Watchers),
+	}, nil
+}
+
+// NewAdminServer creates the admin server of s, listening on localhost:port.
+// When token is not empty, every call must carry it as a bearer token in the
+// authorization metadata.
+func NewAdminServer(port int, token string, s *Server) (*AdminServer, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	var opts []grpc.ServerOption
+	if token != "" {
+		opts = append(opts,
+			grpc.UnaryInterceptor(bearerTokenInterceptor(token, "invalid admin token")),
+			grpc.StreamInterceptor(bearerTokenStreamInterceptor(token, "invalid admin token")),
+		)
+	}
+
+	a := &AdminServer{
+		listener:   listener,
+		gRPCServer: grpc.NewServer(opts...),
+		s:          s,
+	}
+
+	pb.RegisterChatAdminServer(a.gRPCServer, a)
+
+	return a, nil
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, internal.ErrRoomNotFound), errors.Is(err, internal.ErrRoomClosed), errors.Is(err, internal.ErrParticipantNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, internal.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, internal.ErrPermissionDenied), errors.Is(err, internal.ErrBanned):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, internal.ErrRoomFull), errors.Is(err, internal.ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, errRemoteRoom):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
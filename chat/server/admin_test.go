@@ -0,0 +1,245 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+func TestAdminServer(t *testing.T) {
+	s, client := newTestClient(t)
+	a, err := NewAdminServer(0, "s3cret", s)
+	if err != nil {
+		t.Fatalf("NewAdminServer failed: %v", err)
+	}
+	go a.Serve()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := a.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown of the admin server failed: %v", err)
+		}
+	}()
+	conn, err := grpc.Dial(a.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	admin := pb.NewChatAdminClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat failed: %v", err)
+	}
+	sendCommand(t, stream, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: "alice"})
+	recvCommand(t, stream, pb.ServerMessage_ConfirmRoomCheckout)
+
+	defaultRoom := string(s.defaultRoom)
+	var newsID string
+	// The cases run in order, on the same server.
+	testsTable := []struct {
+		Name string
+		// Token is the bearer token of the call, the admin token when empty.
+		Token    string
+		Call     func(ctx context.Context) (proto.Message, error)
+		WantCode codes.Code
+		Check    func(t *testing.T, resp proto.Message)
+	}{
+		{
+			Name:  "wrong token",
+			Token: "guess",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.ListRooms(ctx, &pb.ListRoomsRequest{})
+			},
+			WantCode: codes.Unauthenticated,
+		},
+		{
+			Name: "create room",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.CreateRoom(ctx, &pb.CreateRoomRequest{Name: "news", Topic: "News", Capacity: 10})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				r := resp.(*pb.CreateRoomResponse).Room
+				if r.Name != "news" || r.Topic != "News" || r.Capacity != 10 {
+					t.Errorf("got room %v, want news", r)
+				}
+				newsID = r.Id
+			},
+		},
+		{
+			Name: "create existing room",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.CreateRoom(ctx, &pb.CreateRoomRequest{Name: "news"})
+			},
+			WantCode: codes.AlreadyExists,
+		},
+		{
+			Name: "create room without name",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.CreateRoom(ctx, &pb.CreateRoomRequest{Topic: "News"})
+			},
+			WantCode: codes.InvalidArgument,
+		},
+		{
+			Name: "list rooms",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.ListRooms(ctx, &pb.ListRoomsRequest{})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				participants := map[string]uint32{}
+				for _, r := range resp.(*pb.ListRoomsResponse).Rooms {
+					participants[r.Name] = r.Participants
+				}
+				if len(participants) != 2 || participants["default"] != 1 || participants["news"] != 0 {
+					t.Errorf("got rooms with participants %v, want default with alice and news", participants)
+				}
+			},
+		},
+		{
+			Name: "list participants",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.ListParticipants(ctx, &pb.ListParticipantsRequest{RoomId: defaultRoom})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				ps := resp.(*pb.ListParticipantsResponse).Participants
+				if len(ps) != 1 || ps[0].Username != "alice" || ps[0].RoomId != defaultRoom {
+					t.Errorf("got participants %v, want alice", ps)
+				}
+			},
+		},
+		{
+			Name: "announce",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.Announce(ctx, &pb.AnnounceRequest{Body: "maintenance at noon"})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				if n := resp.(*pb.AnnounceResponse).Rooms; n != 2 {
+					t.Errorf("announced to %d rooms, want 2", n)
+				}
+				m := recvCommand(t, stream, pb.ServerMessage_Announcement)
+				var announcement pb.ServerMessage_ServerAnnouncement
+				if err := pbutils.UnmarshalAny(m.Operation, &announcement); err != nil {
+					t.Fatalf("UnmarshalAny failed: %v", err)
+				}
+				if announcement.Body != "maintenance at noon" {
+					t.Errorf("got announcement %q", announcement.Body)
+				}
+			},
+		},
+		{
+			Name: "announce without body",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.Announce(ctx, &pb.AnnounceRequest{RoomId: defaultRoom})
+			},
+			WantCode: codes.InvalidArgument,
+		},
+		{
+			Name: "announce to unknown room",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.Announce(ctx, &pb.AnnounceRequest{RoomId: "unknown", Body: "hi"})
+			},
+			WantCode: codes.NotFound,
+		},
+		{
+			Name: "stats",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.GetStats(ctx, &pb.GetStatsRequest{})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				stats := resp.(*pb.GetStatsResponse)
+				if stats.Rooms != 2 || stats.Participants != 1 || stats.StartedAt.AsTime().IsZero() {
+					t.Errorf("got stats %v, want 2 rooms and 1 participant", stats)
+				}
+			},
+		},
+		{
+			Name: "close default room",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.CloseRoom(ctx, &pb.CloseRoomRequest{RoomId: defaultRoom})
+			},
+			WantCode: codes.FailedPrecondition,
+		},
+		{
+			Name: "close unknown room",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.CloseRoom(ctx, &pb.CloseRoomRequest{RoomId: "unknown"})
+			},
+			WantCode: codes.NotFound,
+		},
+		{
+			Name: "close room",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.CloseRoom(ctx, &pb.CloseRoomRequest{RoomId: newsID})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				if _, ok := s.rm.GetRoom(internal.RoomID(newsID)); ok {
+					t.Error("room news still open")
+				}
+			},
+		},
+		{
+			Name: "kick without target",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.DisconnectParticipant(ctx, &pb.DisconnectParticipantRequest{Reason: "spam"})
+			},
+			WantCode: codes.InvalidArgument,
+		},
+		{
+			Name: "kick unknown user",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.DisconnectParticipant(ctx, &pb.DisconnectParticipantRequest{Username: "bob"})
+			},
+			WantCode: codes.NotFound,
+		},
+		{
+			Name: "kick",
+			Call: func(ctx context.Context) (proto.Message, error) {
+				return admin.DisconnectParticipant(ctx, &pb.DisconnectParticipantRequest{Username: "alice", Reason: "spam"})
+			},
+			Check: func(t *testing.T, resp proto.Message) {
+				ps := resp.(*pb.DisconnectParticipantResponse).Disconnected
+				if len(ps) != 1 || ps[0].Username != "alice" {
+					t.Errorf("got disconnected %v, want alice", ps)
+				}
+				m := recvCommand(t, stream, pb.ServerMessage_Shutdown)
+				var shutdown pb.ServerMessage_ServerShutdown
+				if err := pbutils.UnmarshalAny(m.Operation, &shutdown); err != nil {
+					t.Fatalf("UnmarshalAny failed: %v", err)
+				}
+				if shutdown.Reason != "spam" {
+					t.Errorf("got reason %q, want spam", shutdown.Reason)
+				}
+			},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			token := tt.Token
+			if token == "" {
+				token = "s3cret"
+			}
+			ctx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+			resp, err := tt.Call(ctx)
+			if got := status.Code(err); got != tt.WantCode {
+				t.Fatalf("got code %s (%v), want %s", got, err, tt.WantCode)
+			}
+			if tt.Check != nil {
+				tt.Check(t, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator verifies the usernames claimed by the clients of the Chat
+// service: the author of the Helo of a RouteChat session, and the username
+// in the UsernameMetadataKey of the unary RPCs.
+type Authenticator interface {
+	// Authenticate returns an error when the client calling with ctx is not
+	// allowed to use username.
+	Authenticate(ctx context.Context, username string) error
+}
+
+// AuthenticatorFunc is a function used as an Authenticator.
+type AuthenticatorFunc func(ctx context.Context, username string) error
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, username string) error {
+	return f(ctx, username)
+}
+
+// authenticate rejects the banned users, and verifies username with the
+// Authenticator of the server, accepting every username without one.
+func (s *Server) authenticate(ctx context.Context, username string) error {
+	if err := s.checkBan(ctx, username); err != nil {
+		return err
+	}
+	if s.authenticator == nil {
+		return nil
+	}
+
+	return s.authenticator.Authenticate(ctx, username)
+}
+
+// hasBearerToken reports whether the metadata of ctx carry token as a bearer
+// token.
+func hasBearerToken(ctx context.Context, token string) bool {
+	want := []byte("Bearer " + token)
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, got := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(got), want) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bearerTokenInterceptor rejects the unary calls not carrying token as a
+// bearer token with msg.
+func bearerTokenInterceptor(token, msg string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !hasBearerToken(ctx, token) {
+			return nil, status.Error(codes.Unauthenticated, msg)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// bearerTokenStreamInterceptor rejects the streaming calls not carrying token
+// as a bearer token with msg.
+func bearerTokenStreamInterceptor(token, msg string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !hasBearerToken(ss.Context(), token) {
+			return status.Error(codes.Unauthenticated, msg)
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// bearerToken sends a token as a bearer token with every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity is false since the cluster service is served in
+// plaintext.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// NewTokenAuthenticator authenticates the users with static tokens, sent as
+// bearer tokens in the authorization metadata. tokens maps the usernames to
+// their token, the other usernames are rejected.
+func NewTokenAuthenticator(tokens map[string]string) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, username string) error {
+		want, ok := tokens[username]
+		if !ok {
+			return errors.New("unknown user")
+		}
+		if !hasBearerToken(ctx, want) {
+			return errors.New("invalid token")
+		}
+
+		return nil
+	})
+}
+
+// SetBannedUsers bans usernames from the server, replacing the users banned
+// before. The participants of the banned users are disconnected.
+func (s *Server) SetBannedUsers(usernames []string) {
+	banned := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		banned[username] = true
+	}
+	s.bannedMu.Lock()
+	s.banned = banned
+	s.bannedMu.Unlock()
+
+	for username := range banned {
+		if ps := s.rm.DisconnectUser(username, "banned from the server"); len(ps) > 0 {
+			s.log.Infof("Disconnected %d participants of the banned user %s", len(ps), username)
+		}
+	}
+}
+
+// checkBan returns an error when username is banned from the server.
+func (s *Server) checkBan(ctx context.Context, username string) error {
+	if s.isBanned(username) {
+		return errors.New("banned from the server")
+	}
+
+	return nil
+}
+
+func (s *Server) isBanned(username string) bool {
+	s.bannedMu.Lock()
+	defer s.bannedMu.Unlock()
+
+	return s.banned[username]
+}
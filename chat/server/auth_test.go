@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestGatewayToken(t *testing.T) {
+	testsTable := []struct {
+		Name      string
+		Transport string
+		// Header sends the token in the Authorization header instead of the
+		// query of the WebSocket URL.
+		Header     bool
+		Token      string
+		WantJoined bool
+	}{
+		{
+			Name:       "WebSocket query",
+			Transport:  "ws",
+			Token:      "s3cret",
+			WantJoined: true,
+		},
+		{
+			Name:       "WebSocket header",
+			Transport:  "ws",
+			Header:     true,
+			Token:      "s3cret",
+			WantJoined: true,
+		},
+		{
+			Name:      "WebSocket wrong token",
+			Transport: "ws",
+			Token:     "guess",
+		},
+		{
+			Name:       "IRC",
+			Transport:  "irc",
+			Token:      "s3cret",
+			WantJoined: true,
+		},
+		{
+			Name:      "IRC without password",
+			Transport: "irc",
+		},
+	}
+
+	s, _ := newTestClient(t, WithAuthenticator(NewTokenAuthenticator(map[string]string{"alice": "s3cret"})))
+	h, err := NewHTTPServer(0, s)
+	if err != nil {
+		t.Fatalf("NewHTTPServer failed: %v", err)
+	}
+	i, err := NewIRCServer(0, s)
+	if err != nil {
+		t.Fatalf("NewIRCServer failed: %v", err)
+	}
+	go h.Serve()
+	go i.Serve()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := h.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown of the HTTP server failed: %v", err)
+		}
+		if err := i.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown of the IRC server failed: %v", err)
+		}
+	}()
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			var joined bool
+			switch tt.Transport {
+			case "ws":
+				joined = joinWebSocket(t, h.listener.Addr().String(), tt.Token, tt.Header)
+			case "irc":
+				joined = joinIRC(t, i.listener.Addr().String(), tt.Token)
+			}
+			if joined != tt.WantJoined {
+				t.Errorf("joined %t, want %t", joined, tt.WantJoined)
+			}
+		})
+	}
+}
+
+// joinWebSocket logs alice in over a WebSocket, and reports whether she
+// joined a room.
+func joinWebSocket(t *testing.T, addr, token string, header bool) bool {
+	t.Helper()
+	url := fmt.Sprintf("ws://%s/ws", addr)
+	reqHeader := http.Header{}
+	if header {
+		reqHeader.Set("Authorization", "Bearer "+token)
+	} else {
+		url += "?token=" + token
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url, reqHeader)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	op, err := pbutils.MarshalAny(&pb.ClientMessage_ClientHelo{Author: "alice"})
+	if err != nil {
+		t.Fatalf("Marshal from helo failed: %v", err)
+	}
+	helo, err := protojson.Marshal(&pb.ClientMessage{Command: pb.ClientMessage_Helo, Operation: op})
+	if err != nil {
+		t.Fatalf("Marshal from client message failed: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, helo); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return false
+		}
+		var m pb.ServerMessage
+		if err := protojson.Unmarshal(data, &m); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if m.Command == pb.ServerMessage_ConfirmRoomCheckout {
+			return true
+		}
+	}
+}
+
+// joinIRC registers alice over IRC with the password token, when not empty,
+// and reports whether she joined a channel.
+func joinIRC(t *testing.T, addr, token string) bool {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if token != "" {
+		fmt.Fprintf(conn, "PASS %s\r\n", token)
+	}
+	fmt.Fprint(conn, "NICK alice\r\nUSER alice 0 * :Alice\r\n")
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.Contains(line, " JOIN "):
+			return true
+		case strings.HasPrefix(line, "ERROR"):
+			return false
+		}
+	}
+
+	return false
+}
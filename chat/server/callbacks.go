@@ -1,79 +1,72 @@
 package server
 
 import (
-	"context"
 	"errors"
+	"fmt"
 	"io"
-	"sync"
 
 	pbutils "github.com/golang/protobuf/ptypes"
 	"github.com/looplab/fsm"
-	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
 	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
 )
 
-func heloHandler(
-	ctx context.Context,
-	wg *sync.WaitGroup,
-	stream pb.Chat_RouteChatServer,
-	s *Server,
-	p *internal.Participant,
-	closeC chan<- closeCMD,
-) fsm.Callback {
+func heloHandler(ss *session) fsm.Callback {
 	return func(e *fsm.Event) {
 		var err error
 		var cMsgP *pb.ClientMessage
 		cMsgP, err = extractClientMsg(e)
 		if err != nil {
-			log.Errorf("Cannot extract client msg: %v", err)
+			ss.s.log.Errorf("Cannot extract client msg: %v", err)
+			ss.fail(status.Error(codes.Internal, "cannot read the Helo"))
 
 			return
 		}
 		var heloMsg pb.ClientMessage_ClientHelo
 		if err := pbutils.UnmarshalAny(cMsgP.Operation, &heloMsg); err != nil {
-			log.Errorf("Cannot unmarshal to helo: %v", err)
-			// TODO no helo no party
-			return
-		}
+			ss.s.log.Debugf("Cannot unmarshal to helo: %v", err)
+			ss.fail(status.Errorf(codes.InvalidArgument, "malformed Helo: %v", err))
 
-		p, err = internal.NewParticipant(heloMsg.Author)
-		if err != nil {
-			log.Errorf("Participant creation failed: %v", err)
-			// TODO helo failed
 			return
 		}
-		room, ok := s.rm.GetRoom(s.defaultRoom)
-		if !ok {
-			log.Errorf("Unable to get room %s", s.defaultRoom)
+		// The proxied sessions were authenticated by their gateway, a node
+		// of the cluster, but the owner of the room enforces its bans too.
+		authenticate := ss.s.authenticate
+		if ss.landing != "" {
+			authenticate = ss.s.checkBan
 		}
-		if err := room.AddParticipant(p); err != nil {
-			log.Errorf("Room checkout failed: %v", err)
-			// TODO participant registration failed
+		if err := authenticate(ss.ctx, heloMsg.Author); err != nil {
+			ss.fail(status.Errorf(codes.Unauthenticated, "%s: %v", heloMsg.Author, err))
+
 			return
 		}
 
-		confirmRoomMsg := pb.ServerMessage_ServerConfirmRoomCheckout{}
-		op, err := pbutils.MarshalAny(&confirmRoomMsg)
+		p, err := internal.NewParticipant(heloMsg.Author, ss.s.rm.Limits())
 		if err != nil {
-			log.Errorf("Room checkout confirmation failed: %v", err)
-			// TODO room checkout confirmation failed
+			ss.s.log.Errorf("Participant creation failed: %v", err)
+			ss.fail(status.Errorf(codes.InvalidArgument, "%s: %v", heloMsg.Author, err))
+
 			return
 		}
-		sMsg := pb.ServerMessage{
-			Command:   pb.ServerMessage_ConfirmRoomCheckout,
-			Operation: op,
+		if !ss.start(p) {
+			p.Close()
+
+			return
+		}
+		if err := ss.s.rm.Connect(p); err != nil {
+			ss.s.log.Errorf("Connecting %s failed: %v", p, err)
 		}
 
-		wg.Add(1)
 		go func() {
-			defer wg.Done()
+			defer ss.wg.Done()
 
 			sendFunc := func(msg *pb.ServerMessage) error {
-				if err := stream.Send(msg); err != nil {
-					if errors.As(err, &io.EOF) {
-						closeC <- closeCMD{}
+				if err := ss.stream.Send(msg); err != nil {
+					if errors.Is(err, io.EOF) {
+						ss.close(closeCMD{})
 
 						return nil
 					}
@@ -86,14 +79,16 @@ func heloHandler(
 
 			for {
 				select {
-				case <-ctx.Done():
+				case <-ss.ctx.Done():
 					return
-				case <-p.DisconnectChan:
-					log.Debugf("Got DisconnectChan")
-					shutdownMsg := pb.ServerMessage_ServerShutdown{}
+				case reason := <-p.DisconnectChan:
+					ss.s.log.Debugf("Got DisconnectChan")
+					shutdownMsg := pb.ServerMessage_ServerShutdown{
+						Reason: reason,
+					}
 					op, err := pbutils.MarshalAny(&shutdownMsg)
 					if err != nil {
-						log.Errorf("Marshal from shutdownMsg failed: %v", err)
+						ss.s.log.Errorf("Marshal from shutdownMsg failed: %v", err)
 						// TODO shutdown marshalling failed
 						return
 					}
@@ -102,54 +97,132 @@ func heloHandler(
 						Operation: op,
 					}
 					if err := sendFunc(&sMsg); err != nil {
-						log.Errorf("Send Shutdown to %s failed: %v", p, err)
+						ss.s.log.Errorf("Send Shutdown to %s failed: %v", p, err)
 					}
-					closeC <- closeCMD{delay: true}
-					log.Debugf("Done DisconnectChan")
+					ss.close(closeCMD{delay: true})
+					ss.s.log.Debugf("Done DisconnectChan")
 				case sMsgP := <-p.Out:
 					if err := sendFunc(sMsgP); err != nil {
-						log.Errorf("Send to %s failed: %v", p, err)
+						ss.s.log.Errorf("Send to %s failed: %v", p, err)
 					}
 				}
 			}
 		}()
 
-		p.Out <- &sMsg
+		if ss.landing != "" {
+			ss.land(p)
+
+			return
+		}
+		room, ok := ss.s.rm.GetRoom(ss.s.defaultRoom)
+		if !ok {
+			ss.s.log.Errorf("Unable to get room %s", ss.s.defaultRoom)
+			p.Disconnect("the default room is not available")
+
+			return
+		}
+		err = p.JoinRoom(room, "")
+		switch {
+		case errors.Is(err, internal.ErrQueued):
+			ss.s.log.Debugf("Participant %s queued in room %s", p, room.Name())
+		case err != nil:
+			ss.s.log.Errorf("Room checkout failed: %v", err)
+			p.Disconnect(fmt.Sprintf("cannot join room %s: %v", room.Name(), err))
+
+			return
+		default:
+			ss.confirmRoomCheckout()
+		}
+		// What the user missed while offline comes after the checkout.
+		ss.s.rm.FlushMailbox(p)
+	}
+}
+
+// land joins the landing room of a proxied session. The failures are
+// reported as failures of a JoinRoom, leaving p in no room.
+func (ss *session) land(p *internal.Participant) {
+	room, ok := ss.s.rm.FindRoom(ss.landing)
+	if !ok {
+		p.SendError(pb.ClientMessage_JoinRoom, fmt.Errorf("%w: %s", internal.ErrRoomNotFound, ss.landing))
+
+		return
+	}
+	err := p.JoinRoom(room, ss.landingInvite)
+	switch {
+	case errors.Is(err, internal.ErrQueued):
+		ss.s.log.Debugf("Participant %s queued in room %s", p, room.Name())
+	case err != nil:
+		p.SendError(pb.ClientMessage_JoinRoom, err)
+
+		return
+	default:
+		ss.confirmRoomCheckout()
 	}
+	ss.s.rm.FlushMailbox(p)
 }
 
-func writeMessageHandler(
-	ctx context.Context,
-	wg *sync.WaitGroup,
-	stream pb.Chat_RouteChatServer,
-	s *Server,
-	p *internal.Participant,
-	closeC chan<- closeCMD,
-) fsm.Callback {
+func directMessageHandler(ss *session) fsm.Callback {
 	return func(e *fsm.Event) {
 		cMsgP, err := extractClientMsg(e)
 		if err != nil {
-			log.Errorf("Cannot extract client msg: %v", err)
+			ss.s.log.Errorf("Cannot extract client msg: %v", err)
 
 			return
 		}
+		var directMsg pb.ClientMessage_ClientDirectMessage
+		if err := pbutils.UnmarshalAny(cMsgP.Operation, &directMsg); err != nil {
+			ss.p.SendError(pb.ClientMessage_DirectMessage, fmt.Errorf("%w: malformed %s: %v", internal.ErrInvalidArgument, cMsgP.Command, err))
 
-		p.CurrentRoom.In <- internal.RoomMessage{
+			return
+		}
+
+		if err := ss.s.rm.SendDirectMessage(ss.p, directMsg.To, directMsg.Body); err != nil {
+			ss.p.SendError(pb.ClientMessage_DirectMessage, err)
+		}
+	}
+}
+
+func writeMessageHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		cMsgP, err := extractClientMsg(e)
+		if err != nil {
+			ss.s.log.Errorf("Cannot extract client msg: %v", err)
+
+			return
+		}
+		r, ok := ss.currentRoom(cMsgP.Command)
+		if !ok {
+			return
+		}
+
+		err = r.Write(internal.RoomMessage{
 			CMsgP:       cMsgP,
-			Participant: p,
+			Participant: ss.p,
+		})
+		if err != nil {
+			ss.p.SendError(cMsgP.Command, err)
 		}
 	}
 }
 
-func quitHandler(
-	ctx context.Context,
-	wg *sync.WaitGroup,
-	stream pb.Chat_RouteChatServer,
-	s *Server,
-	p *internal.Participant,
-	closeC chan<- closeCMD,
-) fsm.Callback {
+func quitHandler(ss *session) fsm.Callback {
 	return func(e *fsm.Event) {
-		closeC <- closeCMD{}
+		ss.close(closeCMD{})
 	}
 }
+
+// currentRoom returns the room of the participant of the session, sending an
+// error caused by cmd to the participant when it is in no room.
+func (ss *session) currentRoom(cmd pb.ClientMessage_ClientCommand) (*internal.Room, bool) {
+	if ss.p == nil {
+		return nil, false
+	}
+	r := ss.p.CurrentRoom()
+	if r == nil {
+		ss.p.SendError(cmd, fmt.Errorf("%w: not in a room", internal.ErrPermissionDenied))
+
+		return nil, false
+	}
+
+	return r, true
+}
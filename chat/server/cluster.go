@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/savo92/playground-go-grpc/chat/cluster"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+// The nodes of a cluster share the rooms through consistent hashing of their
+// ids: each room lives on its owner, and the participants connected to the
+// other nodes reach it through ProxyChat. When the members change, the rooms
+// are handed over to their new owner, and the gateways of their participants
+// reconnect them to it.
+
+const (
+	proxyRoomKey   = "chat-proxy-room"
+	proxyInviteKey = "chat-proxy-invite"
+
+	// probeInterval is how often the peers are probed.
+	probeInterval = 2 * time.Second
+	// rebalanceInterval is how often the rooms of other nodes are handed
+	// over, beyond the changes of the members.
+	rebalanceInterval = 30 * time.Second
+	migrateTimeout    = 10 * time.Second
+)
+
+// errRemoteRoom is returned when creating a room owned by another node.
+var errRemoteRoom = errors.New("remote room")
+
+// clusterNode runs the cluster service of a Server.
+type clusterNode struct {
+	pb.UnimplementedChatClusterServer
+
+	s          *Server
+	membership *cluster.Membership
+	// secret authenticates the calls between the nodes, in both directions.
+	secret     string
+	listener   net.Listener
+	gRPCServer *grpc.Server
+
+	rebalanceC chan struct{}
+	// ctx is canceled when the node leaves the cluster.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// conns and leaving are guarded by mu.
+	conns   map[string]*grpc.ClientConn
+	leaving bool
+	mu      sync.Mutex
+}
+
+func newClusterNode(s *Server, self cluster.Member, peers []cluster.Member, secret string) (*clusterNode, error) {
+	if secret == "" {
+		return nil, errors.New("the cluster requires a secret")
+	}
+	listener, err := net.Listen("tcp", self.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &clusterNode{
+		s:        s,
+		listener: listener,
+		secret:   secret,
+		gRPCServer: grpc.NewServer(
+			grpc.UnaryInterceptor(bearerTokenInterceptor(secret, "invalid cluster secret")),
+			grpc.StreamInterceptor(bearerTokenStreamInterceptor(secret, "invalid cluster secret")),
+		),
+		rebalanceC: make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+		conns:      make(map[string]*grpc.ClientConn),
+	}
+	c.membership = cluster.NewMembership(self, peers, c.probe, func(*cluster.Ring) {
+		c.requestRebalance()
+	}, s.log)
+	pb.RegisterChatClusterServer(c.gRPCServer, c)
+
+	return c, nil
+}
+
+func (c *clusterNode) serve() error {
+	go c.membership.Run(c.ctx, probeInterval)
+	go c.rebalanceLoop()
+
+	return c.gRPCServer.Serve(c.listener)
+}
+
+// owner returns the member owning the room with the given id.
+func (c *clusterNode) owner(id internal.RoomID) cluster.Member {
+	return c.membership.Ring().Owner(string(id))
+}
+
+// roomID returns the id of the room created with the given name, or an
+// error when the room belongs to another node.
+func (c *clusterNode) roomID(name string) (internal.RoomID, error) {
+	id := internal.ClusterRoomID(name)
+	if owner := c.owner(id); owner.ID != c.membership.Self().ID {
+		return "", fmt.Errorf("%w: room %s belongs to node %s", errRemoteRoom, name, owner)
+	}
+
+	return id, nil
+}
+
+// conn returns a connection to the cluster service of m.
+func (c *clusterNode) conn(m cluster.Member) (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if conn, ok := c.conns[m.Addr]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(m.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(bearerToken(c.secret)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s failed: %w", m, err)
+	}
+	c.conns[m.Addr] = conn
+
+	return conn, nil
+}
+
+func (c *clusterNode) client(m cluster.Member) (pb.ChatClusterClient, error) {
+	conn, err := c.conn(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return pb.NewChatClusterClient(conn), nil
+}
+
+func (c *clusterNode) probe(ctx context.Context, m cluster.Member) error {
+	client, err := c.client(m)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Ping(ctx, &pb.PingRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.NodeId != m.ID {
+		return fmt.Errorf("%s answered as %s", m, resp.NodeId)
+	}
+
+	return nil
+}
+
+func (c *clusterNode) isLeaving() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.leaving
+}
+
+func (c *clusterNode) requestRebalance() {
+	select {
+	case c.rebalanceC <- struct{}{}:
+	default:
+	}
+}
+
+func (c *clusterNode) rebalanceLoop() {
+	t := time.NewTicker(rebalanceInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-t.C:
+		case <-c.rebalanceC:
+		}
+		c.rebalance(c.membership.Ring())
+	}
+}
+
+// rebalance creates the default room and the declared rooms when owned, and
+// hands the rooms owned by other members of ring over to them.
+func (c *clusterNode) rebalance(ring *cluster.Ring) {
+	self := c.membership.Self()
+	if ring.Len() > 0 && ring.Owner(string(c.s.defaultRoom)).ID == self.ID {
+		if _, ok := c.s.rm.GetRoom(c.s.defaultRoom); !ok {
+			_, err := c.s.createDefaultRoom(internal.WithID(c.s.defaultRoom))
+			if err != nil && !errors.Is(err, internal.ErrRoomExists) {
+				c.s.log.Errorf("Default room creation failed: %v", err)
+			}
+		}
+	}
+	if ring.Len() > 0 {
+		if err := c.s.provisionRooms(); err != nil {
+			c.s.log.Errorf("Rooms provisioning failed: %v", err)
+		}
+	}
+	for _, r := range c.s.rm.ListRooms() {
+		if ring.Len() == 0 {
+			return
+		}
+		owner := ring.Owner(string(r.ID()))
+		if owner.ID == self.ID {
+			continue
+		}
+		if err := c.migrate(r.ID(), owner); err != nil {
+			c.s.log.Errorf("Moving room %s to %s failed: %v", r.Name(), owner, err)
+
+			continue
+		}
+		c.s.log.Infof("Moved room %s to %s", r.Name(), owner)
+	}
+}
+
+// migrate hands the room with the given id over to owner.
+func (c *clusterNode) migrate(id internal.RoomID, owner cluster.Member) error {
+	client, err := c.client(owner)
+	if err != nil {
+		return err
+	}
+
+	return c.s.rm.HandOver(id, func(snap *pb.RoomSnapshot) error {
+		ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+		defer cancel()
+		_, err := client.MigrateRoom(ctx, &pb.MigrateRoomRequest{Room: snap})
+
+		return err
+	})
+}
+
+// leave tells the peers that the node leaves the cluster and hands all the
+// rooms over to them.
+func (c *clusterNode) leave(ctx context.Context) {
+	c.mu.Lock()
+	c.leaving = true
+	c.mu.Unlock()
+
+	self := c.membership.Self()
+	var peers []cluster.Member
+	for _, m := range c.membership.Ring().Members() {
+		if m.ID == self.ID {
+			continue
+		}
+		peers = append(peers, m)
+		client, err := c.client(m)
+		if err == nil {
+			_, err = client.Leave(ctx, &pb.LeaveRequest{NodeId: self.ID})
+		}
+		if err != nil {
+			c.s.log.Warnf("Leaving %s failed: %v", m, err)
+		}
+	}
+	c.cancel()
+	if len(peers) > 0 {
+		c.rebalance(cluster.NewRing(peers, cluster.DefaultVirtualNodes))
+	}
+}
+
+// shutdown stops the cluster service, after leave.
+func (c *clusterNode) shutdown(ctx context.Context) error {
+	err := shutdownGRPC(ctx, c.s.log, c.gRPCServer, c.listener, func() {})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for addr, conn := range c.conns {
+		if cErr := conn.Close(); cErr != nil {
+			c.s.log.Warnf("Closing the connection to %s failed: %v", addr, cErr)
+		}
+	}
+
+	return err
+}
+
+// ProxyChat runs the session of a participant connected to another node.
+func (c *clusterNode) ProxyChat(stream pb.ChatCluster_ProxyChatServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	landing := firstMetadata(md, proxyRoomKey)
+	if landing == "" {
+		return status.Errorf(codes.InvalidArgument, "%s is required", proxyRoomKey)
+	}
+
+	return c.s.routeChat(stream, landing, firstMetadata(md, proxyInviteKey))
+}
+
+func (c *clusterNode) MigrateRoom(ctx context.Context, req *pb.MigrateRoomRequest) (*pb.MigrateRoomResponse, error) {
+	if c.isLeaving() {
+		return nil, status.Error(codes.Unavailable, "the node is leaving the cluster")
+	}
+	id, err := c.s.rm.RestoreRoom(req.Room)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	c.s.log.Infof("Took over room %s (%s)", req.Room.GetInfo().GetName(), id)
+
+	return &pb.MigrateRoomResponse{}, nil
+}
+
+func (c *clusterNode) Leave(ctx context.Context, req *pb.LeaveRequest) (*pb.LeaveResponse, error) {
+	if _, ok := c.membership.Lookup(req.NodeId); !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown node %s", req.NodeId)
+	}
+	c.membership.SetAlive(req.NodeId, false)
+
+	return &pb.LeaveResponse{}, nil
+}
+
+func (c *clusterNode) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	if c.isLeaving() {
+		return nil, status.Error(codes.Unavailable, "the node is leaving the cluster")
+	}
+
+	return &pb.PingResponse{NodeId: c.membership.Self().ID}, nil
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+// The federated servers bridge some of their rooms: the messages written in
+// a bridged room are relayed to the room it is bridged to on the peer, with
+// their author attributed to the server of origin, as in alice@serverA. A
+// message is never relayed to a server it already crossed, so that the
+// bridges can form cycles.
+
+const (
+	federationServerKey = "chat-federation-server"
+
+	// relayQueueSize is how many messages wait to be relayed to a peer
+	// before the new ones are dropped.
+	relayQueueSize = 256
+	relayTimeout   = 10 * time.Second
+)
+
+// Federation configures the bridges of the server with the federated
+// servers.
+type Federation struct {
+	// Name identifies the server to its peers, and its users in the rooms of
+	// the peers.
+	Name string
+	// Addr is the address of the federation service of the server.
+	Addr  string
+	Peers []FederationPeer
+}
+
+// FederationPeer is a federated server.
+type FederationPeer struct {
+	Name string
+	// Addr is the address of the federation service of the peer.
+	Addr string
+	// Secret authenticates the calls in both directions.
+	Secret string
+	// Rooms allows the bridged rooms, mapping the names of the local rooms
+	// to the names of the rooms of the peer.
+	Rooms map[string]string
+}
+
+func (f Federation) validate() error {
+	if f.Name == "" || strings.Contains(f.Name, "@") {
+		return fmt.Errorf("invalid federation name %q", f.Name)
+	}
+	names := make(map[string]bool)
+	for _, p := range f.Peers {
+		if p.Name == "" || p.Name == f.Name || names[p.Name] {
+			return fmt.Errorf("invalid or duplicate federation peer %q", p.Name)
+		}
+		if p.Addr == "" || p.Secret == "" {
+			return fmt.Errorf("federation peer %s requires an address and a secret", p.Name)
+		}
+		names[p.Name] = true
+	}
+
+	return nil
+}
+
+// federationPeer relays the messages of the bridged rooms to a peer.
+type federationPeer struct {
+	FederationPeer
+	conn  *grpc.ClientConn
+	queue chan *pb.RelayRequest
+}
+
+// run relays the queued messages until done is closed.
+func (p *federationPeer) run(self string, done <-chan struct{}, logger log.FieldLogger) {
+	client := pb.NewChatFederationClient(p.conn)
+	for {
+		var req *pb.RelayRequest
+		select {
+		case <-done:
+			return
+		case req = <-p.queue:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), relayTimeout)
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			federationServerKey, self,
+			"authorization", "Bearer "+p.Secret,
+		)
+		if _, err := client.Relay(ctx, req); err != nil {
+			logger.Warnf("Relaying a message of room %s to %s failed: %v", req.Room, p.Name, err)
+		}
+		cancel()
+	}
+}
+
+// federationNode runs the federation service of a Server.
+type federationNode struct {
+	pb.UnimplementedChatFederationServer
+
+	s          *Server
+	name       string
+	peers      map[string]*federationPeer
+	listener   net.Listener
+	gRPCServer *grpc.Server
+	// done is closed with the node, stopping the relays.
+	done chan struct{}
+}
+
+func newFederationNode(s *Server, f Federation) (*federationNode, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", f.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	n := &federationNode{
+		s:        s,
+		name:     f.Name,
+		peers:    make(map[string]*federationPeer, len(f.Peers)),
+		listener: listener,
+		done:     make(chan struct{}),
+	}
+	for _, p := range f.Peers {
+		conn, err := grpc.Dial(p.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			n.closePeers()
+			listener.Close()
+
+			return nil, fmt.Errorf("dial %s failed: %w", p.Name, err)
+		}
+		n.peers[p.Name] = &federationPeer{
+			FederationPeer: p,
+			conn:           conn,
+			queue:          make(chan *pb.RelayRequest, relayQueueSize),
+		}
+	}
+	n.gRPCServer = grpc.NewServer(grpc.UnaryInterceptor(n.authInterceptor))
+	pb.RegisterChatFederationServer(n.gRPCServer, n)
+	s.rm.SetRelay(n.relay)
+
+	return n, nil
+}
+
+func (n *federationNode) serve() error {
+	for _, p := range n.peers {
+		go p.run(n.name, n.done, n.s.log)
+	}
+
+	return n.gRPCServer.Serve(n.listener)
+}
+
+func (n *federationNode) shutdown(ctx context.Context) error {
+	err := shutdownGRPC(ctx, n.s.log, n.gRPCServer, n.listener, func() {})
+	n.closePeers()
+
+	return err
+}
+
+func (n *federationNode) closePeers() {
+	close(n.done)
+	for _, p := range n.peers {
+		if err := p.conn.Close(); err != nil {
+			n.s.log.Warnf("Closing the connection to %s failed: %v", p.Name, err)
+		}
+	}
+}
+
+// relay queues m to the peers its room is bridged with, but the ones it
+// crossed. It never blocks.
+func (n *federationNode) relay(m internal.RelayedMessage) {
+	author := m.Author
+	if len(m.Via) == 0 {
+		author += "@" + n.name
+	}
+	via := append(append([]string(nil), m.Via...), n.name)
+	for _, p := range n.peers {
+		remote, ok := p.Rooms[m.Room]
+		if !ok || crossed(m.Via, p.Name) {
+			continue
+		}
+		req := &pb.RelayRequest{
+			Room:   remote,
+			Author: author,
+			Body:   m.Body,
+			SentAt: timestamppb.New(m.SentAt),
+			Via:    via,
+		}
+		select {
+		case p.queue <- req:
+		default:
+			n.s.log.Warnf("Relay queue of %s full, dropping a message of room %s", p.Name, m.Room)
+		}
+	}
+}
+
+func (n *federationNode) Relay(ctx context.Context, req *pb.RelayRequest) (*pb.RelayResponse, error) {
+	p, ok := peerFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unknown federation peer")
+	}
+	if len(req.Via) == 0 || req.Via[len(req.Via)-1] != p.Name {
+		return nil, status.Errorf(codes.InvalidArgument, "the message must come through %s", p.Name)
+	}
+	origin := req.Via[0]
+	if i := strings.LastIndex(req.Author, "@"); i <= 0 || req.Author[i+1:] != origin {
+		return nil, status.Errorf(codes.InvalidArgument, "author %q is not attributed to the server of origin %s", req.Author, origin)
+	}
+	// The messages of the local users, whose origin is the server, are
+	// dropped with the ones that came back through a cycle of bridges.
+	if crossed(req.Via, n.name) {
+		n.s.log.Debugf("Dropping a message of room %s already relayed by %s", req.Room, n.name)
+
+		return &pb.RelayResponse{Dropped: true}, nil
+	}
+	// The servers beyond the peer are trusted as much as the peer, which
+	// relays their messages.
+	if !bridged(p, req.Room) {
+		return nil, status.Errorf(codes.PermissionDenied, "room %s is not bridged with %s", req.Room, p.Name)
+	}
+	err := n.s.rm.Inject(internal.RelayedMessage{
+		Room:   req.Room,
+		Author: req.Author,
+		Body:   req.Body,
+		SentAt: req.SentAt.AsTime(),
+		Via:    req.Via,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.RelayResponse{}, nil
+}
+
+type federationPeerKey struct{}
+
+func peerFromContext(ctx context.Context) (*federationPeer, bool) {
+	p, ok := ctx.Value(federationPeerKey{}).(*federationPeer)
+
+	return p, ok
+}
+
+// authInterceptor authenticates the calling peer with its secret.
+func (n *federationNode) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, ok := n.peers[firstMetadata(md, federationServerKey)]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unknown federation peer")
+	}
+	want := []byte("Bearer " + p.Secret)
+	for _, got := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(got), want) == 1 {
+			return handler(context.WithValue(ctx, federationPeerKey{}, p), req)
+		}
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "invalid federation secret")
+}
+
+// bridged reports whether the local room named room is bridged with p.
+func bridged(p *federationPeer, room string) bool {
+	_, ok := p.Rooms[room]
+
+	return ok
+}
+
+func crossed(via []string, name string) bool {
+	for _, v := range via {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestFederationRelay(t *testing.T) {
+	testsTable := []struct {
+		Name        string
+		Author      string
+		Via         []string
+		WantCode    codes.Code
+		WantDropped bool
+	}{
+		{
+			Name:   "user of the peer",
+			Author: "bob@b",
+			Via:    []string{"b"},
+		},
+		{
+			Name:   "user of a peer through another",
+			Author: "carol@c",
+			Via:    []string{"c", "b"},
+		},
+		{
+			Name:     "unattributed author",
+			Author:   "bob",
+			Via:      []string{"b"},
+			WantCode: codes.InvalidArgument,
+		},
+		{
+			Name:     "author of another server",
+			Author:   "carol@c",
+			Via:      []string{"b"},
+			WantCode: codes.InvalidArgument,
+		},
+		{
+			Name:     "local author",
+			Author:   "alice@a",
+			Via:      []string{"b"},
+			WantCode: codes.InvalidArgument,
+		},
+		{
+			Name:        "local author through a cycle",
+			Author:      "alice@a",
+			Via:         []string{"a", "b"},
+			WantDropped: true,
+		},
+		{
+			Name:   "user of a server beyond the peers",
+			Author: "dave@d",
+			Via:    []string{"d", "b"},
+		},
+		{
+			Name:     "not through the caller",
+			Author:   "carol@c",
+			Via:      []string{"c"},
+			WantCode: codes.InvalidArgument,
+		},
+	}
+
+	s, _ := newTestClient(t, WithFederation(Federation{
+		Name: "a",
+		Addr: "127.0.0.1:0",
+		Peers: []FederationPeer{
+			{Name: "b", Addr: "127.0.0.1:1", Secret: "b-secret", Rooms: map[string]string{"default": "lobby"}},
+			{Name: "c", Addr: "127.0.0.1:2", Secret: "c-secret"},
+		},
+	}))
+	n := s.federation
+	ctx := context.WithValue(context.Background(), federationPeerKey{}, n.peers["b"])
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			resp, err := n.Relay(ctx, &pb.RelayRequest{
+				Room:   "default",
+				Author: tt.Author,
+				Body:   "hi",
+				SentAt: timestamppb.Now(),
+				Via:    tt.Via,
+			})
+			if code := status.Code(err); code != tt.WantCode {
+				t.Fatalf("Relay returned %v, want code %s", err, tt.WantCode)
+			}
+			if err == nil && resp.Dropped != tt.WantDropped {
+				t.Errorf("dropped %t, want %t", resp.Dropped, tt.WantDropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/savo92/playground-go-grpc/chat/cluster"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+// moveTimeout is how long a gateway tries to reach the new owner of a room
+// handed over.
+const moveTimeout = 5 * time.Second
+
+// backend is a ProxyChat session on the owner of a room.
+type backend struct {
+	owner  cluster.Member
+	stream pb.ChatCluster_ProxyChatClient
+	cancel context.CancelFunc
+}
+
+func (b *backend) close() {
+	b.cancel()
+}
+
+// gateway carries the RouteChat session of a participant to the owner of its
+// room, switching backend when the participant joins a room of another node
+// or when its room is handed over.
+type gateway struct {
+	c      *clusterNode
+	ctx    context.Context
+	cancel context.CancelFunc
+	stream pb.Chat_RouteChatServer
+	helo   *pb.ClientMessage
+
+	// sendMu serializes the sends to the stream.
+	sendMu sync.Mutex
+
+	// The fields below are guarded by mu. While moving, the client messages
+	// are kept in pending until the new backend is reached.
+	backend *backend
+	room    internal.RoomID
+	moving  bool
+	pending []*pb.ClientMessage
+	// skipCheckout hides the confirmation of the room joined again after a
+	// handover.
+	skipCheckout bool
+	mu           sync.Mutex
+}
+
+func (c *clusterNode) gateway(stream pb.Chat_RouteChatServer) error {
+	helo, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if helo.Command != pb.ClientMessage_Helo {
+		return status.Errorf(codes.FailedPrecondition, "%s expected, got %s", pb.ClientMessage_Helo, helo.Command)
+	}
+	var heloMsg pb.ClientMessage_ClientHelo
+	if err := pbutils.UnmarshalAny(helo.Operation, &heloMsg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed %s: %v", helo.Command, err)
+	}
+	if err := c.s.authenticate(stream.Context(), heloMsg.Author); err != nil {
+		return status.Errorf(codes.Unauthenticated, "%s: %v", heloMsg.Author, err)
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	g := &gateway{
+		c:      c,
+		ctx:    ctx,
+		cancel: cancel,
+		stream: stream,
+		helo:   helo,
+	}
+	b, msgs, joined, err := g.open(string(c.s.defaultRoom), "")
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "the default room is not available: %v", err)
+	}
+	if !joined {
+		for _, m := range msgs {
+			g.send(m)
+		}
+
+		return status.Error(codes.Unavailable, "the default room is not available")
+	}
+	g.switchTo(b, msgs)
+	go g.recvLoop()
+
+	select {
+	case <-ctx.Done():
+	case <-c.ctx.Done():
+	}
+	g.mu.Lock()
+	b = g.backend
+	g.backend = nil
+	g.mu.Unlock()
+	if b != nil {
+		b.close()
+	}
+
+	return nil
+}
+
+// open starts a session on the owner of the room ref, landing in it with the
+// given invite code. It returns the messages received until the room was
+// joined, or until joining it failed.
+func (g *gateway) open(ref, invite string) (b *backend, msgs []*pb.ServerMessage, joined bool, err error) {
+	owner := g.c.owner(clusterRef(ref))
+	client, err := g.c.client(owner)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	ctx, cancel := context.WithCancel(g.ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, proxyRoomKey, ref, proxyInviteKey, invite)
+	stream, err := client.ProxyChat(ctx)
+	if err != nil {
+		cancel()
+
+		return nil, nil, false, fmt.Errorf("proxy to %s failed: %w", owner, err)
+	}
+	b = &backend{owner: owner, stream: stream, cancel: cancel}
+	if err := stream.Send(g.helo); err != nil {
+		b.close()
+
+		return nil, nil, false, fmt.Errorf("proxy to %s failed: %w", owner, err)
+	}
+
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			b.close()
+
+			return nil, nil, false, fmt.Errorf("proxy to %s failed: %w", owner, err)
+		}
+		msgs = append(msgs, m)
+		switch m.Command {
+		case pb.ServerMessage_ConfirmRoomCheckout, pb.ServerMessage_QueuePosition:
+			return b, msgs, true, nil
+		case pb.ServerMessage_Error:
+			var errorMsg pb.ServerMessage_ServerError
+			if err := pbutils.UnmarshalAny(m.Operation, &errorMsg); err == nil && errorMsg.Command == pb.ClientMessage_JoinRoom {
+				b.close()
+
+				return nil, msgs, false, nil
+			}
+		case pb.ServerMessage_Shutdown:
+			b.close()
+
+			return nil, msgs, false, nil
+		}
+	}
+}
+
+// switchTo makes b the backend of the session, after forwarding msgs, and
+// closes the previous one.
+func (g *gateway) switchTo(b *backend, msgs []*pb.ServerMessage) {
+	g.mu.Lock()
+	old := g.backend
+	g.backend = b
+	g.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+	for _, m := range msgs {
+		g.forward(b, m)
+	}
+	go g.pump(b)
+}
+
+// pump forwards the messages of b while it is the backend of the session.
+func (g *gateway) pump(b *backend) {
+	for {
+		m, err := b.stream.Recv()
+		if err != nil {
+			g.mu.Lock()
+			current := g.backend == b
+			g.mu.Unlock()
+			if current {
+				// The session ended on the owner.
+				g.cancel()
+			}
+
+			return
+		}
+		if m.Command == pb.ServerMessage_Shutdown && shutdownReason(m) == internal.ReasonRoomMoved {
+			g.mu.Lock()
+			current := g.backend == b
+			room := g.room
+			g.moving = current
+			g.mu.Unlock()
+			if current {
+				g.move(room, m)
+			}
+
+			return
+		}
+		g.forward(b, m)
+	}
+}
+
+// move joins room again on its new owner, or forwards shutdown and ends the
+// session when it cannot be reached.
+func (g *gateway) move(room internal.RoomID, shutdown *pb.ServerMessage) {
+	deadline := time.Now().Add(moveTimeout)
+	delay := 100 * time.Millisecond
+	for {
+		b, msgs, joined, err := g.open(string(room), "")
+		if err == nil && joined {
+			g.mu.Lock()
+			g.skipCheckout = true
+			g.mu.Unlock()
+			g.switchTo(b, msgs)
+			g.flushPending()
+
+			return
+		}
+		if time.Now().Add(delay).After(deadline) {
+			g.c.s.log.Errorf("Reaching room %s after its handover failed: %v", room, err)
+			g.send(shutdown)
+			g.cancel()
+
+			return
+		}
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// forward sends m, received from b, to the participant when b is still the
+// backend of the session.
+func (g *gateway) forward(b *backend, m *pb.ServerMessage) {
+	g.mu.Lock()
+	if g.backend != b {
+		g.mu.Unlock()
+
+		return
+	}
+	if m.Command == pb.ServerMessage_ConfirmRoomCheckout {
+		var confirm pb.ServerMessage_ServerConfirmRoomCheckout
+		if err := pbutils.UnmarshalAny(m.Operation, &confirm); err == nil {
+			g.room = internal.RoomID(confirm.Room.GetId())
+		}
+		if g.skipCheckout {
+			g.skipCheckout = false
+			g.mu.Unlock()
+
+			return
+		}
+	}
+	g.mu.Unlock()
+	g.send(m)
+}
+
+func (g *gateway) send(m *pb.ServerMessage) {
+	g.sendMu.Lock()
+	defer g.sendMu.Unlock()
+
+	if err := g.stream.Send(m); err != nil {
+		g.c.s.log.Debugf("Send failed: %v", err)
+		g.cancel()
+	}
+}
+
+func (g *gateway) sendError(cmd pb.ClientMessage_ClientCommand, err error) {
+	m, mErr := internal.NewErrorMessage(cmd, err)
+	if mErr != nil {
+		g.c.s.log.Errorf("Marshal from error failed: %v", mErr)
+
+		return
+	}
+	g.send(m)
+}
+
+// recvLoop routes the messages of the participant until the stream ends.
+func (g *gateway) recvLoop() {
+	for {
+		m, err := g.stream.Recv()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && g.ctx.Err() == nil {
+				g.c.s.log.Errorf("Recv failed: %v", err)
+			}
+			g.cancel()
+
+			return
+		}
+		g.mu.Lock()
+		if g.moving {
+			g.pending = append(g.pending, m)
+			g.mu.Unlock()
+
+			continue
+		}
+		g.mu.Unlock()
+		g.route(m)
+	}
+}
+
+// flushPending routes the messages received while moving.
+func (g *gateway) flushPending() {
+	for {
+		g.mu.Lock()
+		if len(g.pending) == 0 {
+			g.moving = false
+			g.mu.Unlock()
+
+			return
+		}
+		m := g.pending[0]
+		g.pending = g.pending[1:]
+		g.mu.Unlock()
+		g.route(m)
+	}
+}
+
+// route sends m to the backend of the session, unless it joins a room of
+// another node.
+func (g *gateway) route(m *pb.ClientMessage) {
+	if m.Command == pb.ClientMessage_JoinRoom && g.join(m) {
+		return
+	}
+	g.mu.Lock()
+	b := g.backend
+	g.mu.Unlock()
+	if b == nil {
+		return
+	}
+	if err := b.stream.Send(m); err != nil {
+		g.c.s.log.Debugf("Send to %s failed: %v", b.owner, err)
+	}
+}
+
+// join switches the session to the owner of the room joined by m, reporting
+// whether it handled m. The rooms of the node of the current backend, and
+// the rooms joined by invite code only, are joined by the backend.
+func (g *gateway) join(m *pb.ClientMessage) bool {
+	var joinMsg pb.ClientMessage_ClientJoinRoom
+	if err := pbutils.UnmarshalAny(m.Operation, &joinMsg); err != nil || joinMsg.Room == "" {
+		return false
+	}
+	g.mu.Lock()
+	current := g.backend
+	g.mu.Unlock()
+	if current == nil || g.c.owner(clusterRef(joinMsg.Room)).ID == current.owner.ID {
+		return false
+	}
+
+	b, msgs, joined, err := g.open(joinMsg.Room, joinMsg.InviteCode)
+	if err != nil {
+		g.sendError(pb.ClientMessage_JoinRoom, fmt.Errorf("room %s is not available: %w", joinMsg.Room, err))
+
+		return true
+	}
+	if !joined {
+		for _, m := range msgs {
+			if m.Command != pb.ServerMessage_Shutdown {
+				g.send(m)
+			}
+		}
+
+		return true
+	}
+	g.switchTo(b, msgs)
+
+	return true
+}
+
+// clusterRef returns the id of the room ref, an id or a name.
+func clusterRef(ref string) internal.RoomID {
+	if _, err := uuid.Parse(ref); err == nil {
+		return internal.RoomID(ref)
+	}
+
+	return internal.ClusterRoomID(ref)
+}
+
+func shutdownReason(m *pb.ServerMessage) string {
+	var shutdownMsg pb.ServerMessage_ServerShutdown
+	if err := pbutils.UnmarshalAny(m.Operation, &shutdownMsg); err != nil {
+		return ""
+	}
+
+	return shutdownMsg.Reason
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// The gRPC-Web endpoint serves WatchRoom to the browsers, in the binary
+// (application/grpc-web+proto) and the text (application/grpc-web-text)
+// formats. The other methods are served over gRPC only.
+
+const (
+	grpcWebWatchRoomPath = "/pbuf.Chat/WatchRoom"
+	// grpcWebMaxRequest bounds the request message.
+	grpcWebMaxRequest = 64 << 10
+
+	grpcWebDataFrame    = 0x00
+	grpcWebTrailerFrame = 0x80
+)
+
+// grpcWebWriter writes the frames of a gRPC-Web response.
+type grpcWebWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	text    bool
+}
+
+func (gw *grpcWebWriter) writeFrame(flag byte, payload []byte) error {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	if gw.text {
+		frame = []byte(base64.StdEncoding.EncodeToString(frame))
+	}
+	if _, err := gw.w.Write(frame); err != nil {
+		return err
+	}
+	gw.flusher.Flush()
+
+	return nil
+}
+
+func (gw *grpcWebWriter) send(sMsgP *pb.ServerMessage) error {
+	payload, err := proto.Marshal(sMsgP)
+	if err != nil {
+		return err
+	}
+
+	return gw.writeFrame(grpcWebDataFrame, payload)
+}
+
+func (gw *grpcWebWriter) writeStatus(st *status.Status) error {
+	message := strings.NewReplacer("\r", " ", "\n", " ").Replace(st.Message())
+	trailer := fmt.Sprintf("grpc-status: %d\r\ngrpc-message: %s\r\n", st.Code(), message)
+
+	return gw.writeFrame(grpcWebTrailerFrame, []byte(trailer))
+}
+
+func (h *HTTPServer) serveGRPCWebWatchRoom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(contentType, "application/grpc-web-text")
+	if !text && !strings.HasPrefix(contentType, "application/grpc-web") {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	var body io.Reader = io.LimitReader(r.Body, grpcWebMaxRequest)
+	if text {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	var req pb.WatchRoomRequest
+	reqErr := readGRPCWebRequest(body, &req)
+
+	if text {
+		w.Header().Set("Content-Type", "application/grpc-web-text+proto")
+	} else {
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+	}
+	w.WriteHeader(http.StatusOK)
+	gw := &grpcWebWriter{w: w, flusher: flusher, text: text}
+	err := reqErr
+	if err == nil {
+		err = h.watchGRPCWeb(r, gw, req.Room)
+	}
+	if wErr := gw.writeStatus(status.Convert(err)); wErr != nil {
+		h.s.log.Debugf("gRPC-Web stream ended: %v", wErr)
+	}
+}
+
+func (h *HTTPServer) watchGRPCWeb(r *http.Request, gw *grpcWebWriter, room string) error {
+	watcher, err := h.s.watch(room)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// The stream ends with the request, or when the server shuts down.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-h.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return forwardWatched(ctx, watcher, gw.send)
+}
+
+// readGRPCWebRequest reads the single, uncompressed, message of a request.
+func readGRPCWebRequest(r io.Reader, m proto.Message) error {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed request: %v", err)
+	}
+	if header[0] != grpcWebDataFrame {
+		return status.Error(codes.Unimplemented, "compressed requests are not supported")
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if len(payload) > grpcWebMaxRequest {
+		return status.Error(codes.ResourceExhausted, "request too large")
+	}
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed request: %v", err)
+	}
+	if err := proto.Unmarshal(payload, m); err != nil {
+		return status.Errorf(codes.InvalidArgument, "malformed request: %v", err)
+	}
+
+	return nil
+}
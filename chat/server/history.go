@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"github.com/looplab/fsm"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+func (s *Server) GetHistory(ctx context.Context, req *pb.GetHistoryRequest) (*pb.GetHistoryResponse, error) {
+	username, err := s.callerUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := s.rm.FindRoom(req.Room)
+	if !ok {
+		return nil, toStatus(fmt.Errorf("%w: %s", internal.ErrRoomNotFound, req.Room))
+	}
+	res, err := r.History(username, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return res, nil
+}
+
+func getHistoryHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		cMsgP, err := extractClientMsg(e)
+		if err != nil {
+			ss.s.log.Errorf("Cannot extract client msg: %v", err)
+
+			return
+		}
+		var getHistoryMsg pb.ClientMessage_ClientGetHistory
+		if err := pbutils.UnmarshalAny(cMsgP.Operation, &getHistoryMsg); err != nil || getHistoryMsg.Request == nil {
+			ss.p.SendError(pb.ClientMessage_GetHistory, fmt.Errorf("%w: malformed %s", internal.ErrInvalidArgument, cMsgP.Command))
+
+			return
+		}
+
+		req := getHistoryMsg.Request
+		var r *internal.Room
+		var ok bool
+		if req.Room == "" {
+			if r, ok = ss.currentRoom(pb.ClientMessage_GetHistory); !ok {
+				return
+			}
+		} else if r, ok = ss.s.rm.FindRoom(req.Room); !ok {
+			ss.p.SendError(pb.ClientMessage_GetHistory, fmt.Errorf("%w: %s", internal.ErrRoomNotFound, req.Room))
+
+			return
+		}
+		res, err := r.History(ss.p.Username(), req)
+		if err != nil {
+			ss.p.SendError(pb.ClientMessage_GetHistory, err)
+
+			return
+		}
+		ss.send(pb.ServerMessage_History, &pb.ServerMessage_ServerHistory{
+			RoomId:   string(r.ID()),
+			Response: res,
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// HTTPServer exposes the chat of a Server to the clients unable to speak
+// gRPC, on its own listener. The WebSocket sessions are served on /ws, the
+// read-only feeds of the rooms on /events, as Server-Sent Events, and on
+// /pbuf.Chat/WatchRoom, for the gRPC-Web clients.
+type HTTPServer struct {
+	listener   net.Listener
+	httpServer *http.Server
+
+	s *Server
+	// ctx is canceled on shutdown, ending the hijacked connections.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (h *HTTPServer) Serve() error {
+	if err := h.httpServer.Serve(h.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func (h *HTTPServer) Shutdown(ctx context.Context) error {
+	h.cancel()
+	if err := h.httpServer.Shutdown(ctx); err != nil {
+		h.s.log.Warn("Graceful HTTP shutdown timed out, killing")
+
+		return h.httpServer.Close()
+	}
+
+	return nil
+}
+
+// NewHTTPServer listens on port, on the bind address of s, for the clients
+// of s. The requests are
+// accepted from the pages served by the same origin, or by one of
+// allowedOrigins.
+func NewHTTPServer(port int, s *Server, allowedOrigins ...string) (*HTTPServer, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.bindAddress, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &HTTPServer{
+		listener: listener,
+		s:        s,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/ws", h.webSocketHandler(allowedOrigins))
+	mux.Handle("/events", withCORS(allowedOrigins, http.HandlerFunc(h.serveEvents)))
+	mux.Handle(grpcWebWatchRoomPath, withCORS(allowedOrigins, http.HandlerFunc(h.serveGRPCWebWatchRoom)))
+	h.httpServer = &http.Server{Handler: mux}
+
+	return h, nil
+}
+
+// withCORS allows the cross-origin requests of allowedOrigins to next, and
+// answers their preflight requests.
+func withCORS(allowedOrigins []string, next http.Handler) http.Handler {
+	allowed := checkOrigin(allowedOrigins)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			if !allowed(r) {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", "grpc-status, grpc-message")
+			w.Header().Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "content-type, x-grpc-web, x-user-agent, grpc-timeout")
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
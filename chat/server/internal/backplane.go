@@ -0,0 +1,562 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// The replicas sharing a backplane share the rooms with the same name: the
+// messages written in a room are published on its topic, and every replica
+// stores and forwards them to its participants. Each replica stores the
+// messages under its own IDs. The changes of the state of a room, its
+// metadata, mode, visibility, capacity, roles, bans, mutes, invites and read
+// markers, are published on its topic as well, and applied by the other
+// replicas. The rooms created on a replica are created on the others, which
+// close them on their own, as their participants leave.
+//
+// The replicas publish their online users, so that the direct messages and
+// mentions are forwarded to the replica their recipient is connected to.
+
+const (
+	// presenceInterval is how often a replica publishes its online users.
+	presenceInterval = 10 * time.Second
+	// presenceExpiry is how long the online users of a replica are trusted
+	// without news from it.
+	presenceExpiry = 3 * presenceInterval
+	presenceTopic  = "chat.presence"
+	// roomsTopic carries the rooms created by the replicas.
+	roomsTopic = "chat.rooms"
+	// eventQueueSize is how many messages received from the backplane wait
+	// to be delivered by a room, or by the manager, before the new ones are
+	// dropped.
+	eventQueueSize = 256
+)
+
+func roomTopic(name string) string {
+	return "chat.room." + name
+}
+
+// nodeTopic is the topic of the mail forwarded to a replica.
+func nodeTopic(node string) string {
+	return "chat.node." + node
+}
+
+// eventKind tells the messages from the changes of the state of a room.
+type eventKind string
+
+const (
+	eventMessage     eventKind = ""
+	eventTopic       eventKind = "topic"
+	eventDescription eventKind = "description"
+	eventLabel       eventKind = "label"
+	eventMode        eventKind = "mode"
+	eventVisibility  eventKind = "visibility"
+	eventCapacity    eventKind = "capacity"
+	eventRole        eventKind = "role"
+	eventMute        eventKind = "mute"
+	eventBan         eventKind = "ban"
+	eventUnban       eventKind = "unban"
+	eventInvite      eventKind = "invite"
+	eventMember      eventKind = "member"
+	eventReadMarker  eventKind = "read_marker"
+)
+
+// roomEvent is a message written in a room, or a change of its state.
+type roomEvent struct {
+	Kind   eventKind `json:"kind,omitempty"`
+	Node   string    `json:"node"`
+	Author string    `json:"author,omitempty"`
+	Body   string    `json:"body,omitempty"`
+	SentAt time.Time `json:"sent_at"`
+	// Via lists the federated servers a relayed message crossed, its origin
+	// first.
+	Via []string `json:"via,omitempty"`
+	// Change is set for the changes of the state.
+	Change *roomChange `json:"change,omitempty"`
+}
+
+// roomChange is a change of the state of a room. The fields set depend on
+// the kind of the event.
+type roomChange struct {
+	// Username is the user whose role, mute, ban, membership or read marker
+	// changed, or the one who changed the topic.
+	Username string `json:"username,omitempty"`
+	// Value is the topic, the description, the value of the label Key or the
+	// reason of the ban.
+	Value string `json:"value,omitempty"`
+	// Key is the key of the label, or the code of the invite redeemed by a
+	// new member.
+	Key              string            `json:"key,omitempty"`
+	Mode             pb.RoomMode       `json:"mode,omitempty"`
+	SlowModeInterval time.Duration     `json:"slow_mode_interval,omitempty"`
+	Role             pb.Role           `json:"role,omitempty"`
+	Visibility       pb.RoomVisibility `json:"visibility,omitempty"`
+	// Capacity and Queue are the capacity of the room and whether the
+	// participants joining it when full wait for a seat.
+	Capacity int  `json:"capacity,omitempty"`
+	Queue    bool `json:"queue,omitempty"`
+	Muted    bool `json:"muted,omitempty"`
+	// Until is the end of the ban, zero for the bans without expiration.
+	Until  time.Time `json:"until,omitempty"`
+	Invite *Invite   `json:"invite,omitempty"`
+	// InviteUses is the number of uses of Invite, or of the invite code Key
+	// when redeemed.
+	InviteUses int `json:"invite_uses,omitempty"`
+	// ReadUpTo is the time of the message read up to, whose ID differs on
+	// each replica. Share is set when the read receipt is shared with the
+	// room.
+	ReadUpTo time.Time `json:"read_up_to,omitempty"`
+	Share    bool      `json:"share,omitempty"`
+}
+
+// roomCreated announces a room created on a replica, with its state.
+type roomCreated struct {
+	Node     string `json:"node"`
+	Snapshot []byte `json:"snapshot"`
+}
+
+// presenceEvent lists the online users of a replica.
+type presenceEvent struct {
+	Node      string   `json:"node"`
+	Usernames []string `json:"usernames"`
+	// Leaving is set when the replica shuts down.
+	Leaving bool `json:"leaving,omitempty"`
+}
+
+// remotePresence holds the online users of another replica.
+type remotePresence struct {
+	usernames map[string]bool
+	seenAt    time.Time
+}
+
+// publish publishes a message written in the room to the replicas.
+func (r *room) publish(ev roomEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	return r.rm.backplane.Publish(roomTopic(r.name), payload)
+}
+
+// publishChange publishes a change of the state of the room, made on the
+// replica, to the other replicas. The caller must not hold r.mu.
+func (r *room) publishChange(kind eventKind, change roomChange) {
+	ev := roomEvent{Kind: kind, Node: r.rm.node, SentAt: time.Now(), Change: &change}
+	if err := r.publish(ev); err != nil {
+		r.rm.log.Warnf("Publishing the %s change of room %s failed: %v", kind, r.name, err)
+	}
+}
+
+// receive queues a message published in the room, delivered by the room
+// goroutine. It never blocks the backplane.
+func (r *room) receive(payload []byte) {
+	select {
+	case r.events <- payload:
+	default:
+		r.rm.log.Warnf("Event queue of room %s full, dropping a message", r.name)
+	}
+}
+
+// flushEvents delivers the events queued. It runs in the room goroutine.
+func (r *room) flushEvents() {
+	for {
+		select {
+		case payload := <-r.events:
+			r.deliver(payload)
+		default:
+			return
+		}
+	}
+}
+
+// deliver stores and forwards a message published in the room, or applies a
+// change made on another replica.
+func (r *room) deliver(payload []byte) {
+	var ev roomEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		r.rm.log.Errorf("Malformed message in room %s: %v", r.name, err)
+
+		return
+	}
+	if ev.Kind != eventMessage {
+		// The changes are applied by the replica making them.
+		if ev.Node != r.rm.node && ev.Change != nil {
+			r.applyChange(ev.Kind, *ev.Change)
+		}
+
+		return
+	}
+	msg := store.Message{
+		RoomID: string(r.id),
+		Author: ev.Author,
+		Body:   ev.Body,
+		SentAt: ev.SentAt,
+	}
+	if stored, err := r.rm.store.AppendMessage(msg); err != nil {
+		r.rm.log.Errorf("Storing message in room %s failed: %v", r.name, err)
+	} else {
+		msg = stored
+		r.rm.index.Add(msg)
+		r.mu.Lock()
+		r.lastMessageID = msg.ID
+		r.mu.Unlock()
+	}
+
+	forwardMessage := pb.ServerMessage_ServerForwardMessage{
+		Author: msg.Author,
+		Body:   msg.Body,
+		Id:     uint64(msg.ID),
+		SentAt: timestamppb.New(msg.SentAt),
+	}
+	op, err := pbutils.MarshalAny(&forwardMessage)
+	if err != nil {
+		r.rm.log.Errorf("Marshal from forwardMessage failed: %v", err)
+
+		return
+	}
+	sMsgP := &pb.ServerMessage{
+		Command:   pb.ServerMessage_ForwardMessage,
+		Operation: op,
+	}
+	for _, p := range copyParticipants(r) {
+		p.Send(sMsgP)
+	}
+	r.notifyWatchers(sMsgP)
+	// The mentions are notified, and the message relayed to the federated
+	// servers, once, by the replica of the author.
+	if ev.Node == r.rm.node {
+		r.rm.notifyMentions(r, msg)
+		r.rm.relay(r, msg, ev.Via)
+	}
+	atomic.AddUint64(&r.rm.messages, 1)
+	r.mu.Lock()
+	r.lastActivity = msg.SentAt
+	r.mu.Unlock()
+}
+
+// applyChange applies a change of the state of the room made on another
+// replica.
+func (r *room) applyChange(kind eventKind, c roomChange) {
+	switch kind {
+	case eventTopic:
+		r.mu.Lock()
+		r.topic = c.Value
+		r.mu.Unlock()
+		r.broadcastTopic(c.Value, c.Username)
+	case eventDescription:
+		r.mu.Lock()
+		r.description = c.Value
+		r.mu.Unlock()
+	case eventLabel:
+		r.mu.Lock()
+		r.setLabel(c.Key, c.Value)
+		r.mu.Unlock()
+	case eventMode:
+		r.mu.Lock()
+		r.setMode(c.Mode, c.SlowModeInterval)
+		r.mu.Unlock()
+		r.broadcastState()
+	case eventVisibility:
+		r.mu.Lock()
+		r.visibility = c.Visibility
+		r.mu.Unlock()
+	case eventCapacity:
+		r.mu.Lock()
+		r.capacity = c.Capacity
+		r.queueWhenFull = c.Queue
+		r.mu.Unlock()
+		r.admitWaiting()
+	case eventRole:
+		r.mu.Lock()
+		r.setRole(c.Username, c.Role)
+		r.mu.Unlock()
+	case eventMute:
+		r.mu.Lock()
+		r.setMuted(c.Username, c.Muted)
+		r.mu.Unlock()
+	case eventBan:
+		r.ban(c.Username, ban{until: c.Until, reason: c.Value})
+	case eventUnban:
+		r.mu.Lock()
+		delete(r.bans, c.Username)
+		r.mu.Unlock()
+	case eventInvite:
+		if c.Invite == nil {
+			return
+		}
+		inv := *c.Invite
+		inv.uses = c.InviteUses
+		r.mu.Lock()
+		r.invites[inv.Code] = &inv
+		r.mu.Unlock()
+	case eventMember:
+		r.mu.Lock()
+		if inv, ok := r.invites[c.Key]; ok && inv.uses < c.InviteUses {
+			inv.uses = c.InviteUses
+			r.pruneInvites(time.Now())
+		}
+		r.members[c.Username] = true
+		r.mu.Unlock()
+	case eventReadMarker:
+		r.applyReadMarker(c.Username, c.ReadUpTo, c.Share)
+	default:
+		r.rm.log.Warnf("Unknown change %q in room %s", kind, r.name)
+	}
+}
+
+// announceRoom publishes the room created on the replica to the others.
+func (rm *RoomManager) announceRoom(r *room) {
+	snapshot, err := proto.Marshal(r.state())
+	if err != nil {
+		rm.log.Errorf("Marshal from room %s failed: %v", r.name, err)
+
+		return
+	}
+	payload, err := json.Marshal(roomCreated{Node: rm.node, Snapshot: snapshot})
+	if err == nil {
+		err = rm.backplane.Publish(roomsTopic, payload)
+	}
+	if err != nil {
+		rm.log.Warnf("Publishing room %s failed: %v", r.name, err)
+	}
+}
+
+// receiveRoom creates the room created by another replica, unless the
+// replica has a room with the same name.
+func (rm *RoomManager) receiveRoom(payload []byte) {
+	var ev roomCreated
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		rm.log.Errorf("Malformed room: %v", err)
+
+		return
+	}
+	if ev.Node == rm.node {
+		return
+	}
+	var snap pb.RoomSnapshot
+	if err := proto.Unmarshal(ev.Snapshot, &snap); err != nil || snap.Info == nil {
+		rm.log.Errorf("Malformed room snapshot: %v", err)
+
+		return
+	}
+	if _, ok := rm.FindRoom(snap.Info.Name); ok {
+		return
+	}
+	r, err := rm.createRoom(snap.Info.Name, snapshotOptions(&snap)...)
+	if errors.Is(err, ErrRoomExists) {
+		return
+	}
+	if err != nil {
+		rm.log.Errorf("Creating room %s of replica %s failed: %v", snap.Info.Name, ev.Node, err)
+
+		return
+	}
+	r.restoreState(&snap)
+	rm.log.Infof("Created room %s of replica %s", r.name, ev.Node)
+}
+
+// enqueue queues f, run by the event loop of the manager. It never blocks
+// the backplane.
+func (rm *RoomManager) enqueue(topic string, f func()) {
+	select {
+	case rm.events <- f:
+	default:
+		rm.log.Warnf("Event queue full, dropping a message of %s", topic)
+	}
+}
+
+// eventLoop runs the work received from the backplane on the topics of the
+// manager, until the manager is closed.
+func (rm *RoomManager) eventLoop() {
+	for {
+		select {
+		case <-rm.done:
+			return
+		case f := <-rm.events:
+			f()
+		}
+	}
+}
+
+// publishPresence publishes the online users of the replica.
+func (rm *RoomManager) publishPresence(leaving bool) {
+	ev := presenceEvent{Node: rm.node, Leaving: leaving}
+	if !leaving {
+		rm.onlineMu.Lock()
+		for username := range rm.online {
+			ev.Usernames = append(ev.Usernames, username)
+		}
+		rm.onlineMu.Unlock()
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		rm.log.Errorf("Marshal from presence failed: %v", err)
+
+		return
+	}
+	if err := rm.backplane.Publish(presenceTopic, payload); err != nil {
+		rm.log.Warnf("Publishing the presence failed: %v", err)
+	}
+}
+
+func (rm *RoomManager) receivePresence(payload []byte) {
+	var ev presenceEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		rm.log.Errorf("Malformed presence: %v", err)
+
+		return
+	}
+	if ev.Node == rm.node {
+		return
+	}
+
+	rm.onlineMu.Lock()
+	defer rm.onlineMu.Unlock()
+	if ev.Leaving {
+		delete(rm.remote, ev.Node)
+
+		return
+	}
+	usernames := make(map[string]bool, len(ev.Usernames))
+	for _, username := range ev.Usernames {
+		usernames[username] = true
+	}
+	rm.remote[ev.Node] = &remotePresence{usernames: usernames, seenAt: time.Now()}
+}
+
+// presenceLoop publishes the online users of the replica periodically, and
+// forgets the replicas not heard of anymore.
+func (rm *RoomManager) presenceLoop() {
+	t := time.NewTicker(presenceInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-rm.done:
+			return
+		case now := <-t.C:
+			rm.publishPresence(false)
+			rm.onlineMu.Lock()
+			for node, rp := range rm.remote {
+				if now.Sub(rp.seenAt) > presenceExpiry {
+					delete(rm.remote, node)
+				}
+			}
+			rm.onlineMu.Unlock()
+		}
+	}
+}
+
+// remoteNode returns the replica username is connected to, the one heard of
+// last when several, or "" when none.
+func (rm *RoomManager) remoteNode(username string) string {
+	rm.onlineMu.Lock()
+	defer rm.onlineMu.Unlock()
+
+	var node string
+	var seenAt time.Time
+	for n, rp := range rm.remote {
+		if rp.usernames[username] && rp.seenAt.After(seenAt) && time.Since(rp.seenAt) <= presenceExpiry {
+			node, seenAt = n, rp.seenAt
+		}
+	}
+
+	return node
+}
+
+// forwardMail forwards item to the replica its recipient is connected to,
+// reporting whether it was.
+func (rm *RoomManager) forwardMail(item store.MailItem) bool {
+	node := rm.remoteNode(item.Recipient)
+	if node == "" {
+		return false
+	}
+	payload, err := json.Marshal(item)
+	if err == nil {
+		err = rm.backplane.Publish(nodeTopic(node), payload)
+	}
+	if err != nil {
+		rm.log.Warnf("Forwarding mail for %s failed: %v", item.Recipient, err)
+
+		return false
+	}
+
+	return true
+}
+
+// receiveMail delivers the mail forwarded by another replica, or keeps it in
+// the mailbox when its recipient left meanwhile.
+func (rm *RoomManager) receiveMail(payload []byte) {
+	var item store.MailItem
+	if err := json.Unmarshal(payload, &item); err != nil {
+		rm.log.Errorf("Malformed mail: %v", err)
+
+		return
+	}
+	if item.Kind == store.MailMention {
+		// The room and the message are the ones of the replica of the
+		// author: the message has another ID here.
+		item.MessageID = 0
+		if r, ok := rm.FindRoom(item.RoomName); ok {
+			item.RoomID = string(r.id)
+		}
+	}
+	var recipients []*Participant
+	for _, p := range rm.onlineParticipants(item.Recipient) {
+		if r := p.CurrentRoom(); item.Kind == store.MailMention && r != nil && r.name == item.RoomName {
+			return
+		}
+		recipients = append(recipients, p)
+	}
+	if len(recipients) == 0 {
+		if _, err := rm.store.AppendMail(item, rm.mailbox.MaxItems); err != nil {
+			rm.log.Errorf("Storing mail for %s failed: %v", item.Recipient, err)
+		}
+
+		return
+	}
+	sMsgP, err := newMailMessage(item, false)
+	if err != nil {
+		rm.log.Errorf("Marshal from mail item failed: %v", err)
+
+		return
+	}
+	for _, p := range recipients {
+		p.Send(sMsgP)
+	}
+}
+
+// subscribe subscribes the manager to the presence, the rooms and the mail
+// of the replicas. The rooms and the mail are handled by the event loop, as
+// creating a room subscribes to the backplane and delivering the mail waits
+// for its recipients.
+func (rm *RoomManager) subscribe() error {
+	for topic, h := range map[string]func([]byte){
+		presenceTopic: rm.receivePresence,
+		roomsTopic: func(payload []byte) {
+			rm.enqueue(roomsTopic, func() { rm.receiveRoom(payload) })
+		},
+		nodeTopic(rm.node): func(payload []byte) {
+			rm.enqueue(nodeTopic(rm.node), func() { rm.receiveMail(payload) })
+		},
+	} {
+		unsubscribe, err := rm.backplane.Subscribe(topic, h)
+		if err != nil {
+			return fmt.Errorf("backplane subscription failed: %w", err)
+		}
+		rm.unsubscribe = append(rm.unsubscribe, unsubscribe)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// eventually reports whether cond holds within a second.
+func eventually(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestReplicas(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Change changes the room of the first replica, Check checks it on
+		// the second.
+		Change func(t *testing.T, r *room)
+		Check  func(r *room) bool
+	}{
+		{
+			Name: "topic",
+			Change: func(t *testing.T, r *room) {
+				if err := r.SetTopic(nil, "news"); err != nil {
+					t.Fatalf("SetTopic failed: %v", err)
+				}
+			},
+			Check: func(r *room) bool {
+				return r.Info().Topic == "news"
+			},
+		},
+		{
+			Name: "mode",
+			Change: func(t *testing.T, r *room) {
+				if err := r.SetMode(nil, pb.RoomMode_ReadOnly, 0); err != nil {
+					t.Fatalf("SetMode failed: %v", err)
+				}
+			},
+			Check: func(r *room) bool {
+				mode, _ := r.Mode()
+
+				return mode == pb.RoomMode_ReadOnly
+			},
+		},
+		{
+			Name: "role",
+			Change: func(t *testing.T, r *room) {
+				if err := r.SetRole(nil, "bob", pb.Role_Moderator); err != nil {
+					t.Fatalf("SetRole failed: %v", err)
+				}
+			},
+			Check: func(r *room) bool {
+				return r.Role("bob") == pb.Role_Moderator
+			},
+		},
+		{
+			Name: "mute",
+			Change: func(t *testing.T, r *room) {
+				if err := r.Mute(nil, "bob", true); err != nil {
+					t.Fatalf("Mute failed: %v", err)
+				}
+			},
+			Check: func(r *room) bool {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+
+				return r.muted["bob"]
+			},
+		},
+		{
+			Name: "ban",
+			Change: func(t *testing.T, r *room) {
+				if err := r.Ban(nil, "bob", 0, "spam"); err != nil {
+					t.Fatalf("Ban failed: %v", err)
+				}
+			},
+			Check: func(r *room) bool {
+				bob, _ := NewParticipant("bob", Limits{})
+				defer bob.Close()
+
+				return errors.Is(bob.JoinRoom(r, ""), ErrBanned)
+			},
+		},
+		{
+			Name: "read marker",
+			Change: func(t *testing.T, r *room) {
+				if err := r.publish(roomEvent{Node: r.rm.node, Author: "alice", Body: "hi", SentAt: time.Now()}); err != nil {
+					t.Fatalf("publish failed: %v", err)
+				}
+				delivered := func() bool {
+					n, _ := r.Unread("bob")
+
+					return n == 1
+				}
+				if !eventually(delivered) {
+					t.Fatal("message not delivered")
+				}
+				bob, _ := NewParticipant("bob", Limits{})
+				if _, err := r.MarkRead(bob, 0, false); err != nil {
+					t.Fatalf("MarkRead failed: %v", err)
+				}
+			},
+			Check: func(r *room) bool {
+				n, err := r.Unread("bob")
+
+				return err == nil && n == 0
+			},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			bp := backplane.NewLocal()
+			replicas := make([]*RoomManager, 2)
+			for i := range replicas {
+				rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), bp)
+				if err != nil {
+					t.Fatalf("NewRoomManager failed: %v", err)
+				}
+				defer rm.Close()
+				replicas[i] = rm
+			}
+			id, err := replicas[0].CreateRoom("test")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			var replicated *room
+			created := func() bool {
+				var ok bool
+				replicated, ok = replicas[1].FindRoom("test")
+
+				return ok
+			}
+			if !eventually(created) {
+				t.Fatal("room not created on the other replica")
+			}
+			r, _ := replicas[0].GetRoom(id)
+
+			tt.Change(t, r)
+			if !eventually(func() bool { return tt.Check(replicated) }) {
+				t.Error("change not applied on the other replica")
+			}
+		})
+	}
+}
+
+func TestReplicatedRoom(t *testing.T) {
+	bp := backplane.NewLocal()
+	a, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), bp)
+	if err != nil {
+		t.Fatalf("NewRoomManager failed: %v", err)
+	}
+	defer a.Close()
+	b, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), bp)
+	if err != nil {
+		t.Fatalf("NewRoomManager failed: %v", err)
+	}
+	defer b.Close()
+
+	if _, err := a.CreateRoom("vip", WithVisibility(pb.RoomVisibility_Private), WithOwner("alice"), WithTopic("members only")); err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	var r *room
+	created := func() bool {
+		var ok bool
+		r, ok = b.FindRoom("vip")
+
+		return ok
+	}
+	if !eventually(created) {
+		t.Fatal("room not created on the other replica")
+	}
+	info := r.Info()
+	if info.Visibility != pb.RoomVisibility_Private || info.Topic != "members only" {
+		t.Errorf("got room %v, want the private room created", info)
+	}
+	if role := r.Role("alice"); role != pb.Role_Owner {
+		t.Errorf("alice is %s, want owner", role)
+	}
+}
+
+func TestReceiveMailNotBlocking(t *testing.T) {
+	bp := backplane.NewLocal()
+	rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), bp)
+	if err != nil {
+		t.Fatalf("NewRoomManager failed: %v", err)
+	}
+	defer rm.Close()
+	// bob does not read the messages.
+	bob, _ := NewParticipant("bob", Limits{})
+	defer bob.Close()
+	if err := rm.Connect(bob); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2*outBuffer; i++ {
+			payload, _ := json.Marshal(store.MailItem{Kind: store.MailDirectMessage, Recipient: "bob", From: "alice", Body: "hi", SentAt: time.Now()})
+			if err := bp.Publish(nodeTopic(rm.node), payload); err != nil {
+				t.Errorf("Publish failed: %v", err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the backplane is blocked by a recipient not reading")
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// WithCapacity limits the room to capacity participants. When queue is true,
+// the participants joining the full room wait in a FIFO queue and are
+// admitted as soon as somebody leaves, otherwise they are rejected.
+func WithCapacity(capacity int, queue bool) RoomOption {
+	return func(r *room) {
+		r.capacity = capacity
+		r.queueWhenFull = queue
+	}
+}
+
+// SetCapacity changes the capacity of the room and whether the
+// participants joining it when full wait for a seat, on behalf of the server
+// operators. The participants beyond a smaller capacity stay, and the
+// waiting ones are admitted while the room has room for them.
+func (r *room) SetCapacity(capacity int, queue bool) error {
+	if capacity < 0 {
+		return fmt.Errorf("%w: negative capacity", ErrInvalidArgument)
+	}
+	r.mu.Lock()
+	r.capacity = capacity
+	r.queueWhenFull = queue
+	r.mu.Unlock()
+
+	r.publishChange(eventCapacity, roomChange{Capacity: capacity, Queue: queue})
+	r.admitWaiting()
+
+	return nil
+}
+
+// full reports whether the room reached its capacity. The caller must hold
+// r.mu.
+func (r *room) full() bool {
+	return r.capacity > 0 && len(r.participants) >= r.capacity
+}
+
+// enqueue appends p to the waiting queue, unless it is already waiting. The
+// users who kept their place from the node the room was handed over from
+// are queued ahead of the others, in order. The caller must hold r.mu and
+// p.mu.
+func (r *room) enqueue(p *Participant) {
+	p.queuedIn = r
+	for _, w := range r.waiting {
+		if w == p {
+			return
+		}
+	}
+	pos, ok := r.queuePositions[p.username]
+	if !ok {
+		r.waiting = append(r.waiting, p)
+
+		return
+	}
+	i := 0
+	for ; i < len(r.waiting); i++ {
+		if wPos, ok := r.queuePositions[r.waiting[i].username]; !ok || wPos > pos {
+			break
+		}
+	}
+	r.waiting = append(r.waiting, nil)
+	copy(r.waiting[i+1:], r.waiting[i:])
+	r.waiting[i] = p
+}
+
+// dequeue removes p from the waiting queue.
+func (r *room) dequeue(p *Participant) {
+	r.mu.Lock()
+	removed := false
+	for i, w := range r.waiting {
+		if w == p {
+			r.waiting = append(r.waiting[:i], r.waiting[i+1:]...)
+			delete(r.queuePositions, p.username)
+			removed = true
+
+			break
+		}
+	}
+	r.mu.Unlock()
+	p.leaveQueue(r)
+
+	if removed {
+		r.notifyQueue()
+	}
+}
+
+// admitWaiting admits the waiting participants while the room has room for
+// them, moving them from their current room.
+func (r *room) admitWaiting() {
+	type admission struct {
+		p    *Participant
+		prev *room
+	}
+	var admitted []admission
+	var joined []string
+	r.mu.Lock()
+	for !r.closed && len(r.waiting) > 0 && !r.full() {
+		p := r.waiting[0]
+		r.waiting = r.waiting[1:]
+		delete(r.queuePositions, p.username)
+		p.mu.Lock()
+		// The participant may have closed or moved to another queue since
+		// it was queued.
+		if p.closed || p.queuedIn != r {
+			p.mu.Unlock()
+
+			continue
+		}
+		p.queuedIn = nil
+		if err := r.checkBan(p.username); err != nil {
+			p.mu.Unlock()
+
+			continue
+		}
+		admitted = append(admitted, admission{p: p, prev: p.room})
+		p.room = r
+		p.mu.Unlock()
+		r.participants[p.id] = p
+		r.emptySince = time.Time{}
+		if !r.members[p.username] {
+			r.members[p.username] = true
+			joined = append(joined, p.username)
+		}
+	}
+	r.mu.Unlock()
+	if len(admitted) == 0 {
+		return
+	}
+
+	for _, username := range joined {
+		r.publishChange(eventMember, roomChange{Username: username})
+	}
+	for _, a := range admitted {
+		r.rm.log.Debugf("Participant %s admitted to room %s from the queue", a.p.id, r.name)
+		if a.prev != nil {
+			a.prev.removeParticipant(a.p.id)
+		}
+		a.p.ConfirmRoomCheckout()
+	}
+	r.notifyQueue()
+}
+
+// notifyQueue sends their position to the waiting participants.
+func (r *room) notifyQueue() {
+	r.mu.Lock()
+	waiting := make([]*Participant, len(r.waiting))
+	copy(waiting, r.waiting)
+	r.mu.Unlock()
+	if len(waiting) == 0 {
+		return
+	}
+
+	info := r.Info()
+	for i, p := range waiting {
+		queuePositionMsg := pb.ServerMessage_ServerQueuePosition{
+			Room:     info,
+			Position: uint32(i + 1),
+		}
+		op, err := pbutils.MarshalAny(&queuePositionMsg)
+		if err != nil {
+			r.rm.log.Errorf("Marshal from queuePosition failed: %v", err)
+
+			return
+		}
+		p.Send(&pb.ServerMessage{
+			Command:   pb.ServerMessage_QueuePosition,
+			Operation: op,
+		})
+	}
+}
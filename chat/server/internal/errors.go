@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+var (
+	ErrRoomNotFound        = errors.New("room not found")
+	ErrParticipantNotFound = errors.New("participant not found")
+	ErrRoomExists          = errors.New("room already exists")
+	ErrInvalidArgument     = errors.New("invalid argument")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrBanned              = errors.New("banned")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrRoomFull            = errors.New("room full")
+	ErrRoomClosed          = errors.New("room closed")
+	// ErrQueued is returned when a participant is queued to join a full
+	// room.
+	ErrQueued = errors.New("queued")
+)
+
+// NewErrorMessage builds the ServerMessage reporting err, caused by cmd, to
+// the client.
+func NewErrorMessage(cmd pb.ClientMessage_ClientCommand, err error) (*pb.ServerMessage, error) {
+	errorMsg := pb.ServerMessage_ServerError{
+		Code:    errorCode(err),
+		Message: err.Error(),
+		Command: cmd,
+	}
+	op, err := pbutils.MarshalAny(&errorMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ServerMessage{
+		Command:   pb.ServerMessage_Error,
+		Operation: op,
+	}, nil
+}
+
+func errorCode(err error) pb.ServerMessage_ServerError_Code {
+	switch {
+	case errors.Is(err, ErrInvalidArgument), errors.Is(err, ErrRoomExists):
+		return pb.ServerMessage_ServerError_InvalidArgument
+	case errors.Is(err, ErrRoomNotFound), errors.Is(err, ErrRoomClosed), errors.Is(err, ErrParticipantNotFound):
+		return pb.ServerMessage_ServerError_NotFound
+	case errors.Is(err, ErrPermissionDenied):
+		return pb.ServerMessage_ServerError_PermissionDenied
+	case errors.Is(err, ErrBanned):
+		return pb.ServerMessage_ServerError_Banned
+	case errors.Is(err, ErrRateLimited):
+		return pb.ServerMessage_ServerError_RateLimited
+	case errors.Is(err, ErrRoomFull):
+		return pb.ServerMessage_ServerError_RoomFull
+	default:
+		return pb.ServerMessage_ServerError_Internal
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// RelayedMessage is a message of a room exchanged with the federated
+// servers.
+type RelayedMessage struct {
+	// Room is the name of the room on the server handling the message.
+	Room   string
+	Author string
+	Body   string
+	SentAt time.Time
+	// Via lists the servers the message crossed, its origin first. It is
+	// empty for the messages written on the server.
+	Via []string
+}
+
+// SetRelay registers f to relay the messages written in the rooms, or
+// received from federated servers, to the other federated servers. It must
+// be called before the manager is used.
+func (rm *RoomManager) SetRelay(f func(RelayedMessage)) {
+	rm.relayFunc = f
+}
+
+func (rm *RoomManager) relay(r *room, msg store.Message, via []string) {
+	if rm.relayFunc == nil {
+		return
+	}
+	rm.relayFunc(RelayedMessage{
+		Room:   r.name,
+		Author: msg.Author,
+		Body:   msg.Body,
+		SentAt: msg.SentAt,
+		Via:    via,
+	})
+}
+
+// Inject writes a message relayed by a federated server in its room, as
+// written by its author: the bans, the mutes and the mode of the room apply
+// to the author, and the message counts towards the limits of the room.
+func (rm *RoomManager) Inject(m RelayedMessage) error {
+	if len(m.Via) == 0 {
+		return fmt.Errorf("%w: relayed message without origin", ErrInvalidArgument)
+	}
+	r, ok := rm.FindRoom(m.Room)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, m.Room)
+	}
+	now := time.Now()
+	if err := r.checkRelayed(m.Author, len(m.Body), now); err != nil {
+		return err
+	}
+
+	err := r.publish(roomEvent{
+		Node:   rm.node,
+		Author: m.Author,
+		Body:   m.Body,
+		SentAt: m.SentAt,
+		Via:    m.Via,
+	})
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.lastWrites[m.Author] = now
+	r.mu.Unlock()
+
+	return nil
+}
+
+// checkRelayed returns an error when author, a user of a federated server,
+// cannot write a message of size bytes in the room at now.
+func (r *room) checkRelayed(author string, size int, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.frozen {
+		return fmt.Errorf("%w: room %s is moving to another node", ErrRoomClosed, r.name)
+	}
+	if err := r.checkBan(author); err != nil {
+		return err
+	}
+	if r.muted[author] {
+		return fmt.Errorf("%w: %s is muted in room %s", ErrPermissionDenied, author, r.name)
+	}
+	if err := r.checkMode(author, r.roles[author], now); err != nil {
+		return err
+	}
+	if r.limits.MaxMessageBytes > 0 && size > r.limits.MaxMessageBytes {
+		return fmt.Errorf("%w: messages cannot be longer than %d bytes", ErrInvalidArgument, r.limits.MaxMessageBytes)
+	}
+	if !r.messagesBucket.allow(now, 1) {
+		return fmt.Errorf("%w: room %s is too busy", ErrRateLimited, r.name)
+	}
+
+	return nil
+}
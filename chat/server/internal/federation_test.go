@@ -0,0 +1,123 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestRelay(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Setup prepares the room before the message is written.
+		Setup func(r *room)
+		// Injected is nil for a message written on the server.
+		Injected  *RelayedMessage
+		WantErr   error
+		WantRelay []RelayedMessage
+	}{
+		{
+			Name:      "local message",
+			WantRelay: []RelayedMessage{{Room: "lobby", Author: "alice", Body: "hi"}},
+		},
+		{
+			Name:      "relayed message",
+			Injected:  &RelayedMessage{Room: "lobby", Author: "bob@b", Body: "hi", Via: []string{"b"}},
+			WantRelay: []RelayedMessage{{Room: "lobby", Author: "bob@b", Body: "hi", Via: []string{"b"}}},
+		},
+		{
+			Name:     "without origin",
+			Injected: &RelayedMessage{Room: "lobby", Author: "bob@b", Body: "hi"},
+			WantErr:  ErrInvalidArgument,
+		},
+		{
+			Name:     "unknown room",
+			Injected: &RelayedMessage{Room: "attic", Author: "bob@b", Body: "hi", Via: []string{"b"}},
+			WantErr:  ErrRoomNotFound,
+		},
+		{
+			Name: "banned author",
+			Setup: func(r *room) {
+				r.Ban(nil, "bob@b", 0, "spam")
+			},
+			Injected: &RelayedMessage{Room: "lobby", Author: "bob@b", Body: "hi", Via: []string{"b"}},
+			WantErr:  ErrBanned,
+		},
+		{
+			Name: "muted author",
+			Setup: func(r *room) {
+				r.Mute(nil, "bob@b", true)
+			},
+			Injected: &RelayedMessage{Room: "lobby", Author: "bob@b", Body: "hi", Via: []string{"b"}},
+			WantErr:  ErrPermissionDenied,
+		},
+		{
+			Name: "read-only room",
+			Setup: func(r *room) {
+				r.SetMode(nil, pb.RoomMode_ReadOnly, 0)
+			},
+			Injected: &RelayedMessage{Room: "lobby", Author: "bob@b", Body: "hi", Via: []string{"b"}},
+			WantErr:  ErrPermissionDenied,
+		},
+		{
+			Name: "busy room",
+			Setup: func(r *room) {
+				r.mu.Lock()
+				r.messagesBucket = newTokenBucket(0.001, 1)
+				r.messagesBucket.allow(time.Now(), 1)
+				r.mu.Unlock()
+			},
+			Injected: &RelayedMessage{Room: "lobby", Author: "bob@b", Body: "hi", Via: []string{"b"}},
+			WantErr:  ErrRateLimited,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			relayedC := make(chan RelayedMessage, 1)
+			rm.SetRelay(func(m RelayedMessage) {
+				m.SentAt = time.Time{}
+				relayedC <- m
+			})
+			id, err := rm.CreateRoom("lobby")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			if tt.Setup != nil {
+				tt.Setup(r)
+			}
+
+			if tt.Injected == nil {
+				err = r.publish(roomEvent{Node: rm.node, Author: "alice", Body: "hi", SentAt: time.Now()})
+			} else {
+				err = rm.Inject(*tt.Injected)
+			}
+			if !errors.Is(err, tt.WantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			// The messages are relayed by the room goroutine.
+			var relayed []RelayedMessage
+			for range tt.WantRelay {
+				select {
+				case m := <-relayedC:
+					relayed = append(relayed, m)
+				case <-time.After(time.Second):
+				}
+			}
+			if fmt.Sprint(relayed) != fmt.Sprint(tt.WantRelay) {
+				t.Errorf("relayed %v, want %v", relayed, tt.WantRelay)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	maxHistoryPageSize     = 200
+)
+
+// History returns the page of the history of the room selected by req,
+// which username must be able to see.
+func (r *room) History(username string, req *pb.GetHistoryRequest) (*pb.GetHistoryResponse, error) {
+	// Private rooms are reported as missing to the users who cannot see
+	// them.
+	if !r.VisibleTo(username) {
+		return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, r.name)
+	}
+
+	q := store.HistoryQuery{Limit: int(req.PageSize)}
+	if q.Limit == 0 {
+		q.Limit = defaultHistoryPageSize
+	}
+	if q.Limit > maxHistoryPageSize {
+		q.Limit = maxHistoryPageSize
+	}
+	switch anchor := req.Anchor.(type) {
+	case *pb.GetHistoryRequest_BeforeId:
+		q.BeforeID = store.MessageID(anchor.BeforeId)
+	case *pb.GetHistoryRequest_AfterId:
+		q.AfterID = store.MessageID(anchor.AfterId)
+	case *pb.GetHistoryRequest_Before:
+		q.Before = anchor.Before.AsTime()
+	case *pb.GetHistoryRequest_After:
+		q.After = anchor.After.AsTime()
+	case *pb.GetHistoryRequest_Cursor:
+		if err := parseHistoryCursor(anchor.Cursor, &q); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, more, err := r.rm.store.History(string(r.id), q)
+	if err != nil {
+		return nil, fmt.Errorf("store failed: %w", err)
+	}
+	res := &pb.GetHistoryResponse{}
+	for _, m := range messages {
+		res.Messages = append(res.Messages, storedMessage(m, r.name))
+	}
+	if more {
+		if q.Forward() {
+			res.NextCursor = fmt.Sprintf("after:%d", messages[len(messages)-1].ID)
+		} else {
+			res.NextCursor = fmt.Sprintf("before:%d", messages[0].ID)
+		}
+	}
+
+	return res, nil
+}
+
+// parseHistoryCursor sets the bound of q encoded in cursor by History.
+func parseHistoryCursor(cursor string, q *store.HistoryQuery) error {
+	i := strings.Index(cursor, ":")
+	if i < 0 {
+		return fmt.Errorf("%w: invalid cursor", ErrInvalidArgument)
+	}
+	id, err := strconv.ParseUint(cursor[i+1:], 10, 64)
+	if err != nil || id == 0 {
+		return fmt.Errorf("%w: invalid cursor", ErrInvalidArgument)
+	}
+	switch cursor[:i] {
+	case "before":
+		q.BeforeID = store.MessageID(id)
+	case "after":
+		q.AfterID = store.MessageID(id)
+	default:
+		return fmt.Errorf("%w: invalid cursor", ErrInvalidArgument)
+	}
+
+	return nil
+}
+
+func storedMessage(m store.Message, roomName string) *pb.StoredMessage {
+	return &pb.StoredMessage{
+		Id:       uint64(m.ID),
+		RoomId:   m.RoomID,
+		RoomName: roomName,
+		Author:   m.Author,
+		Body:     m.Body,
+		SentAt:   timestamppb.New(m.SentAt),
+	}
+}
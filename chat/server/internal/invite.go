@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// DefaultInviteTTL is the lifetime of the invites created without one.
+const DefaultInviteTTL = 24 * time.Hour
+
+var inviteEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type Invite struct {
+	Code      string
+	CreatedBy string
+	ExpiresAt time.Time
+	// MaxUses is zero for invites with unlimited uses.
+	MaxUses int
+	uses    int
+}
+
+func (i *Invite) valid(now time.Time) bool {
+	return now.Before(i.ExpiresAt) && (i.MaxUses == 0 || i.uses < i.MaxUses)
+}
+
+// CreateInvite creates an invite code to the room, valid for ttl and maxUses
+// times, or unlimited times when maxUses is zero. Only owners can create
+// invites. A nil actor stands for the server operators, who are always
+// allowed.
+func (r *room) CreateInvite(actor *Participant, maxUses int, ttl time.Duration) (Invite, error) {
+	if maxUses < 0 {
+		return Invite{}, fmt.Errorf("%w: negative invite uses", ErrInvalidArgument)
+	}
+	if ttl < 0 {
+		return Invite{}, fmt.Errorf("%w: negative invite ttl", ErrInvalidArgument)
+	}
+	if ttl == 0 {
+		ttl = DefaultInviteTTL
+	}
+	code, err := newInviteCode()
+	if err != nil {
+		return Invite{}, err
+	}
+
+	inv := &Invite{
+		Code:      code,
+		ExpiresAt: time.Now().Add(ttl),
+		MaxUses:   maxUses,
+	}
+	r.mu.Lock()
+	if actor != nil {
+		if r.roles[actor.username] != pb.Role_Owner {
+			r.mu.Unlock()
+
+			return Invite{}, fmt.Errorf("%w: only owners can invite to room %s", ErrPermissionDenied, r.name)
+		}
+		inv.CreatedBy = actor.username
+	}
+	r.pruneInvites(time.Now())
+	r.invites[code] = inv
+	r.mu.Unlock()
+	r.publishChange(eventInvite, roomChange{Invite: inv})
+
+	return *inv, nil
+}
+
+// hasInvite reports whether code is a valid invite to the room.
+func (r *room) hasInvite(code string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.validInvite(code)
+}
+
+// validInvite reports whether the invite code can be used. The caller must
+// hold r.mu.
+func (r *room) validInvite(code string) bool {
+	inv, ok := r.invites[normalizeInviteCode(code)]
+
+	return ok && inv.valid(time.Now())
+}
+
+// redeemInvite uses the invite code once, returning the change to publish.
+// The caller must hold r.mu.
+func (r *room) redeemInvite(code string) (roomChange, error) {
+	code = normalizeInviteCode(code)
+	inv, ok := r.invites[code]
+	if !ok || !inv.valid(time.Now()) {
+		delete(r.invites, code)
+
+		return roomChange{}, fmt.Errorf("%w: invalid or expired invite to room %s", ErrPermissionDenied, r.name)
+	}
+	inv.uses++
+	if !inv.valid(time.Now()) {
+		delete(r.invites, code)
+	}
+
+	return roomChange{Key: code, InviteUses: inv.uses}, nil
+}
+
+// pruneInvites removes the expired invites. The caller must hold r.mu.
+func (r *room) pruneInvites(now time.Time) {
+	for code, inv := range r.invites {
+		if !inv.valid(now) {
+			delete(r.invites, code)
+		}
+	}
+}
+
+func newInviteCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("invite code generation failed: %w", err)
+	}
+
+	return inviteEncoding.EncodeToString(b), nil
+}
+
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
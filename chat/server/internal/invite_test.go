@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestInviteToFullRoom(t *testing.T) {
+	testsTable := []struct {
+		Name        string
+		Queue       bool
+		WantErr     error
+		WantInvite  bool
+		WantMember  bool
+		WantWaiting uint32
+	}{
+		{
+			Name:       "rejected",
+			WantErr:    ErrRoomFull,
+			WantInvite: true,
+		},
+		{
+			Name:        "queued",
+			Queue:       true,
+			WantErr:     ErrQueued,
+			WantMember:  true,
+			WantWaiting: 1,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			id, err := rm.CreateRoom("vip", WithVisibility(pb.RoomVisibility_Private), WithCapacity(1, tt.Queue))
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			inv, err := r.CreateInvite(nil, 1, 0)
+			if err != nil {
+				t.Fatalf("CreateInvite failed: %v", err)
+			}
+			r.mu.Lock()
+			r.members["alice"] = true
+			r.mu.Unlock()
+			alice, _ := NewParticipant("alice", Limits{})
+			defer testSession(alice)()
+			if err := alice.JoinRoom(r, ""); err != nil {
+				t.Fatalf("JoinRoom failed: %v", err)
+			}
+
+			bob, _ := NewParticipant("bob", Limits{})
+			defer testSession(bob)()
+			if err := bob.JoinRoom(r, inv.Code); !errors.Is(err, tt.WantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			if got := r.hasInvite(inv.Code); got != tt.WantInvite {
+				t.Errorf("invite valid %t, want %t", got, tt.WantInvite)
+			}
+			r.mu.Lock()
+			member := r.members["bob"]
+			r.mu.Unlock()
+			if member != tt.WantMember {
+				t.Errorf("member %t, want %t", member, tt.WantMember)
+			}
+			if got := r.Info().GetWaiting(); got != tt.WantWaiting {
+				t.Errorf("got %d waiting, want %d", got, tt.WantWaiting)
+			}
+		})
+	}
+}
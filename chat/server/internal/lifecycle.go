@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// Lifecycle configures how the RoomManager cleans the rooms up.
+type Lifecycle struct {
+	// EmptyTTL is how long an ephemeral room is kept while empty, unless the
+	// room sets its own.
+	EmptyTTL time.Duration
+	// ArchiveAfter is how long a room can go without participants nor
+	// messages before being archived to the history store. Zero disables
+	// the archival.
+	ArchiveAfter time.Duration
+	// SweepInterval is how often the rooms are checked. Zero disables the
+	// cleanup.
+	SweepInterval time.Duration
+	// Retention is the retention of the history of the rooms not setting
+	// their own.
+	Retention store.Retention
+	// CompactInterval is how often the retention of the history is
+	// enforced. Zero disables the compaction.
+	CompactInterval time.Duration
+}
+
+func DefaultLifecycle() Lifecycle {
+	return Lifecycle{
+		EmptyTTL:        5 * time.Minute,
+		SweepInterval:   30 * time.Second,
+		CompactInterval: 10 * time.Minute,
+	}
+}
+
+// WithLifecycle makes the room persistent or ephemeral. Ephemeral rooms are
+// closed after being empty for emptyTTL, or for the default of the manager
+// when emptyTTL is zero.
+func WithLifecycle(lifecycle pb.RoomLifecycle, emptyTTL time.Duration) RoomOption {
+	return func(r *room) {
+		r.lifecycle = lifecycle
+		r.emptyTTL = emptyTTL
+	}
+}
+
+// WithPermanent exempts the room from the lifecycle policy, neither expiring
+// nor being archived.
+func WithPermanent() RoomOption {
+	return func(r *room) {
+		r.permanent = true
+		r.lifecycle = pb.RoomLifecycle_Persistent
+	}
+}
+
+// effectiveEmptyTTL returns the TTL of the room when ephemeral.
+func (r *room) effectiveEmptyTTL() time.Duration {
+	if r.emptyTTL > 0 {
+		return r.emptyTTL
+	}
+
+	return r.rm.lifecycle.EmptyTTL
+}
+
+// expired reports whether the room is ephemeral and has been empty for its
+// TTL at now.
+func (r *room) expired(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.permanent || r.lifecycle != pb.RoomLifecycle_Ephemeral {
+		return false
+	}
+	if len(r.participants) > 0 || len(r.waiting) > 0 || r.emptySince.IsZero() {
+		return false
+	}
+
+	return now.Sub(r.emptySince) >= r.effectiveEmptyTTL()
+}
+
+// idle reports whether the room had neither participants nor messages for
+// at least d at now.
+func (r *room) idle(now time.Time, d time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.permanent || len(r.participants) > 0 || len(r.waiting) > 0 {
+		return false
+	}
+	since := r.lastActivity
+	if r.emptySince.After(since) {
+		since = r.emptySince
+	}
+
+	return now.Sub(since) >= d
+}
+
+// archivedRoom returns the record of the room archived at now.
+func (r *room) archivedRoom(now time.Time) store.ArchivedRoom {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	labels := make(map[string]string, len(r.labels))
+	for k, v := range r.labels {
+		labels[k] = v
+	}
+
+	return store.ArchivedRoom{
+		ID:          string(r.id),
+		Name:        r.name,
+		Topic:       r.topic,
+		Description: r.description,
+		Creator:     r.creator,
+		Labels:      labels,
+		CreatedAt:   r.createdAt,
+		ArchivedAt:  now,
+	}
+}
+
+func (rm *RoomManager) sweepLoop(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-rm.done:
+			return
+		case now := <-t.C:
+			rm.sweep(now)
+		}
+	}
+}
+
+// sweep closes the expired ephemeral rooms, archives the idle ones and
+// prunes the mailboxes.
+func (rm *RoomManager) sweep(now time.Time) {
+	rm.pruneMail(now)
+	archiveAfter := rm.lifecycle.ArchiveAfter
+	for _, r := range rm.ListRooms() {
+		switch {
+		case r.expired(now):
+			rm.log.Infof("Closing ephemeral room %s, empty for %s", r.name, r.effectiveEmptyTTL())
+			r.close("room expired")
+		case archiveAfter > 0 && r.idle(now, archiveAfter):
+			if err := rm.archive(r, now); err != nil {
+				rm.log.Errorf("Archiving room %s failed: %v", r.name, err)
+			}
+		}
+	}
+}
+
+// archive records r in the history store and closes it.
+func (rm *RoomManager) archive(r *room, now time.Time) error {
+	if err := rm.store.ArchiveRoom(r.archivedRoom(now)); err != nil {
+		return fmt.Errorf("store failed: %w", err)
+	}
+	rm.log.Infof("Archiving room %s, idle for %s", r.name, rm.lifecycle.ArchiveAfter)
+	r.close(fmt.Sprintf("room archived after %s without participants nor messages", rm.lifecycle.ArchiveAfter))
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestSweep(t *testing.T) {
+	testsTable := []struct {
+		Name     string
+		Opts     []RoomOption
+		EmptyFor time.Duration
+		IdleFor  time.Duration
+		// Participant joins the room before the sweep.
+		Participant  bool
+		WantClosed   bool
+		WantArchived bool
+	}{
+		{
+			Name:       "ephemeral expired",
+			Opts:       []RoomOption{WithLifecycle(pb.RoomLifecycle_Ephemeral, 0)},
+			EmptyFor:   time.Minute,
+			WantClosed: true,
+		},
+		{
+			Name:     "ephemeral within its own ttl",
+			Opts:     []RoomOption{WithLifecycle(pb.RoomLifecycle_Ephemeral, time.Hour)},
+			EmptyFor: time.Minute,
+		},
+		{
+			Name:     "persistent empty",
+			EmptyFor: time.Hour,
+		},
+		{
+			Name:         "idle",
+			EmptyFor:     48 * time.Hour,
+			IdleFor:      48 * time.Hour,
+			WantClosed:   true,
+			WantArchived: true,
+		},
+		{
+			Name:        "idle with a participant",
+			IdleFor:     48 * time.Hour,
+			Participant: true,
+		},
+		{
+			Name:     "idle but empty recently",
+			EmptyFor: time.Hour,
+			IdleFor:  48 * time.Hour,
+		},
+		{
+			Name:    "permanent idle",
+			Opts:    []RoomOption{WithPermanent()},
+			IdleFor: 48 * time.Hour,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			st := store.NewMemoryStore()
+			rm, err := NewRoomManager(DefaultLimits(), Lifecycle{EmptyTTL: time.Second, ArchiveAfter: 24 * time.Hour}, Mailbox{}, st, backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			rID, err := rm.CreateRoom("test", tt.Opts...)
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(rID)
+			now := time.Now()
+			r.mu.Lock()
+			r.emptySince = now.Add(-tt.EmptyFor)
+			r.lastActivity = now.Add(-tt.IdleFor)
+			r.mu.Unlock()
+			if tt.Participant {
+				p, _ := NewParticipant("alice", Limits{})
+				defer testSession(p)()
+				if err := p.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+			}
+
+			rm.sweep(now)
+
+			if _, ok := rm.GetRoom(rID); ok == tt.WantClosed {
+				t.Errorf("room open=%t after sweep; want %t", ok, !tt.WantClosed)
+			}
+			archived, err := st.ArchivedRooms()
+			if err != nil {
+				t.Fatalf("ArchivedRooms failed: %v", err)
+			}
+			if got := len(archived) == 1; got != tt.WantArchived {
+				t.Errorf("archived=%t after sweep; want %t", got, tt.WantArchived)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// Mailbox configures the mailbox keeping the direct messages and mentions of
+// the users who are offline until they connect again.
+type Mailbox struct {
+	// Retention is how long an item is kept. Zero keeps the items until they
+	// are delivered.
+	Retention time.Duration
+	// MaxItems caps the items kept for each user, dropping the oldest. Zero
+	// disables the cap.
+	MaxItems int
+}
+
+func DefaultMailbox() Mailbox {
+	return Mailbox{
+		Retention: 7 * 24 * time.Hour,
+		MaxItems:  100,
+	}
+}
+
+// mentionRe matches the @username mentions in the body of a message.
+var mentionRe = regexp.MustCompile(`@(\w[\w-]*)`)
+
+// mentions returns the users mentioned in body, once each, in order.
+func mentions(body string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, m := range mentionRe.FindAllStringSubmatch(body, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			usernames = append(usernames, m[1])
+		}
+	}
+
+	return usernames
+}
+
+// Connect registers p as online: the direct messages and mentions for its
+// username are delivered to it instead of the mailbox until it is closed.
+func (rm *RoomManager) Connect(p *Participant) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return errParticipantClosed
+	}
+	p.rm = rm
+
+	rm.onlineMu.Lock()
+	if rm.online[p.username] == nil {
+		rm.online[p.username] = make(map[participantID]*Participant)
+	}
+	rm.online[p.username][p.id] = p
+	first := len(rm.online[p.username]) == 1
+	rm.onlineMu.Unlock()
+	p.mu.Unlock()
+
+	if first {
+		rm.publishPresence(false)
+	}
+
+	return nil
+}
+
+// disconnect unregisters p, registered by Connect.
+func (rm *RoomManager) disconnect(p *Participant) {
+	rm.onlineMu.Lock()
+	delete(rm.online[p.username], p.id)
+	last := len(rm.online[p.username]) == 0
+	if last {
+		delete(rm.online, p.username)
+	}
+	rm.onlineMu.Unlock()
+
+	if last {
+		rm.publishPresence(false)
+	}
+}
+
+// onlineParticipants returns the participants connected as username.
+func (rm *RoomManager) onlineParticipants(username string) []*Participant {
+	rm.onlineMu.Lock()
+	defer rm.onlineMu.Unlock()
+
+	participants := make([]*Participant, 0, len(rm.online[username]))
+	for _, p := range rm.online[username] {
+		participants = append(participants, p)
+	}
+
+	return participants
+}
+
+// DisconnectUser disconnects the participants of the user named username,
+// notifying reason, and returns them.
+func (rm *RoomManager) DisconnectUser(username, reason string) []*Participant {
+	participants := rm.onlineParticipants(username)
+	for _, p := range participants {
+		p.Disconnect(reason)
+	}
+
+	return participants
+}
+
+// SendDirectMessage sends body from p to the user named to, keeping it in
+// the mailbox of the user when offline.
+func (rm *RoomManager) SendDirectMessage(p *Participant, to string, body string) error {
+	if to == "" || to == p.username {
+		return fmt.Errorf("%w: invalid recipient %q", ErrInvalidArgument, to)
+	}
+	if body == "" {
+		return fmt.Errorf("%w: empty message", ErrInvalidArgument)
+	}
+	if limits := rm.Limits(); limits.MaxMessageBytes > 0 && len(body) > limits.MaxMessageBytes {
+		return fmt.Errorf("%w: messages cannot be longer than %d bytes", ErrInvalidArgument, limits.MaxMessageBytes)
+	}
+	now := time.Now()
+	messagesBucket, bytesBucket, _ := p.rateLimits()
+	if !messagesBucket.allow(now, 1) || !bytesBucket.allow(now, len(body)) {
+		return fmt.Errorf("%w: slow down", ErrRateLimited)
+	}
+
+	return rm.deliver(store.MailItem{
+		Kind:      store.MailDirectMessage,
+		Recipient: to,
+		From:      p.username,
+		Body:      body,
+		SentAt:    now,
+	}, rm.onlineParticipants(to))
+}
+
+// notifyMentions notifies the users mentioned in msg, written in r, who are
+// not in r. The users who cannot see r are not notified.
+func (rm *RoomManager) notifyMentions(r *room, msg store.Message) {
+	for _, username := range mentions(msg.Body) {
+		if username == msg.Author || !r.VisibleTo(username) {
+			continue
+		}
+		var recipients []*Participant
+		inRoom := false
+		for _, p := range rm.onlineParticipants(username) {
+			if p.CurrentRoom() == r {
+				inRoom = true
+
+				break
+			}
+			recipients = append(recipients, p)
+		}
+		if inRoom {
+			continue
+		}
+		err := rm.deliver(store.MailItem{
+			Kind:      store.MailMention,
+			Recipient: username,
+			From:      msg.Author,
+			Body:      msg.Body,
+			SentAt:    msg.SentAt,
+			RoomID:    string(r.id),
+			RoomName:  r.name,
+			MessageID: msg.ID,
+		}, recipients)
+		if err != nil {
+			rm.log.Errorf("Mention of %s in room %s failed: %v", username, r.name, err)
+		}
+	}
+}
+
+// deliver sends item to the recipients. When there is none, it forwards item
+// to the replica its recipient is connected to, or stores it in the mailbox
+// of its recipient.
+func (rm *RoomManager) deliver(item store.MailItem, recipients []*Participant) error {
+	if len(recipients) > 0 {
+		sMsgP, err := newMailMessage(item, false)
+		if err != nil {
+			return err
+		}
+		for _, p := range recipients {
+			p.Send(sMsgP)
+		}
+
+		return nil
+	}
+	if rm.forwardMail(item) {
+		return nil
+	}
+	if _, err := rm.store.AppendMail(item, rm.mailbox.MaxItems); err != nil {
+		return fmt.Errorf("store failed: %w", err)
+	}
+
+	return nil
+}
+
+// FlushMailbox delivers to p, oldest first, the items kept in the mailbox of
+// its username. The items not delivered because p was closed are kept, in
+// order, ahead of the items stored meanwhile.
+func (rm *RoomManager) FlushMailbox(p *Participant) {
+	items, err := rm.store.TakeMail(p.username)
+	if err != nil {
+		rm.log.Errorf("Reading the mailbox of %s failed: %v", p.username, err)
+
+		return
+	}
+	now := time.Now()
+	for i, item := range items {
+		if rm.mailbox.Retention > 0 && now.Sub(item.SentAt) > rm.mailbox.Retention {
+			continue
+		}
+		sMsgP, err := newMailMessage(item, true)
+		if err != nil {
+			rm.log.Errorf("Marshal from mail item failed: %v", err)
+
+			continue
+		}
+		if !p.Send(sMsgP) {
+			if err := rm.store.RestoreMail(p.username, items[i:]); err != nil {
+				rm.log.Errorf("Keeping the mail of %s failed: %v", p.username, err)
+			}
+
+			return
+		}
+	}
+}
+
+// pruneMail drops the mailbox items older than the retention at now.
+func (rm *RoomManager) pruneMail(now time.Time) {
+	if rm.mailbox.Retention <= 0 {
+		return
+	}
+	n, err := rm.store.PruneMail(now.Add(-rm.mailbox.Retention))
+	if err != nil {
+		rm.log.Errorf("Pruning the mailboxes failed: %v", err)
+
+		return
+	}
+	if n > 0 {
+		rm.log.Infof("Pruned %d mailbox items older than %s", n, rm.mailbox.Retention)
+	}
+}
+
+// newMailMessage builds the ServerMessage delivering item.
+func newMailMessage(item store.MailItem, offline bool) (*pb.ServerMessage, error) {
+	var cmd pb.ServerMessage_ServerCommand
+	var op proto.Message
+	switch item.Kind {
+	case store.MailMention:
+		cmd = pb.ServerMessage_Mention
+		op = &pb.ServerMessage_ServerMention{
+			RoomId:    item.RoomID,
+			RoomName:  item.RoomName,
+			Author:    item.From,
+			Body:      item.Body,
+			MessageId: uint64(item.MessageID),
+			SentAt:    timestamppb.New(item.SentAt),
+			Offline:   offline,
+		}
+	default:
+		cmd = pb.ServerMessage_DirectMessage
+		op = &pb.ServerMessage_ServerDirectMessage{
+			From:    item.From,
+			Body:    item.Body,
+			SentAt:  timestamppb.New(item.SentAt),
+			Offline: offline,
+		}
+	}
+	operation, err := pbutils.MarshalAny(op)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ServerMessage{
+		Command:   cmd,
+		Operation: operation,
+	}, nil
+}
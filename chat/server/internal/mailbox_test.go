@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestFlushMailbox(t *testing.T) {
+	testsTable := []struct {
+		Name     string
+		Mailbox  Mailbox
+		Sent     []string
+		SentAgo  time.Duration
+		WantBody []string
+	}{
+		{
+			Name:     "in order",
+			Sent:     []string{"one", "two", "three"},
+			WantBody: []string{"one", "two", "three"},
+		},
+		{
+			Name:     "capped",
+			Mailbox:  Mailbox{MaxItems: 2},
+			Sent:     []string{"one", "two", "three"},
+			WantBody: []string{"two", "three"},
+		},
+		{
+			Name:    "expired",
+			Mailbox: Mailbox{Retention: time.Hour},
+			Sent:    []string{"one"},
+			SentAgo: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm, err := NewRoomManager(Limits{}, Lifecycle{}, tt.Mailbox, store.NewMemoryStore(), backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			for _, body := range tt.Sent {
+				err := rm.deliver(store.MailItem{
+					Kind:      store.MailDirectMessage,
+					Recipient: "bob",
+					From:      "alice",
+					Body:      body,
+					SentAt:    time.Now().Add(-tt.SentAgo),
+				}, nil)
+				if err != nil {
+					t.Fatalf("deliver failed: %v", err)
+				}
+			}
+
+			p, _ := NewParticipant("bob", Limits{})
+			if err := rm.Connect(p); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			rm.FlushMailbox(p)
+			p.Close()
+			close(p.Out)
+
+			var got []string
+			for sMsgP := range p.Out {
+				var directMsg pb.ServerMessage_ServerDirectMessage
+				if err := pbutils.UnmarshalAny(sMsgP.Operation, &directMsg); err != nil {
+					t.Fatalf("Unmarshal failed: %v", err)
+				}
+				if !directMsg.Offline {
+					t.Errorf("%q not flagged as offline", directMsg.Body)
+				}
+				got = append(got, directMsg.Body)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.WantBody) {
+				t.Errorf("got %v, want %v", got, tt.WantBody)
+			}
+			if len(rm.onlineParticipants("bob")) != 0 {
+				t.Errorf("bob still online after Close")
+			}
+		})
+	}
+}
@@ -1,62 +1,344 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 
+	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	"github.com/savo92/playground-go-grpc/chat/search"
+	"github.com/savo92/playground-go-grpc/chat/store"
 )
 
+type Stats struct {
+	Rooms        int
+	Participants int
+	Messages     uint64
+	Watchers     int
+}
+
+// RoomManager owns the rooms. Its methods are safe for concurrent use.
 type RoomManager struct {
+	// rooms, reserved and closed are guarded by mu.
 	rooms map[RoomID]*room
-	mu    sync.Mutex
+	// reserved holds the names of the rooms being created.
+	reserved map[string]bool
+	mu       sync.Mutex
+
+	// messages counts the messages forwarded by every room. Use atomic ops.
+	messages uint64
+
+	// limits is guarded by limitsMu.
+	limits    Limits
+	limitsMu  sync.Mutex
+	lifecycle Lifecycle
+	mailbox   Mailbox
+	store     store.Store
+	// index indexes the messages of the store.
+	index *search.Index
+
+	// node identifies the replica on the backplane.
+	node        string
+	backplane   backplane.Backplane
+	unsubscribe []func()
+	// events queues the work received from the backplane on the topics of
+	// the manager.
+	events chan func()
 
-	closed bool
+	// online indexes the connected participants by username, and remote the
+	// online users of the other replicas by replica. They are guarded by
+	// onlineMu.
+	online   map[string]map[participantID]*Participant
+	remote   map[string]*remotePresence
+	onlineMu sync.Mutex
+
+	// relayFunc, when set, relays the messages written in the rooms to the
+	// federated servers. It is set before the manager is used.
+	relayFunc func(RelayedMessage)
+
+	log log.FieldLogger
+
+	// done is closed with the manager, stopping the sweep of the rooms.
+	done      chan struct{}
+	closed    bool
+	compactor *store.Compactor
 }
 
-func (rm *RoomManager) CreateRoom(name string) (RoomID, error) {
-	if rm.closed {
-		return RoomID(""), fmt.Errorf("room manager already closed")
-	}
-	r, err := newRoom(name)
+// CreateRoom creates a room, which the replicas create as well.
+func (rm *RoomManager) CreateRoom(name string, opts ...RoomOption) (RoomID, error) {
+	r, err := rm.createRoom(name, opts...)
 	if err != nil {
 		return RoomID(""), err
 	}
-	r.rm = rm
+	rm.announceRoom(r)
+
+	return r.id, nil
+}
+
+func (rm *RoomManager) createRoom(name string, opts ...RoomOption) (*room, error) {
+	if err := rm.reserveName(name); err != nil {
+		return nil, err
+	}
+	// The room subscribes to the backplane without holding the lock.
+	r, err := newRoom(name, rm, opts...)
 	rm.mu.Lock()
-	rm.rooms[r.id] = r
+	delete(rm.reserved, name)
+	closed := rm.closed
+	if err == nil && !closed {
+		rm.rooms[r.id] = r
+	}
 	rm.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		r.close("room closed")
 
-	return r.id, nil
+		return nil, fmt.Errorf("room manager already closed")
+	}
+
+	return r, nil
+}
+
+// reserveName reserves name for a room being created, unless taken.
+func (rm *RoomManager) reserveName(name string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	if rm.closed {
+		return fmt.Errorf("room manager already closed")
+	}
+	if rm.reserved[name] {
+		return fmt.Errorf("%w: %s", ErrRoomExists, name)
+	}
+	for _, r := range rm.rooms {
+		if r.name == name {
+			return fmt.Errorf("%w: %s", ErrRoomExists, name)
+		}
+	}
+	rm.reserved[name] = true
+
+	return nil
 }
 
 func (rm *RoomManager) GetRoom(id RoomID) (*room, bool) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
 	r, ok := rm.rooms[id]
 
 	return r, ok
 }
 
+// FindRoom looks a room up by id or by name.
+func (rm *RoomManager) FindRoom(ref string) (*room, bool) {
+	if r, ok := rm.GetRoom(RoomID(ref)); ok {
+		return r, true
+	}
+	for _, r := range rm.ListRooms() {
+		if r.name == ref {
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// FindRoomByInvite looks up the room a valid invite code belongs to.
+func (rm *RoomManager) FindRoomByInvite(code string) (*room, bool) {
+	for _, r := range rm.ListRooms() {
+		if r.hasInvite(code) {
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// ListRooms returns the rooms currently managed, sorted by name.
+func (rm *RoomManager) ListRooms() []*room {
+	rm.mu.Lock()
+	rooms := make([]*room, 0, len(rm.rooms))
+	for _, r := range rm.rooms {
+		rooms = append(rooms, r)
+	}
+	rm.mu.Unlock()
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].name < rooms[j].name
+	})
+
+	return rooms
+}
+
+func (rm *RoomManager) CloseRoom(id RoomID) error {
+	r, ok := rm.GetRoom(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, id)
+	}
+	r.close("room closed")
+
+	return nil
+}
+
+// RetireRoom announces notice to the room with the given id, moves its
+// participants to landing, when not nil, and closes it. The participants
+// that cannot join landing right away are disconnected with notice.
+func (rm *RoomManager) RetireRoom(id RoomID, landing *room, notice string) error {
+	r, ok := rm.GetRoom(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, id)
+	}
+	if err := r.Announce(notice); err != nil {
+		return err
+	}
+	for _, p := range r.Participants() {
+		if landing == nil {
+			break
+		}
+		if err := p.JoinRoom(landing, ""); err != nil && !errors.Is(err, ErrQueued) {
+			rm.log.Debugf("Moving participant %s to room %s failed: %v", p.id, landing.name, err)
+
+			continue
+		}
+		p.ConfirmRoomCheckout()
+	}
+	r.close(notice)
+
+	return nil
+}
+
+// DisconnectParticipant disconnects the participant with the given id,
+// notifying it with the given reason.
+func (rm *RoomManager) DisconnectParticipant(id string, reason string) (*Participant, error) {
+	for _, r := range rm.ListRooms() {
+		for _, p := range r.Participants() {
+			if string(p.id) == id {
+				p.Disconnect(reason)
+
+				return p, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrParticipantNotFound, id)
+}
+
+func (rm *RoomManager) Stats() Stats {
+	stats := Stats{
+		Messages: atomic.LoadUint64(&rm.messages),
+	}
+	for _, r := range rm.ListRooms() {
+		stats.Rooms++
+		stats.Participants += len(r.Participants())
+		stats.Watchers += r.watcherCount()
+	}
+
+	return stats
+}
+
 func (rm *RoomManager) removeRoom(id RoomID) {
-	log.Debugf("Room %s removed from manager", rm.rooms[id].name)
 	rm.mu.Lock()
+	r, ok := rm.rooms[id]
 	delete(rm.rooms, id)
 	rm.mu.Unlock()
+
+	if ok {
+		rm.log.Debugf("Room %s removed from manager", r.name)
+	}
 }
 
+// Close closes every room and refuses the creation of new ones.
 func (rm *RoomManager) Close() {
+	rm.mu.Lock()
 	if rm.closed {
+		rm.mu.Unlock()
+
 		return
 	}
-	log.Debugf("Closing room manager")
+	rm.log.Debugf("Closing room manager")
 	rm.closed = true
+	close(rm.done)
+	if rm.compactor != nil {
+		rm.compactor.Stop()
+	}
+	rooms := make([]*room, 0, len(rm.rooms))
 	for _, r := range rm.rooms {
-		r.close()
+		rooms = append(rooms, r)
+	}
+	rm.mu.Unlock()
+
+	for _, r := range rooms {
+		r.close("room closed")
+	}
+	rm.publishPresence(true)
+	for _, unsubscribe := range rm.unsubscribe {
+		unsubscribe()
 	}
 }
 
-func NewRoomManager() (*RoomManager, error) {
+// ManagerOption configures a RoomManager.
+type ManagerOption func(*RoomManager)
+
+// WithLogger sets the logger of the manager and of its rooms. They log to the
+// standard logger by default.
+func WithLogger(logger log.FieldLogger) ManagerOption {
+	return func(rm *RoomManager) {
+		rm.log = logger
+	}
+}
+
+// NewRoomManager creates a manager whose rooms store their history in st and
+// are cleaned up according to lifecycle. The mailboxes of the offline users
+// are kept in st according to mailbox. The rooms are shared with the
+// replicas on bp.
+func NewRoomManager(limits Limits, lifecycle Lifecycle, mailbox Mailbox, st store.Store, bp backplane.Backplane, opts ...ManagerOption) (*RoomManager, error) {
+	if st == nil {
+		return nil, fmt.Errorf("a history store is required")
+	}
+	if bp == nil {
+		return nil, fmt.Errorf("a backplane is required")
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
 	rm := &RoomManager{
-		rooms: make(map[RoomID]*room),
+		rooms:     make(map[RoomID]*room),
+		reserved:  make(map[string]bool),
+		limits:    limits,
+		lifecycle: lifecycle,
+		mailbox:   mailbox,
+		store:     st,
+		index:     search.NewIndex(),
+		node:      uuid.New().String(),
+		backplane: bp,
+		online:    make(map[string]map[participantID]*Participant),
+		remote:    make(map[string]*remotePresence),
+		events:    make(chan func(), eventQueueSize),
+		log:       log.StandardLogger(),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(rm)
+	}
+	if err := rm.indexHistory(); err != nil {
+		return nil, err
+	}
+	if err := rm.subscribe(); err != nil {
+		return nil, err
+	}
+	go rm.presenceLoop()
+	go rm.eventLoop()
+	if lifecycle.SweepInterval > 0 {
+		go rm.sweepLoop(lifecycle.SweepInterval)
+	}
+	if lifecycle.CompactInterval > 0 {
+		rm.compactor = store.NewCompactor(st, lifecycle.CompactInterval, rm.index.Remove)
 	}
 
 	return rm, nil
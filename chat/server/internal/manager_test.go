@@ -0,0 +1,379 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// The tests in this file stress the lifecycle of the rooms and of the
+// participants from many goroutines. They are meant to be run with -race.
+
+const stressTimeout = 20 * time.Second
+
+// testSession stands for the session of a participant: it drains the
+// messages of the participant and closes it when disconnected.
+func testSession(p *Participant) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-p.Out:
+			case <-p.DisconnectChan:
+				p.Close()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		p.Close()
+		close(done)
+		wg.Wait()
+	}
+}
+
+func newStressManager(t *testing.T) *RoomManager {
+	t.Helper()
+	// The zero Limits disable the rate limits.
+	rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+	if err != nil {
+		t.Fatalf("NewRoomManager failed: %v", err)
+	}
+
+	return rm
+}
+
+func writeMessage(t *testing.T, p *Participant, r *room, body string) error {
+	t.Helper()
+	op, err := pbutils.MarshalAny(&pb.ClientMessage_ClientWriteMessage{Body: body})
+	if err != nil {
+		t.Fatalf("MarshalAny failed: %v", err)
+	}
+
+	return r.Write(RoomMessage{
+		CMsgP:       &pb.ClientMessage{Command: pb.ClientMessage_WriteMessage, Operation: op},
+		Participant: p,
+	})
+}
+
+// runConcurrently runs f n times concurrently, failing the test when they do
+// not return within stressTimeout.
+func runConcurrently(t *testing.T, n int, f func(i int)) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			f(i)
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(stressTimeout):
+		t.Fatalf("goroutines still running after %s, deadlock?", stressTimeout)
+	}
+}
+
+func TestRoomLifecycleStress(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		Run  func(t *testing.T, rm *RoomManager)
+	}{
+		{
+			Name: "create join write and close rooms",
+			Run: func(t *testing.T, rm *RoomManager) {
+				runConcurrently(t, 16, func(i int) {
+					for j := 0; j < 20; j++ {
+						rID, err := rm.CreateRoom(fmt.Sprintf("room-%d-%d", i, j))
+						if err != nil {
+							t.Errorf("CreateRoom failed: %v", err)
+
+							return
+						}
+						r, ok := rm.GetRoom(rID)
+						if !ok {
+							continue
+						}
+						p, _ := NewParticipant(fmt.Sprintf("user-%d", i), Limits{})
+						stop := testSession(p)
+						if err := p.JoinRoom(r, ""); err == nil {
+							_ = writeMessage(t, p, r, "hello")
+						}
+						rm.ListRooms()
+						rm.Stats()
+						if j%2 == 0 {
+							_ = rm.CloseRoom(rID)
+						}
+						stop()
+					}
+				})
+			},
+		},
+		{
+			Name: "write while the room closes",
+			Run: func(t *testing.T, rm *RoomManager) {
+				rID, _ := rm.CreateRoom("busy")
+				r, _ := rm.GetRoom(rID)
+				runConcurrently(t, 32, func(i int) {
+					p, _ := NewParticipant(fmt.Sprintf("user-%d", i), Limits{})
+					stop := testSession(p)
+					defer stop()
+					if err := p.JoinRoom(r, ""); err != nil {
+						if !errors.Is(err, ErrRoomClosed) {
+							t.Errorf("JoinRoom failed: %v", err)
+						}
+
+						return
+					}
+					for j := 0; j < 20; j++ {
+						if err := writeMessage(t, p, r, "hello"); err != nil {
+							if !errors.Is(err, ErrRoomClosed) {
+								t.Errorf("Write failed: %v", err)
+							}
+
+							return
+						}
+						if i == 0 && j == 10 {
+							r.close("room closed")
+						}
+					}
+				})
+			},
+		},
+		{
+			Name: "move between rooms",
+			Run: func(t *testing.T, rm *RoomManager) {
+				var rooms []*room
+				for i := 0; i < 4; i++ {
+					rID, _ := rm.CreateRoom(fmt.Sprintf("room-%d", i))
+					r, _ := rm.GetRoom(rID)
+					rooms = append(rooms, r)
+				}
+				runConcurrently(t, 16, func(i int) {
+					p, _ := NewParticipant(fmt.Sprintf("user-%d", i), Limits{})
+					stop := testSession(p)
+					for j := 0; j < 50; j++ {
+						r := rooms[(i+j)%len(rooms)]
+						if err := p.JoinRoom(r, ""); err != nil && !errors.Is(err, ErrInvalidArgument) {
+							t.Errorf("JoinRoom failed: %v", err)
+						}
+						_ = writeMessage(t, p, r, "hello")
+					}
+					stop()
+				})
+				for _, r := range rooms {
+					if n := len(r.Participants()); n != 0 {
+						t.Errorf("room %s has %d participants after they closed", r.name, n)
+					}
+				}
+			},
+		},
+		{
+			Name: "queue of a full room",
+			Run: func(t *testing.T, rm *RoomManager) {
+				rID, _ := rm.CreateRoom("small", WithCapacity(2, true))
+				r, _ := rm.GetRoom(rID)
+				runConcurrently(t, 16, func(i int) {
+					for j := 0; j < 20; j++ {
+						p, _ := NewParticipant(fmt.Sprintf("user-%d", i), Limits{})
+						stop := testSession(p)
+						if err := p.JoinRoom(r, ""); err != nil && !errors.Is(err, ErrQueued) {
+							t.Errorf("JoinRoom failed: %v", err)
+						}
+						if n := len(r.Participants()); n > 2 {
+							t.Errorf("room has %d participants, over its capacity", n)
+						}
+						stop()
+					}
+				})
+				if info := r.Info(); info.Participants != 0 || info.Waiting != 0 {
+					t.Errorf("room has %d participants and %d waiting after they closed", info.Participants, info.Waiting)
+				}
+			},
+		},
+		{
+			Name: "disconnect and kick",
+			Run: func(t *testing.T, rm *RoomManager) {
+				rID, _ := rm.CreateRoom("moderated")
+				r, _ := rm.GetRoom(rID)
+				runConcurrently(t, 16, func(i int) {
+					for j := 0; j < 20; j++ {
+						p, _ := NewParticipant(fmt.Sprintf("user-%d", i), Limits{})
+						stop := testSession(p)
+						_ = p.JoinRoom(r, "")
+						switch j % 3 {
+						case 0:
+							_, _ = rm.DisconnectParticipant(p.String(), "bye")
+						case 1:
+							_ = r.Kick(nil, p.username, "bye")
+						default:
+							p.Disconnect("bye")
+						}
+						// A second disconnection must not block.
+						p.Disconnect("bye again")
+						stop()
+					}
+				})
+			},
+		},
+		{
+			Name: "close the manager while joining",
+			Run: func(t *testing.T, rm *RoomManager) {
+				runConcurrently(t, 16, func(i int) {
+					for j := 0; j < 20; j++ {
+						if i == 0 && j == 10 {
+							rm.Close()
+						}
+						rID, err := rm.CreateRoom(fmt.Sprintf("room-%d-%d", i, j))
+						if err != nil {
+							continue
+						}
+						r, ok := rm.GetRoom(rID)
+						if !ok {
+							continue
+						}
+						p, _ := NewParticipant(fmt.Sprintf("user-%d", i), Limits{})
+						stop := testSession(p)
+						_ = p.JoinRoom(r, "")
+						stop()
+					}
+				})
+				if n := len(rm.ListRooms()); n != 0 {
+					t.Errorf("%d rooms left after Close", n)
+				}
+			},
+		},
+		{
+			Name: "participant not reading its messages",
+			Run: func(t *testing.T, rm *RoomManager) {
+				rID, _ := rm.CreateRoom("stalled")
+				r, _ := rm.GetRoom(rID)
+				p, _ := NewParticipant("alice", Limits{})
+				defer p.Close()
+				if err := p.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+				// The backplane must not wait for the room to deliver.
+				runConcurrently(t, 1, func(int) {
+					for i := 0; i < 2*outBuffer; i++ {
+						if err := r.publish(roomEvent{Node: rm.node, Author: "bob", Body: "hello", SentAt: time.Now()}); err != nil {
+							t.Errorf("publish failed: %v", err)
+						}
+					}
+				})
+			},
+		},
+		{
+			Name: "mute a flooder while a participant is not reading",
+			Run: func(t *testing.T, rm *RoomManager) {
+				// Every message of alice is rate limited, none is forwarded.
+				limits := Limits{ParticipantBytes: 1, ParticipantBytesBurst: 1, MuteAfter: 1, MuteWindow: time.Minute, MuteFor: time.Minute}
+				if err := rm.SetLimits(limits); err != nil {
+					t.Fatalf("SetLimits failed: %v", err)
+				}
+				rID, _ := rm.CreateRoom("flooded")
+				r, _ := rm.GetRoom(rID)
+				bob, _ := NewParticipant("bob", Limits{})
+				defer bob.Close()
+				if err := bob.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+				for len(bob.Out) < cap(bob.Out) {
+					bob.Send(&pb.ServerMessage{})
+				}
+				alice, _ := NewParticipant("alice", limits)
+				defer testSession(alice)()
+				if err := alice.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+				// The announcement of the mute must not stop the room.
+				runConcurrently(t, 1, func(int) {
+					for i := 0; i < 3; i++ {
+						if err := writeMessage(t, alice, r, "flood"); err != nil {
+							t.Errorf("Write failed: %v", err)
+						}
+					}
+				})
+			},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			tt.Run(t, rm)
+		})
+	}
+}
+
+// slowBackplane holds the subscriptions to the topics of the rooms until
+// release is closed.
+type slowBackplane struct {
+	*backplane.Local
+	subscribing chan struct{}
+	release     chan struct{}
+}
+
+func (b *slowBackplane) Subscribe(topic string, h backplane.Handler) (func(), error) {
+	if topic == roomTopic("slow") {
+		close(b.subscribing)
+		<-b.release
+	}
+
+	return b.Local.Subscribe(topic, h)
+}
+
+func TestCreateRoomSubscribing(t *testing.T) {
+	bp := &slowBackplane{Local: backplane.NewLocal(), subscribing: make(chan struct{}), release: make(chan struct{})}
+	rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), bp)
+	if err != nil {
+		t.Fatalf("NewRoomManager failed: %v", err)
+	}
+	defer rm.Close()
+
+	created := make(chan error, 1)
+	go func() {
+		_, err := rm.CreateRoom("slow")
+		created <- err
+	}()
+	<-bp.subscribing
+	// The manager is usable while the room subscribes.
+	runConcurrently(t, 1, func(int) {
+		if _, err := rm.CreateRoom("slow"); !errors.Is(err, ErrRoomExists) {
+			t.Errorf("CreateRoom of a room being created returned %v, want %v", err, ErrRoomExists)
+		}
+		if _, err := rm.CreateRoom("fast"); err != nil {
+			t.Errorf("CreateRoom failed: %v", err)
+		}
+		if n := len(rm.ListRooms()); n != 1 {
+			t.Errorf("got %d rooms, want 1", n)
+		}
+	})
+	close(bp.release)
+	if err := <-created; err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	if _, ok := rm.FindRoom("slow"); !ok {
+		t.Error("room slow not found once created")
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+const (
+	MaxTopicLength       = 256
+	MaxDescriptionLength = 2048
+	MaxLabelKeyLength    = 64
+	MaxLabelValueLength  = 256
+	MaxLabels            = 32
+)
+
+func WithTopic(topic string) RoomOption {
+	return func(r *room) {
+		r.topic = topic
+	}
+}
+
+func WithDescription(description string) RoomOption {
+	return func(r *room) {
+		r.description = description
+	}
+}
+
+// WithCreator records the username that created the room.
+func WithCreator(username string) RoomOption {
+	return func(r *room) {
+		r.creator = username
+	}
+}
+
+func WithLabels(labels map[string]string) RoomOption {
+	return func(r *room) {
+		for k, v := range labels {
+			r.labels[k] = v
+		}
+	}
+}
+
+// SetTopic changes the topic of the room and broadcasts it to the
+// participants. Only owners and moderators can change it. A nil actor stands
+// for the server operators, who are always allowed.
+func (r *room) SetTopic(actor *Participant, topic string) error {
+	if err := checkLength("topic", topic, MaxTopicLength); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if err := r.checkRole(actor, pb.Role_Moderator); err != nil {
+		r.mu.Unlock()
+
+		return err
+	}
+	r.topic = topic
+	r.mu.Unlock()
+
+	changedBy := ""
+	if actor != nil {
+		changedBy = actor.username
+	}
+	r.broadcastTopic(topic, changedBy)
+	r.publishChange(eventTopic, roomChange{Username: changedBy, Value: topic})
+
+	return nil
+}
+
+// SetDescription changes the description of the room. Only owners and
+// moderators can change it, a nil actor stands for the server operators.
+func (r *room) SetDescription(actor *Participant, description string) error {
+	if err := checkLength("description", description, MaxDescriptionLength); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if err := r.checkRole(actor, pb.Role_Moderator); err != nil {
+		r.mu.Unlock()
+
+		return err
+	}
+	r.description = description
+	r.mu.Unlock()
+	r.publishChange(eventDescription, roomChange{Value: description})
+
+	return nil
+}
+
+// SetLabel sets the label key of the room to value, removing it when value
+// is empty. Only owners can change the labels, a nil actor stands for the
+// server operators.
+func (r *room) SetLabel(actor *Participant, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("%w: the label key is required", ErrInvalidArgument)
+	}
+	if err := checkLength("label key", key, MaxLabelKeyLength); err != nil {
+		return err
+	}
+	if err := checkLength("label value", value, MaxLabelValueLength); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	if err := r.checkRole(actor, pb.Role_Owner); err != nil {
+		r.mu.Unlock()
+
+		return err
+	}
+	if _, ok := r.labels[key]; !ok && value != "" && len(r.labels) >= MaxLabels {
+		r.mu.Unlock()
+
+		return fmt.Errorf("%w: room %s cannot have more than %d labels", ErrInvalidArgument, r.name, MaxLabels)
+	}
+	r.setLabel(key, value)
+	r.mu.Unlock()
+	r.publishChange(eventLabel, roomChange{Key: key, Value: value})
+
+	return nil
+}
+
+// setLabel sets the label key to value, removing it when value is empty. The
+// caller must hold r.mu.
+func (r *room) setLabel(key, value string) {
+	if value == "" {
+		delete(r.labels, key)
+
+		return
+	}
+	r.labels[key] = value
+}
+
+// checkMetadata validates the metadata set by the options of a new room.
+func (r *room) checkMetadata() error {
+	if err := checkLength("topic", r.topic, MaxTopicLength); err != nil {
+		return err
+	}
+	if err := checkLength("description", r.description, MaxDescriptionLength); err != nil {
+		return err
+	}
+	if len(r.labels) > MaxLabels {
+		return fmt.Errorf("%w: a room cannot have more than %d labels", ErrInvalidArgument, MaxLabels)
+	}
+	for k, v := range r.labels {
+		if k == "" || v == "" {
+			return fmt.Errorf("%w: the labels require both a key and a value", ErrInvalidArgument)
+		}
+		if err := checkLength("label key", k, MaxLabelKeyLength); err != nil {
+			return err
+		}
+		if err := checkLength("label value", v, MaxLabelValueLength); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRole returns an error when actor has not at least role in the room.
+// The caller must hold r.mu.
+func (r *room) checkRole(actor *Participant, role pb.Role) error {
+	if actor == nil || rank(r.roles[actor.username]) >= rank(role) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s role required in room %s", ErrPermissionDenied, role, r.name)
+}
+
+func (r *room) broadcastTopic(topic, changedBy string) {
+	topicChangedMsg := pb.ServerMessage_ServerTopicChanged{
+		RoomId:    string(r.id),
+		Topic:     topic,
+		ChangedBy: changedBy,
+	}
+	op, err := pbutils.MarshalAny(&topicChangedMsg)
+	if err != nil {
+		r.rm.log.Errorf("Marshal from topicChanged failed: %v", err)
+
+		return
+	}
+	sMsgP := &pb.ServerMessage{
+		Command:   pb.ServerMessage_TopicChanged,
+		Operation: op,
+	}
+	for _, p := range copyParticipants(r) {
+		p.Send(sMsgP)
+	}
+	r.notifyWatchers(sMsgP)
+}
+
+func checkLength(what, s string, max int) error {
+	if n := utf8.RuneCountInString(s); n > max {
+		return fmt.Errorf("%w: the %s is %d characters long, the maximum is %d", ErrInvalidArgument, what, n, max)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestCreateRoomMetadata(t *testing.T) {
+	tooManyLabels := make(map[string]string, MaxLabels+1)
+	for i := 0; i <= MaxLabels; i++ {
+		tooManyLabels[fmt.Sprint("key", i)] = "value"
+	}
+	testsTable := []struct {
+		Name    string
+		Opts    []RoomOption
+		WantErr bool
+	}{
+		{
+			Name: "metadata",
+			Opts: []RoomOption{
+				WithTopic("News"),
+				WithDescription("The news of the day"),
+				WithCreator("alice"),
+				WithLabels(map[string]string{"team": "editorial"}),
+			},
+		},
+		{
+			// The lengths are counted in characters, not in bytes.
+			Name: "longest topic",
+			Opts: []RoomOption{WithTopic(strings.Repeat("é", MaxTopicLength))},
+		},
+		{
+			Name:    "topic too long",
+			Opts:    []RoomOption{WithTopic(strings.Repeat("a", MaxTopicLength+1))},
+			WantErr: true,
+		},
+		{
+			Name:    "description too long",
+			Opts:    []RoomOption{WithDescription(strings.Repeat("a", MaxDescriptionLength+1))},
+			WantErr: true,
+		},
+		{
+			Name:    "label without value",
+			Opts:    []RoomOption{WithLabels(map[string]string{"team": ""})},
+			WantErr: true,
+		},
+		{
+			Name:    "label key too long",
+			Opts:    []RoomOption{WithLabels(map[string]string{strings.Repeat("k", MaxLabelKeyLength+1): "value"})},
+			WantErr: true,
+		},
+		{
+			Name:    "too many labels",
+			Opts:    []RoomOption{WithLabels(tooManyLabels)},
+			WantErr: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			_, err := rm.CreateRoom("news", tt.Opts...)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("got error %v, want %v", err, ErrInvalidArgument)
+			}
+			if err != nil && len(rm.ListRooms()) != 0 {
+				t.Error("room created despite the error")
+			}
+		})
+	}
+}
+
+func TestUpdateRoomMetadata(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Role is granted to bob, who makes the change.
+		Role    pb.Role
+		Change  func(r *room, bob *Participant) error
+		WantErr error
+		Check   func(t *testing.T, info *pb.RoomInfo)
+	}{
+		{
+			Name: "topic",
+			Role: pb.Role_Moderator,
+			Change: func(r *room, bob *Participant) error {
+				return r.SetTopic(bob, "Breaking news")
+			},
+			Check: func(t *testing.T, info *pb.RoomInfo) {
+				if info.Topic != "Breaking news" {
+					t.Errorf("got topic %q", info.Topic)
+				}
+			},
+		},
+		{
+			Name: "topic by a member",
+			Change: func(r *room, bob *Participant) error {
+				return r.SetTopic(bob, "Breaking news")
+			},
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name: "topic by the operators",
+			Change: func(r *room, bob *Participant) error {
+				return r.SetTopic(nil, "")
+			},
+			Check: func(t *testing.T, info *pb.RoomInfo) {
+				if info.Topic != "" {
+					t.Errorf("got topic %q, want none", info.Topic)
+				}
+			},
+		},
+		{
+			Name: "topic too long",
+			Role: pb.Role_Owner,
+			Change: func(r *room, bob *Participant) error {
+				return r.SetTopic(bob, strings.Repeat("a", MaxTopicLength+1))
+			},
+			WantErr: ErrInvalidArgument,
+		},
+		{
+			Name: "description",
+			Role: pb.Role_Moderator,
+			Change: func(r *room, bob *Participant) error {
+				return r.SetDescription(bob, "The news of the day")
+			},
+			Check: func(t *testing.T, info *pb.RoomInfo) {
+				if info.Description != "The news of the day" {
+					t.Errorf("got description %q", info.Description)
+				}
+			},
+		},
+		{
+			Name: "description by a member",
+			Change: func(r *room, bob *Participant) error {
+				return r.SetDescription(bob, "The news of the day")
+			},
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name: "labels",
+			Role: pb.Role_Owner,
+			Change: func(r *room, bob *Participant) error {
+				if err := r.SetLabel(bob, "lang", "en"); err != nil {
+					return err
+				}
+
+				return r.SetLabel(bob, "team", "")
+			},
+			Check: func(t *testing.T, info *pb.RoomInfo) {
+				if want := map[string]string{"lang": "en"}; !reflect.DeepEqual(info.Labels, want) {
+					t.Errorf("got labels %v, want %v", info.Labels, want)
+				}
+			},
+		},
+		{
+			Name: "label by a moderator",
+			Role: pb.Role_Moderator,
+			Change: func(r *room, bob *Participant) error {
+				return r.SetLabel(bob, "lang", "en")
+			},
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name: "label without key",
+			Role: pb.Role_Owner,
+			Change: func(r *room, bob *Participant) error {
+				return r.SetLabel(bob, "", "en")
+			},
+			WantErr: ErrInvalidArgument,
+		},
+		{
+			Name: "too many labels",
+			Change: func(r *room, bob *Participant) error {
+				for i := 1; i < MaxLabels; i++ {
+					if err := r.SetLabel(nil, fmt.Sprint("key", i), "value"); err != nil {
+						return err
+					}
+				}
+				// Changing a label is allowed at the limit.
+				if err := r.SetLabel(nil, "team", "news"); err != nil {
+					return err
+				}
+
+				return r.SetLabel(nil, "lang", "en")
+			},
+			WantErr: ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			id, err := rm.CreateRoom("news", WithTopic("News"), WithLabels(map[string]string{"team": "editorial"}))
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			if err := r.SetRole(nil, "bob", tt.Role); err != nil {
+				t.Fatalf("SetRole failed: %v", err)
+			}
+			bob, _ := NewParticipant("bob", Limits{})
+			defer testSession(bob)()
+			if err := bob.JoinRoom(r, ""); err != nil {
+				t.Fatalf("JoinRoom failed: %v", err)
+			}
+
+			err = tt.Change(r, bob)
+			if !errors.Is(err, tt.WantErr) || (err != nil) != (tt.WantErr != nil) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			if tt.Check != nil {
+				tt.Check(t, r.Info())
+			}
+		})
+	}
+}
+
+func TestTopicChanged(t *testing.T) {
+	rm := newStressManager(t)
+	defer rm.Close()
+	id, err := rm.CreateRoom("news")
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	r, _ := rm.GetRoom(id)
+	if err := r.SetRole(nil, "alice", pb.Role_Owner); err != nil {
+		t.Fatalf("SetRole failed: %v", err)
+	}
+	alice, _ := NewParticipant("alice", Limits{})
+	defer alice.Close()
+	bob, _ := NewParticipant("bob", Limits{})
+	defer bob.Close()
+	for _, p := range []*Participant{alice, bob} {
+		if err := p.JoinRoom(r, ""); err != nil {
+			t.Fatalf("JoinRoom failed: %v", err)
+		}
+	}
+
+	if err := r.SetTopic(alice, "Breaking news"); err != nil {
+		t.Fatalf("SetTopic failed: %v", err)
+	}
+	// Every participant is told, the author of the change included.
+	for _, p := range []*Participant{alice, bob} {
+		for m := range p.Out {
+			if m.Command != pb.ServerMessage_TopicChanged {
+				continue
+			}
+			var topicMsg pb.ServerMessage_ServerTopicChanged
+			if err := pbutils.UnmarshalAny(m.Operation, &topicMsg); err != nil {
+				t.Fatalf("UnmarshalAny failed: %v", err)
+			}
+			if topicMsg.Topic != "Breaking news" || topicMsg.ChangedBy != "alice" || topicMsg.RoomId != string(id) {
+				t.Errorf("%s got %v, want the topic changed by alice", p.username, &topicMsg)
+			}
+
+			break
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// ReasonRoomMoved is the reason of the disconnection of the participants of
+// a room handed over to another node.
+const ReasonRoomMoved = "room moved"
+
+// clusterNamespace derives the ids of the rooms of a cluster from their
+// names.
+var clusterNamespace = uuid.MustParse("5b0e7c1e-3f55-4c83-9a53-2a6f0b1d7c42")
+
+// ClusterRoomID returns the id of the room named name in a cluster, where
+// every node must agree on the id of a room to find its owner.
+func ClusterRoomID(name string) RoomID {
+	return RoomID(uuid.NewSHA1(clusterNamespace, []byte(name)).String())
+}
+
+// WithID sets the id of the room instead of a random one.
+func WithID(id RoomID) RoomOption {
+	return func(r *room) {
+		r.id = id
+	}
+}
+
+// Snapshot returns the state of the room, its history, its read markers and
+// its waiting queue.
+func (r *room) Snapshot() (*pb.RoomSnapshot, error) {
+	snap := r.state()
+	messages, _, err := r.rm.store.History(string(r.id), store.HistoryQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("store failed: %w", err)
+	}
+	for _, m := range messages {
+		snap.Messages = append(snap.Messages, storedMessage(m, r.name))
+	}
+	markers, err := r.rm.store.ReadMarkers(string(r.id))
+	if err != nil {
+		return nil, fmt.Errorf("store failed: %w", err)
+	}
+	snap.ReadMarkers = make(map[string]uint64, len(markers))
+	for username, id := range markers {
+		snap.ReadMarkers[username] = uint64(id)
+	}
+	r.mu.Lock()
+	for _, p := range r.waiting {
+		snap.Waiting = append(snap.Waiting, p.username)
+	}
+	r.mu.Unlock()
+
+	return snap, nil
+}
+
+// state returns the state of the room, without its history.
+func (r *room) state() *pb.RoomSnapshot {
+	info := r.Info()
+	snap := &pb.RoomSnapshot{Info: info, Roles: make(map[string]pb.Role)}
+
+	r.mu.Lock()
+	snap.Permanent = r.permanent
+	if r.emptyTTL > 0 {
+		snap.EmptyTtl = durationpb.New(r.emptyTTL)
+	}
+	snap.Retention = retentionInfo(r.retention)
+	for username := range r.members {
+		snap.Members = append(snap.Members, username)
+	}
+	for username, role := range r.roles {
+		snap.Roles[username] = role
+	}
+	for username := range r.muted {
+		snap.Muted = append(snap.Muted, username)
+	}
+	for username, b := range r.bans {
+		bs := &pb.BanSnapshot{Username: username, Reason: b.reason}
+		if !b.until.IsZero() {
+			bs.Until = timestamppb.New(b.until)
+		}
+		snap.Bans = append(snap.Bans, bs)
+	}
+	for _, inv := range r.invites {
+		snap.Invites = append(snap.Invites, &pb.InviteSnapshot{
+			Code:      inv.Code,
+			CreatedBy: inv.CreatedBy,
+			ExpiresAt: timestamppb.New(inv.ExpiresAt),
+			MaxUses:   uint32(inv.MaxUses),
+			Uses:      uint32(inv.uses),
+		})
+	}
+	r.mu.Unlock()
+
+	return snap
+}
+
+// HandOver sends the room with the given id to another node through send.
+// The room stops accepting messages while handed over. Once sent, the room
+// is closed, disconnecting its participants with ReasonRoomMoved, and its
+// history is dropped. When it cannot be sent, the room accepts messages
+// again.
+func (rm *RoomManager) HandOver(id RoomID, send func(*pb.RoomSnapshot) error) error {
+	r, ok := rm.GetRoom(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRoomNotFound, id)
+	}
+	if err := r.freeze(); err != nil {
+		return err
+	}
+	snap, err := r.Snapshot()
+	if err == nil {
+		err = send(snap)
+	}
+	if err != nil {
+		r.mu.Lock()
+		r.frozen = false
+		r.mu.Unlock()
+
+		return err
+	}
+	r.close(ReasonRoomMoved)
+	if _, err := rm.PurgeRoom(string(id)); err != nil {
+		rm.log.Errorf("Dropping the history of room %s failed: %v", r.name, err)
+	}
+
+	return nil
+}
+
+// freeze stops the room from accepting messages, returning once the
+// messages accepted before are delivered.
+func (r *room) freeze() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+
+		return fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
+	}
+	r.frozen = true
+	r.mu.Unlock()
+
+	flushed := make(chan struct{})
+	select {
+	case r.flushC <- flushed:
+	case <-r.done:
+		return fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-r.done:
+		return fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
+	}
+}
+
+// RestoreRoom creates a room from a snapshot, replacing the room with the
+// same id, whose participants are disconnected with ReasonRoomMoved.
+func (rm *RoomManager) RestoreRoom(snap *pb.RoomSnapshot) (RoomID, error) {
+	info := snap.Info
+	if info == nil || info.Id == "" {
+		return "", fmt.Errorf("%w: room snapshot without id", ErrInvalidArgument)
+	}
+	id := RoomID(info.Id)
+	if r, ok := rm.GetRoom(id); ok {
+		r.close(ReasonRoomMoved)
+		if _, err := rm.PurgeRoom(info.Id); err != nil {
+			return "", err
+		}
+	}
+
+	r, err := rm.createRoom(info.Name, append(snapshotOptions(snap), WithID(id))...)
+	if err != nil {
+		return "", err
+	}
+	var lastMessageID store.MessageID
+	// ids maps the ids of the messages of snap to the ones of the node.
+	ids := make(map[uint64]store.MessageID, len(snap.Messages))
+	for _, m := range snap.Messages {
+		stored, err := rm.store.AppendMessage(store.Message{
+			RoomID: info.Id,
+			Author: m.Author,
+			Body:   m.Body,
+			SentAt: m.SentAt.AsTime(),
+		})
+		if err != nil {
+			return "", fmt.Errorf("store failed: %w", err)
+		}
+		rm.index.Add(stored)
+		ids[m.Id] = stored.ID
+		lastMessageID = stored.ID
+	}
+	for username, marker := range snap.ReadMarkers {
+		id, ok := ids[marker]
+		if !ok {
+			continue
+		}
+		if _, err := rm.store.SetReadMarker(username, info.Id, id); err != nil {
+			return "", fmt.Errorf("store failed: %w", err)
+		}
+	}
+
+	r.restoreState(snap)
+	r.mu.Lock()
+	r.lastMessageID = lastMessageID
+	for i, username := range snap.Waiting {
+		r.queuePositions[username] = i
+	}
+	r.mu.Unlock()
+	rm.announceRoom(r)
+
+	return id, nil
+}
+
+// snapshotOptions returns the options creating the room of snap, besides its
+// id.
+func snapshotOptions(snap *pb.RoomSnapshot) []RoomOption {
+	info := snap.Info
+	opts := []RoomOption{
+		WithVisibility(info.Visibility),
+		WithCapacity(int(info.Capacity), info.QueueWhenFull),
+		WithTopic(info.Topic),
+		WithDescription(info.Description),
+		WithLabels(info.Labels),
+		WithCreator(info.Creator),
+		WithLifecycle(info.Lifecycle, snap.EmptyTtl.AsDuration()),
+		WithRetention(RetentionFromProto(snap.Retention)),
+	}
+	if snap.Permanent {
+		opts = append(opts, WithPermanent())
+	}
+
+	return opts
+}
+
+// restoreState sets the state of the room from snap, besides the one set by
+// snapshotOptions.
+func (r *room) restoreState(snap *pb.RoomSnapshot) {
+	info := snap.Info
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.createdAt = info.CreatedAt.AsTime()
+	r.lastActivity = info.LastActivityAt.AsTime()
+	r.mode = info.Mode
+	r.slowModeInterval = info.SlowModeInterval.AsDuration()
+	for _, username := range snap.Members {
+		r.members[username] = true
+	}
+	for username, role := range snap.Roles {
+		// The nodes muting with a role send the muted users as such.
+		if role == pb.Role_Muted {
+			r.muted[username] = true
+
+			continue
+		}
+		r.roles[username] = role
+	}
+	for _, username := range snap.Muted {
+		r.muted[username] = true
+	}
+	for _, bs := range snap.Bans {
+		b := ban{reason: bs.Reason}
+		if bs.Until != nil {
+			b.until = bs.Until.AsTime()
+		}
+		r.bans[bs.Username] = b
+	}
+	for _, is := range snap.Invites {
+		r.invites[is.Code] = &Invite{
+			Code:      is.Code,
+			CreatedBy: is.CreatedBy,
+			ExpiresAt: is.ExpiresAt.AsTime(),
+			MaxUses:   int(is.MaxUses),
+			uses:      int(is.Uses),
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestHandOver(t *testing.T) {
+	from := newStressManager(t)
+	defer from.Close()
+	to := newStressManager(t)
+	defer to.Close()
+	id, err := from.CreateRoom("test", WithID(ClusterRoomID("test")), WithCapacity(1, true))
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	r, _ := from.GetRoom(id)
+	alice, _ := NewParticipant("alice", Limits{})
+	defer alice.Close()
+	if err := alice.JoinRoom(r, ""); err != nil {
+		t.Fatalf("JoinRoom failed: %v", err)
+	}
+	// bob and carol wait for a seat, in order.
+	var waiting []*Participant
+	for _, username := range []string{"bob", "carol"} {
+		p, _ := NewParticipant(username, Limits{})
+		defer p.Close()
+		if err := p.JoinRoom(r, ""); !errors.Is(err, ErrQueued) {
+			t.Fatalf("got error %v, want %v", err, ErrQueued)
+		}
+		waiting = append(waiting, p)
+	}
+	recvCommand := func(want pb.ServerMessage_ServerCommand) {
+		t.Helper()
+		if m := <-alice.Out; m.Command != want {
+			t.Fatalf("got %s, want %s", m.Command, want)
+		}
+	}
+	if err := writeMessage(t, alice, r, "hi"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	recvCommand(pb.ServerMessage_ForwardMessage)
+
+	err = from.HandOver(id, func(*pb.RoomSnapshot) error {
+		return errors.New("unreachable")
+	})
+	if err == nil {
+		t.Fatal("HandOver succeeded, want an error")
+	}
+	// The room accepts messages again when it cannot be handed over.
+	if err := writeMessage(t, alice, r, "still here"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	recvCommand(pb.ServerMessage_ForwardMessage)
+	if _, err := r.MarkRead(waiting[0], 0, false); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+
+	err = from.HandOver(id, func(snap *pb.RoomSnapshot) error {
+		// The room does not accept messages while handed over.
+		if err := writeMessage(t, alice, r, "bye"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		recvCommand(pb.ServerMessage_Error)
+		_, err := to.RestoreRoom(snap)
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("HandOver failed: %v", err)
+	}
+
+	restored, ok := to.GetRoom(id)
+	if !ok {
+		t.Fatal("room not restored")
+	}
+	if n, err := restored.Unread("bob"); err != nil || n != 0 {
+		t.Errorf("got %d messages unread by bob (error %v), want 0", n, err)
+	}
+	// The participants join again in any order, bob and carol keep their
+	// place.
+	for _, p := range []*Participant{alice, waiting[1], waiting[0]} {
+		if err := p.JoinRoom(restored, ""); err != nil && !errors.Is(err, ErrQueued) {
+			t.Fatalf("JoinRoom failed: %v", err)
+		}
+	}
+	restored.mu.Lock()
+	var got []string
+	for _, p := range restored.waiting {
+		got = append(got, p.username)
+	}
+	restored.mu.Unlock()
+	if len(got) != 2 || got[0] != "bob" || got[1] != "carol" {
+		t.Errorf("got queue %v, want [bob carol]", got)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func (r *room) Mode() (pb.RoomMode, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.mode, r.slowModeInterval
+}
+
+// SetMode changes the mode of the room and broadcasts the new state to the
+// participants. Only owners and moderators can change it. A nil actor stands
+// for the server operators, who are always allowed.
+func (r *room) SetMode(actor *Participant, mode pb.RoomMode, slowModeInterval time.Duration) error {
+	if _, ok := pb.RoomMode_name[int32(mode)]; !ok {
+		return fmt.Errorf("%w: unknown room mode %d", ErrInvalidArgument, mode)
+	}
+	if mode == pb.RoomMode_Slow && slowModeInterval <= 0 {
+		return fmt.Errorf("%w: the slow mode requires a positive interval", ErrInvalidArgument)
+	}
+	if mode != pb.RoomMode_Slow {
+		slowModeInterval = 0
+	}
+
+	r.mu.Lock()
+	if actor != nil && rank(r.roles[actor.username]) < rank(pb.Role_Moderator) {
+		r.mu.Unlock()
+
+		return fmt.Errorf("%w: moderator role required in room %s", ErrPermissionDenied, r.name)
+	}
+	r.setMode(mode, slowModeInterval)
+	r.mu.Unlock()
+
+	r.broadcastState()
+	r.publishChange(eventMode, roomChange{Mode: mode, SlowModeInterval: slowModeInterval})
+
+	return nil
+}
+
+// setMode changes the mode of the room, forgetting the last writes. The
+// caller must hold r.mu.
+func (r *room) setMode(mode pb.RoomMode, slowModeInterval time.Duration) {
+	r.mode = mode
+	r.slowModeInterval = slowModeInterval
+	r.lastWrites = make(map[string]time.Time)
+}
+
+// checkMode returns an error when the mode of the room does not allow a
+// participant with role to write at now. The caller must hold r.mu.
+func (r *room) checkMode(username string, role pb.Role, now time.Time) error {
+	switch r.mode {
+	case pb.RoomMode_ReadOnly:
+		return fmt.Errorf("%w: room %s is read-only", ErrPermissionDenied, r.name)
+	case pb.RoomMode_AnnouncementOnly:
+		if rank(role) < rank(pb.Role_Moderator) {
+			return fmt.Errorf("%w: only moderators can write in room %s", ErrPermissionDenied, r.name)
+		}
+	case pb.RoomMode_Slow:
+		if rank(role) >= rank(pb.Role_Moderator) {
+			return nil
+		}
+		if wait := r.lastWrites[username].Add(r.slowModeInterval).Sub(now); wait > 0 {
+			return fmt.Errorf("%w: room %s is in slow mode, wait %s", ErrRateLimited, r.name, wait.Round(time.Second))
+		}
+	}
+
+	return nil
+}
+
+func (r *room) broadcastState() {
+	mode, slowModeInterval := r.Mode()
+	stateMsg := pb.ServerMessage_ServerRoomState{
+		RoomId: string(r.id),
+		Mode:   mode,
+	}
+	if slowModeInterval > 0 {
+		stateMsg.SlowModeInterval = durationpb.New(slowModeInterval)
+	}
+	op, err := pbutils.MarshalAny(&stateMsg)
+	if err != nil {
+		r.rm.log.Errorf("Marshal from roomState failed: %v", err)
+
+		return
+	}
+	sMsgP := &pb.ServerMessage{
+		Command:   pb.ServerMessage_RoomState,
+		Operation: op,
+	}
+	for _, p := range copyParticipants(r) {
+		p.Send(sMsgP)
+	}
+	r.notifyWatchers(sMsgP)
+}
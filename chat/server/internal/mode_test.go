@@ -0,0 +1,231 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestCheckMode(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		Mode pb.RoomMode
+		Role pb.Role
+		// LastWrite is how long ago bob last wrote, never when zero.
+		LastWrite time.Duration
+		WantErr   error
+	}{
+		{
+			Name: "normal",
+			Mode: pb.RoomMode_Normal,
+		},
+		{
+			Name:    "read-only",
+			Mode:    pb.RoomMode_ReadOnly,
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name:    "read-only owner",
+			Mode:    pb.RoomMode_ReadOnly,
+			Role:    pb.Role_Owner,
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name:    "announcement-only member",
+			Mode:    pb.RoomMode_AnnouncementOnly,
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name: "announcement-only moderator",
+			Mode: pb.RoomMode_AnnouncementOnly,
+			Role: pb.Role_Moderator,
+		},
+		{
+			Name: "slow first write",
+			Mode: pb.RoomMode_Slow,
+		},
+		{
+			Name:      "slow too soon",
+			Mode:      pb.RoomMode_Slow,
+			LastWrite: 10 * time.Second,
+			WantErr:   ErrRateLimited,
+		},
+		{
+			Name:      "slow after the interval",
+			Mode:      pb.RoomMode_Slow,
+			LastWrite: 31 * time.Second,
+		},
+		{
+			Name:      "slow moderator",
+			Mode:      pb.RoomMode_Slow,
+			Role:      pb.Role_Moderator,
+			LastWrite: time.Second,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			id, err := rm.CreateRoom("lobby")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			now := time.Now()
+
+			r.mu.Lock()
+			r.setMode(tt.Mode, 30*time.Second)
+			if tt.LastWrite > 0 {
+				r.lastWrites["bob"] = now.Add(-tt.LastWrite)
+			}
+			err = r.checkMode("bob", tt.Role, now)
+			r.mu.Unlock()
+			if !errors.Is(err, tt.WantErr) || (err != nil) != (tt.WantErr != nil) {
+				t.Errorf("got error %v, want %v", err, tt.WantErr)
+			}
+		})
+	}
+}
+
+func TestSetMode(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Role is granted to bob, who changes the mode.
+		Role             pb.Role
+		Mode             pb.RoomMode
+		SlowModeInterval time.Duration
+		WantErr          error
+		WantInterval     time.Duration
+	}{
+		{
+			Name:             "slow",
+			Role:             pb.Role_Moderator,
+			Mode:             pb.RoomMode_Slow,
+			SlowModeInterval: time.Minute,
+			WantInterval:     time.Minute,
+		},
+		{
+			Name:             "interval outside the slow mode",
+			Role:             pb.Role_Owner,
+			Mode:             pb.RoomMode_ReadOnly,
+			SlowModeInterval: time.Minute,
+		},
+		{
+			Name:    "member",
+			Mode:    pb.RoomMode_ReadOnly,
+			WantErr: ErrPermissionDenied,
+		},
+		{
+			Name:    "slow without interval",
+			Role:    pb.Role_Moderator,
+			Mode:    pb.RoomMode_Slow,
+			WantErr: ErrInvalidArgument,
+		},
+		{
+			Name:    "unknown mode",
+			Role:    pb.Role_Moderator,
+			Mode:    pb.RoomMode(42),
+			WantErr: ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			id, err := rm.CreateRoom("lobby")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			if err := r.SetRole(nil, "bob", tt.Role); err != nil {
+				t.Fatalf("SetRole failed: %v", err)
+			}
+			bob, _ := NewParticipant("bob", Limits{})
+			defer bob.Close()
+			if err := bob.JoinRoom(r, ""); err != nil {
+				t.Fatalf("JoinRoom failed: %v", err)
+			}
+
+			err = r.SetMode(bob, tt.Mode, tt.SlowModeInterval)
+			if !errors.Is(err, tt.WantErr) || (err != nil) != (tt.WantErr != nil) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			mode, interval := r.Mode()
+			if err != nil {
+				if mode != pb.RoomMode_Normal {
+					t.Errorf("got mode %s, want %s", mode, pb.RoomMode_Normal)
+				}
+
+				return
+			}
+			if mode != tt.Mode || interval != tt.WantInterval {
+				t.Errorf("got mode %s with interval %s, want %s with %s", mode, interval, tt.Mode, tt.WantInterval)
+			}
+			// The participants are told of the new mode.
+			for m := range bob.Out {
+				if m.Command != pb.ServerMessage_RoomState {
+					continue
+				}
+				var stateMsg pb.ServerMessage_ServerRoomState
+				if err := pbutils.UnmarshalAny(m.Operation, &stateMsg); err != nil {
+					t.Fatalf("UnmarshalAny failed: %v", err)
+				}
+				if stateMsg.Mode != tt.Mode || stateMsg.SlowModeInterval.AsDuration() != tt.WantInterval {
+					t.Errorf("got state %v, want mode %s with interval %s", &stateMsg, tt.Mode, tt.WantInterval)
+				}
+
+				break
+			}
+		})
+	}
+}
+
+func TestSlowMode(t *testing.T) {
+	rm := newStressManager(t)
+	defer rm.Close()
+	id, err := rm.CreateRoom("lobby")
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	r, _ := rm.GetRoom(id)
+	bob, _ := NewParticipant("bob", Limits{})
+	defer bob.Close()
+	if err := bob.JoinRoom(r, ""); err != nil {
+		t.Fatalf("JoinRoom failed: %v", err)
+	}
+	if err := r.SetMode(nil, pb.RoomMode_Slow, time.Hour); err != nil {
+		t.Fatalf("SetMode failed: %v", err)
+	}
+	recvCommand := func(want pb.ServerMessage_ServerCommand) {
+		t.Helper()
+		for m := range bob.Out {
+			switch m.Command {
+			case want:
+				return
+			case pb.ServerMessage_ForwardMessage, pb.ServerMessage_Error:
+				t.Fatalf("got %s, want %s", m.Command, want)
+			}
+		}
+	}
+
+	for _, want := range []pb.ServerMessage_ServerCommand{pb.ServerMessage_ForwardMessage, pb.ServerMessage_Error} {
+		if err := writeMessage(t, bob, r, "hi"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		recvCommand(want)
+	}
+	// Changing the mode forgets the last writes.
+	if err := r.SetMode(nil, pb.RoomMode_Slow, time.Hour); err != nil {
+		t.Fatalf("SetMode failed: %v", err)
+	}
+	if err := writeMessage(t, bob, r, "hi again"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	recvCommand(pb.ServerMessage_ForwardMessage)
+}
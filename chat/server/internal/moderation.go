@@ -0,0 +1,241 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+type ban struct {
+	// until is zero for bans without expiration.
+	until  time.Time
+	reason string
+}
+
+func (b ban) active(now time.Time) bool {
+	return b.until.IsZero() || now.Before(b.until)
+}
+
+// details describes the expiration and the reason of the ban.
+func (b ban) details() string {
+	s := ""
+	if !b.until.IsZero() {
+		s += fmt.Sprintf(" until %s", b.until.Format(time.RFC3339))
+	}
+	if b.reason != "" {
+		s += fmt.Sprintf(": %s", b.reason)
+	}
+
+	return s
+}
+
+// rank orders the roles by privilege.
+func rank(role pb.Role) int {
+	switch role {
+	case pb.Role_Moderator:
+		return 2
+	case pb.Role_Owner:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// WithOwner grants the owner role to username.
+func WithOwner(username string) RoomOption {
+	return func(r *room) {
+		r.roles[username] = pb.Role_Owner
+	}
+}
+
+func (r *room) Role(username string) pb.Role {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.roles[username]
+}
+
+// SetRole grants role to username. Only owners can grant the moderator and
+// owner roles. A nil actor stands for the server operators, who are always
+// allowed. Muted is not a role: see Mute.
+func (r *room) SetRole(actor *Participant, username string, role pb.Role) error {
+	if _, ok := pb.Role_name[int32(role)]; !ok || role == pb.Role_Muted {
+		return fmt.Errorf("%w: invalid role %s", ErrInvalidArgument, role)
+	}
+
+	r.mu.Lock()
+	if actor != nil {
+		if err := r.checkModerator(actor.username, username); err != nil {
+			r.mu.Unlock()
+
+			return err
+		}
+		if rank(role) >= rank(pb.Role_Moderator) && r.roles[actor.username] != pb.Role_Owner {
+			r.mu.Unlock()
+
+			return fmt.Errorf("%w: only owners can grant the %s role", ErrPermissionDenied, role)
+		}
+	}
+	r.setRole(username, role)
+	r.mu.Unlock()
+	r.publishChange(eventRole, roomChange{Username: username, Role: role})
+
+	return nil
+}
+
+// setRole grants role to username. The caller must hold r.mu.
+func (r *room) setRole(username string, role pb.Role) {
+	if role == pb.Role_Member {
+		delete(r.roles, username)
+
+		return
+	}
+	r.roles[username] = role
+}
+
+// Mute prevents username from writing in the room, or allows it again,
+// keeping its role. A nil actor stands for the server operators.
+func (r *room) Mute(actor *Participant, username string, muted bool) error {
+	r.mu.Lock()
+	if actor != nil {
+		if err := r.checkModerator(actor.username, username); err != nil {
+			r.mu.Unlock()
+
+			return err
+		}
+	}
+	r.setMuted(username, muted)
+	r.mu.Unlock()
+	r.publishChange(eventMute, roomChange{Username: username, Muted: muted})
+
+	return nil
+}
+
+// setMuted mutes or unmutes username. The caller must hold r.mu.
+func (r *room) setMuted(username string, muted bool) {
+	if muted {
+		r.muted[username] = true
+
+		return
+	}
+	delete(r.muted, username)
+}
+
+// Kick disconnects every participant of the room connected as username.
+func (r *room) Kick(actor *Participant, username string, reason string) error {
+	r.mu.Lock()
+	if actor != nil {
+		if err := r.checkModerator(actor.username, username); err != nil {
+			r.mu.Unlock()
+
+			return err
+		}
+	}
+	r.mu.Unlock()
+
+	kicked := r.participantsNamed(username)
+	if len(kicked) == 0 {
+		return fmt.Errorf("%w: %s is not in room %s", ErrParticipantNotFound, username, r.name)
+	}
+	msg := fmt.Sprintf("kicked from room %s", r.name)
+	if reason != "" {
+		msg += fmt.Sprintf(": %s", reason)
+	}
+	for _, p := range kicked {
+		p.disconnect(r, msg)
+	}
+
+	return nil
+}
+
+// Ban prevents username from joining the room for d, or forever when d is
+// zero, disconnecting the participants already connected as username.
+func (r *room) Ban(actor *Participant, username string, d time.Duration, reason string) error {
+	if d < 0 {
+		return fmt.Errorf("%w: negative ban duration", ErrInvalidArgument)
+	}
+
+	r.mu.Lock()
+	if actor != nil {
+		if err := r.checkModerator(actor.username, username); err != nil {
+			r.mu.Unlock()
+
+			return err
+		}
+	}
+	r.mu.Unlock()
+	b := ban{reason: reason}
+	if d > 0 {
+		b.until = time.Now().Add(d)
+	}
+	r.ban(username, b)
+	r.publishChange(eventBan, roomChange{Username: username, Until: b.until, Value: reason})
+
+	return nil
+}
+
+// ban bans username with b, disconnecting the participants connected as
+// username.
+func (r *room) ban(username string, b ban) {
+	r.mu.Lock()
+	r.bans[username] = b
+	r.mu.Unlock()
+
+	msg := fmt.Sprintf("banned from room %s%s", r.name, b.details())
+	for _, p := range r.participantsNamed(username) {
+		p.disconnect(r, msg)
+	}
+}
+
+// Unban lifts the ban of username, if any.
+func (r *room) Unban(username string) {
+	r.mu.Lock()
+	_, banned := r.bans[username]
+	delete(r.bans, username)
+	r.mu.Unlock()
+	if banned {
+		r.publishChange(eventUnban, roomChange{Username: username})
+	}
+}
+
+// checkBan returns an error when username is banned. The caller must hold
+// r.mu.
+func (r *room) checkBan(username string) error {
+	b, ok := r.bans[username]
+	if !ok {
+		return nil
+	}
+	if !b.active(time.Now()) {
+		delete(r.bans, username)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w from room %s%s", ErrBanned, r.name, b.details())
+}
+
+// checkModerator returns an error unless actor can moderate target. The
+// caller must hold r.mu.
+func (r *room) checkModerator(actor, target string) error {
+	actorRole := r.roles[actor]
+	if rank(actorRole) < rank(pb.Role_Moderator) {
+		return fmt.Errorf("%w: moderator role required in room %s", ErrPermissionDenied, r.name)
+	}
+	if rank(r.roles[target]) >= rank(actorRole) {
+		return fmt.Errorf("%w: cannot moderate %s", ErrPermissionDenied, target)
+	}
+
+	return nil
+}
+
+func (r *room) participantsNamed(username string) []*Participant {
+	var participants []*Participant
+	for _, p := range copyParticipants(r) {
+		if p.username == username {
+			participants = append(participants, p)
+		}
+	}
+
+	return participants
+}
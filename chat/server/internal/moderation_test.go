@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestMute(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Role is granted to bob before he is muted.
+		Role      pb.Role
+		Unmute    bool
+		WantMuted bool
+	}{
+		{
+			Name:      "member",
+			WantMuted: true,
+		},
+		{
+			Name:      "moderator keeps the role",
+			Role:      pb.Role_Moderator,
+			WantMuted: true,
+		},
+		{
+			Name:   "unmuted moderator",
+			Role:   pb.Role_Moderator,
+			Unmute: true,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			id, err := rm.CreateRoom("lobby")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			if err := r.SetRole(nil, "bob", tt.Role); err != nil {
+				t.Fatalf("SetRole failed: %v", err)
+			}
+			if err := r.Mute(nil, "bob", true); err != nil {
+				t.Fatalf("Mute failed: %v", err)
+			}
+			if tt.Unmute {
+				if err := r.Mute(nil, "bob", false); err != nil {
+					t.Fatalf("Mute failed: %v", err)
+				}
+			}
+
+			bob, _ := NewParticipant("bob", Limits{})
+			defer testSession(bob)()
+			if err := bob.JoinRoom(r, ""); err != nil {
+				t.Fatalf("JoinRoom failed: %v", err)
+			}
+			op, err := pbutils.MarshalAny(&pb.ClientMessage_ClientWriteMessage{Body: "hi"})
+			if err != nil {
+				t.Fatalf("MarshalAny failed: %v", err)
+			}
+			err = r.checkWrite(RoomMessage{CMsgP: &pb.ClientMessage{Command: pb.ClientMessage_WriteMessage, Operation: op}, Participant: bob})
+			if muted := errors.Is(err, ErrPermissionDenied); muted != tt.WantMuted {
+				t.Errorf("muted %t, want %t: %v", muted, tt.WantMuted, err)
+			}
+			if got := r.Role("bob"); got != tt.Role {
+				t.Errorf("got role %s, want %s", got, tt.Role)
+			}
+		})
+	}
+}
+
+func TestSetRoleInvalid(t *testing.T) {
+	rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+	if err != nil {
+		t.Fatalf("NewRoomManager failed: %v", err)
+	}
+	defer rm.Close()
+	id, err := rm.CreateRoom("lobby")
+	if err != nil {
+		t.Fatalf("CreateRoom failed: %v", err)
+	}
+	r, _ := rm.GetRoom(id)
+	for _, role := range []pb.Role{pb.Role_Muted, pb.Role(42)} {
+		if err := r.SetRole(nil, "bob", role); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("SetRole(%s) returned %v, want %v", role, err, ErrInvalidArgument)
+		}
+	}
+}
@@ -1,41 +1,256 @@
 package server
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	pbutils "github.com/golang/protobuf/ptypes"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
 	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
 )
 
+// outBuffer is the number of messages queued to a participant before the
+// senders block.
+const outBuffer = 16
+
 type participantID string
 
+// Participant is a user connected to the server. The rooms and the session of
+// the participant access it from several goroutines: the room it is in, its
+// waiting queue and its state are guarded by mu, which is always acquired
+// after the mutex of a room, never before.
 type Participant struct {
 	id       participantID
 	username string
 
-	CurrentRoom *room
+	mu   sync.Mutex
+	room *room
+	// queuedIn is the room the participant is waiting to join, if any.
+	queuedIn *room
+	closed   bool
+	// rm is the manager the participant is connected to, set by Connect.
+	rm *RoomManager
+	// done is closed by Close, releasing the goroutines sending to Out.
+	done chan struct{}
 
+	// Out carries the messages for the participant. Use Send to write to it.
 	Out chan *pb.ServerMessage
 
-	DisconnectChan chan interface{}
+	// DisconnectChan receives the reason of a server-side disconnection. Only
+	// the first reason is kept.
+	DisconnectChan chan string
+
+	// The rate limits are guarded by mu.
+	messagesBucket *tokenBucket
+	bytesBucket    *tokenBucket
+	offences       *offences
 }
 
-func (p Participant) String() string {
+func (p *Participant) String() string {
 	return string(p.id)
 }
 
-func (p *Participant) disconnect() {
-	log.Debugf("Disconnetting participant %s", p.id)
-	p.CurrentRoom.removeParticipant(p.id)
-	p.DisconnectChan <- struct{}{}
+func (p *Participant) Username() string {
+	return p.username
+}
+
+// CurrentRoom returns the room the participant is in, or nil.
+func (p *Participant) CurrentRoom() *room {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.room
+}
+
+func (p *Participant) Info() *pb.ParticipantInfo {
+	info := &pb.ParticipantInfo{
+		Id:       string(p.id),
+		Username: p.username,
+	}
+	if r := p.CurrentRoom(); r != nil {
+		info.RoomId = string(r.id)
+	}
+
+	return info
+}
+
+// JoinRoom moves the participant from its current room, if any, to r. When r
+// is full and queues the participant, ErrQueued is returned and the
+// participant is moved once admitted.
+func (p *Participant) JoinRoom(r *room, inviteCode string) error {
+	prev, prevQueue, err := r.addParticipant(p, inviteCode)
+	if prevQueue != nil && prevQueue != r {
+		prevQueue.dequeue(p)
+	}
+	if err != nil {
+		return err
+	}
+	if prev != nil {
+		prev.removeParticipant(p.id)
+	}
+
+	return nil
+}
+
+// Close removes the participant from its room and its waiting queue, and
+// releases the goroutines blocked sending to it. It is called once the
+// session of the participant is over.
+func (p *Participant) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return
+	}
+	p.closed = true
+	close(p.done)
+	r, q, rm := p.room, p.queuedIn, p.rm
+	p.queuedIn = nil
+	p.mu.Unlock()
+
+	if rm != nil {
+		rm.disconnect(p)
+	}
+	if q != nil {
+		q.dequeue(p)
+	}
+	if r != nil {
+		r.removeParticipant(p.id)
+	}
+}
+
+// Send queues sMsgP to the participant, reporting whether it was queued. It
+// blocks while the buffer of the participant is full, until the participant
+// is closed.
+func (p *Participant) Send(sMsgP *pb.ServerMessage) bool {
+	select {
+	case p.Out <- sMsgP:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// ConfirmRoomCheckout notifies the participant that it joined its current
+// room.
+func (p *Participant) ConfirmRoomCheckout() {
+	r := p.CurrentRoom()
+	if r == nil {
+		return
+	}
+	confirmRoomMsg := pb.ServerMessage_ServerConfirmRoomCheckout{
+		Room: r.Info(),
+	}
+	op, err := pbutils.MarshalAny(&confirmRoomMsg)
+	if err != nil {
+		p.logger().Errorf("Marshal from confirmRoomCheckout failed: %v", err)
+
+		return
+	}
+	p.Send(&pb.ServerMessage{
+		Command:   pb.ServerMessage_ConfirmRoomCheckout,
+		Operation: op,
+	})
+}
+
+// SendError reports to the participant that cmd failed with err.
+func (p *Participant) SendError(cmd pb.ClientMessage_ClientCommand, err error) {
+	sMsgP, mErr := NewErrorMessage(cmd, err)
+	if mErr != nil {
+		p.logger().Errorf("Marshal from error failed: %v", mErr)
+
+		return
+	}
+	p.Send(sMsgP)
+}
+
+// Disconnect removes the participant from its room and asks its session to
+// end, notifying reason. It never blocks.
+func (p *Participant) Disconnect(reason string) {
+	p.disconnect(nil, reason)
+}
+
+// disconnect is Disconnect, ignored when from is not nil and the participant
+// is no longer in from.
+func (p *Participant) disconnect(from *room, reason string) {
+	r := p.CurrentRoom()
+	if from != nil && r != from {
+		return
+	}
+	p.logger().Debugf("Disconnetting participant %s: %s", p.id, reason)
+	if r != nil {
+		r.removeParticipant(p.id)
+	}
+	select {
+	case p.DisconnectChan <- reason:
+	default:
+		// A disconnection is already pending.
+	}
 }
 
-func NewParticipant(username string) (*Participant, error) {
+// logger returns the logger of the manager p is connected to.
+func (p *Participant) logger() log.FieldLogger {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rm == nil {
+		return log.StandardLogger()
+	}
+
+	return p.rm.log
+}
+
+// leaveQueue clears the waiting queue of the participant when it is r,
+// reporting whether the participant is in no room.
+func (p *Participant) leaveQueue(r *room) (roomless bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.queuedIn != r {
+		return false
+	}
+	p.queuedIn = nil
+
+	return p.room == nil
+}
+
+// errParticipantClosed is returned when joining a room with a closed
+// participant.
+var errParticipantClosed = errors.New("participant closed")
+
+// checkJoin returns an error unless the participant can join r. The caller
+// must hold p.mu.
+func (p *Participant) checkJoin(r *room) error {
+	if p.closed {
+		return errParticipantClosed
+	}
+	if p.room == r {
+		return fmt.Errorf("%w: already in room %s", ErrInvalidArgument, r.name)
+	}
+
+	return nil
+}
+
+// NewParticipant creates the participant of a user named username, which
+// cannot contain "@": the users of the federated servers are attributed as
+// user@server.
+func NewParticipant(username string, limits Limits) (*Participant, error) {
+	if strings.Contains(username, "@") {
+		return nil, fmt.Errorf("%w: invalid username %q, it cannot contain @", ErrInvalidArgument, username)
+	}
 	p := &Participant{
 		id:             participantID(uuid.New().String()),
 		username:       username,
-		Out:            make(chan *pb.ServerMessage),
-		DisconnectChan: make(chan interface{}),
+		done:           make(chan struct{}),
+		Out:            make(chan *pb.ServerMessage, outBuffer),
+		DisconnectChan: make(chan string, 1),
+		messagesBucket: newTokenBucket(limits.ParticipantMessages, limits.ParticipantMessagesBurst),
+		bytesBucket:    newTokenBucket(limits.ParticipantBytes, limits.ParticipantBytesBurst),
+		offences:       &offences{window: limits.MuteWindow},
 	}
 
 	return p, nil
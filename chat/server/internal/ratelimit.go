@@ -0,0 +1,298 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limits configures the limits enforced on the messages written in the
+// rooms. A zero rate disables the corresponding limit.
+type Limits struct {
+	// ParticipantMessages is the number of messages per second a participant
+	// can write, with bursts of ParticipantMessagesBurst.
+	ParticipantMessages      float64
+	ParticipantMessagesBurst int
+	// ParticipantBytes is the number of body bytes per second a participant
+	// can write, with bursts of ParticipantBytesBurst. The burst cannot be
+	// lower than MaxMessageBytes.
+	ParticipantBytes      float64
+	ParticipantBytesBurst int
+	// RoomMessages is the number of messages per second a room forwards,
+	// with bursts of RoomMessagesBurst.
+	RoomMessages      float64
+	RoomMessagesBurst int
+
+	// MaxMessageBytes is the maximum size of a message body. Zero disables
+	// the check.
+	MaxMessageBytes int
+
+	// A participant rate limited MuteAfter times within MuteWindow is muted
+	// for MuteFor. A zero MuteAfter disables the automatic mute.
+	MuteAfter  int
+	MuteWindow time.Duration
+	MuteFor    time.Duration
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		ParticipantMessages:      5,
+		ParticipantMessagesBurst: 10,
+		ParticipantBytes:         4096,
+		ParticipantBytesBurst:    16384,
+		RoomMessages:             50,
+		RoomMessagesBurst:        100,
+		MaxMessageBytes:          4096,
+		MuteAfter:                5,
+		MuteWindow:               time.Minute,
+		MuteFor:                  5 * time.Minute,
+	}
+}
+
+// Validate returns an error when the limits are inconsistent.
+func (l Limits) Validate() error {
+	if l.ParticipantBytes > 0 && l.MaxMessageBytes > 0 && l.ParticipantBytesBurst < l.MaxMessageBytes {
+		return fmt.Errorf("%w: the participant byte burst %d is lower than the maximum message size %d", ErrInvalidArgument, l.ParticipantBytesBurst, l.MaxMessageBytes)
+	}
+
+	return nil
+}
+
+// tokenBucket holds up to burst tokens, refilled at rate tokens per second.
+// A nil tokenBucket allows everything.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func (b *tokenBucket) allow(now time.Time, n int) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	if float64(n) > b.tokens {
+		return false
+	}
+	b.tokens -= float64(n)
+
+	return true
+}
+
+// refund gives back n tokens taken from b, up to its burst.
+func (b *tokenBucket) refund(n int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += float64(n)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// refill adds the tokens earned until now. The caller must hold b.mu.
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// setRate returns b refilled at rate with bursts of burst from now on,
+// keeping its tokens up to the new burst. A nil b is created full, and a
+// zero rate returns nil.
+func (b *tokenBucket) setRate(now time.Time, rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if b == nil {
+		return newTokenBucket(rate, burst)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	b.rate = rate
+	b.burst = float64(burst)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	return b
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// offences counts the times a participant was rate limited within a window.
+type offences struct {
+	window time.Duration
+	start  time.Time
+	count  int
+	mu     sync.Mutex
+}
+
+// add records an offence at now and returns the offences in the window.
+func (o *offences) add(now time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if now.Sub(o.start) > o.window {
+		o.start = now
+		o.count = 0
+	}
+	o.count++
+
+	return o.count
+}
+
+func (o *offences) setWindow(window time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.window = window
+}
+
+// checkLimits returns an error when a message of size bytes written by p
+// exceeds the limits of the room, muting p when it keeps exceeding its own.
+// The tokens of p are only spent on the messages allowed.
+func (r *room) checkLimits(p *Participant, size int) error {
+	now := time.Now()
+	r.mu.Lock()
+	until, muted := r.autoMuted[p.username]
+	if muted && !now.Before(until) {
+		delete(r.autoMuted, p.username)
+		muted = false
+	}
+	limits, roomBucket := r.limits, r.messagesBucket
+	r.mu.Unlock()
+	if muted {
+		return fmt.Errorf("%w: you are muted in room %s until %s", ErrPermissionDenied, r.name, until.Format(time.Kitchen))
+	}
+
+	if limits.MaxMessageBytes > 0 && size > limits.MaxMessageBytes {
+		return fmt.Errorf("%w: messages cannot be longer than %d bytes", ErrInvalidArgument, limits.MaxMessageBytes)
+	}
+	messagesBucket, bytesBucket, offences := p.rateLimits()
+	allowed := messagesBucket.allow(now, 1)
+	if allowed && !bytesBucket.allow(now, size) {
+		messagesBucket.refund(1)
+		allowed = false
+	}
+	if !allowed {
+		if n := offences.add(now); limits.MuteAfter > 0 && n >= limits.MuteAfter {
+			r.autoMute(p.username, now.Add(limits.MuteFor))
+		}
+
+		return fmt.Errorf("%w: slow down", ErrRateLimited)
+	}
+	if !roomBucket.allow(now, 1) {
+		// p is not to blame for a busy room.
+		messagesBucket.refund(1)
+		bytesBucket.refund(size)
+
+		return fmt.Errorf("%w: room %s is too busy", ErrRateLimited, r.name)
+	}
+
+	return nil
+}
+
+func (r *room) autoMute(username string, until time.Time) {
+	r.mu.Lock()
+	r.autoMuted[username] = until
+	r.mu.Unlock()
+
+	r.rm.log.Infof("Participant %s muted in room %s until %s for flooding", username, r.name, until)
+	// autoMute runs in the room goroutine, which must not wait for the
+	// participants to receive the announcement.
+	go func() {
+		if err := r.Announce(fmt.Sprintf("%s was muted for flooding", username)); err != nil {
+			r.rm.log.Errorf("Announce to room %s failed: %v", r.name, err)
+		}
+	}()
+}
+
+// Limits returns the limits of the rooms and of the participants.
+func (rm *RoomManager) Limits() Limits {
+	rm.limitsMu.Lock()
+	defer rm.limitsMu.Unlock()
+
+	return rm.limits
+}
+
+// SetLimits changes the limits of the rooms and of the connected
+// participants. Their rate limits apply the new rates from now on, keeping
+// the tokens and the offences counted so far.
+func (rm *RoomManager) SetLimits(limits Limits) error {
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+	rm.limitsMu.Lock()
+	rm.limits = limits
+	rm.limitsMu.Unlock()
+
+	now := time.Now()
+	for _, r := range rm.ListRooms() {
+		r.mu.Lock()
+		r.limits = limits
+		r.messagesBucket = r.messagesBucket.setRate(now, limits.RoomMessages, limits.RoomMessagesBurst)
+		r.mu.Unlock()
+	}
+	rm.onlineMu.Lock()
+	var online []*Participant
+	for _, participants := range rm.online {
+		for _, p := range participants {
+			online = append(online, p)
+		}
+	}
+	rm.onlineMu.Unlock()
+	for _, p := range online {
+		p.setLimits(now, limits)
+	}
+
+	return nil
+}
+
+// rateLimits returns the buckets and the offences of p.
+func (p *Participant) rateLimits() (messagesBucket, bytesBucket *tokenBucket, o *offences) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.messagesBucket, p.bytesBucket, p.offences
+}
+
+func (p *Participant) setLimits(now time.Time, limits Limits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messagesBucket = p.messagesBucket.setRate(now, limits.ParticipantMessages, limits.ParticipantMessagesBurst)
+	p.bytesBucket = p.bytesBucket.setRate(now, limits.ParticipantBytes, limits.ParticipantBytesBurst)
+	p.offences.setWindow(limits.MuteWindow)
+}
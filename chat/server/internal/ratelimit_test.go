@@ -0,0 +1,219 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// bucketCall requests N tokens at the given offset from the start of a test.
+type bucketCall struct {
+	At   time.Duration
+	N    int
+	Want bool
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	testsTable := []struct {
+		Name  string
+		Rate  float64
+		Burst int
+		Calls []bucketCall
+	}{
+		{
+			Name:  "burst then refill",
+			Rate:  1,
+			Burst: 2,
+			Calls: []bucketCall{
+				{At: 0, N: 1, Want: true},
+				{At: 0, N: 1, Want: true},
+				{At: 0, N: 1, Want: false},
+				{At: time.Second, N: 1, Want: true},
+				{At: time.Second, N: 1, Want: false},
+				{At: 10 * time.Second, N: 2, Want: true},
+			},
+		},
+		{
+			Name:  "larger than burst",
+			Rate:  100,
+			Burst: 10,
+			Calls: []bucketCall{
+				{At: 0, N: 11, Want: false},
+				{At: 0, N: 10, Want: true},
+			},
+		},
+		{
+			Name:  "disabled",
+			Rate:  0,
+			Burst: 0,
+			Calls: []bucketCall{
+				{At: 0, N: 1000, Want: true},
+			},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			b := newTokenBucket(tt.Rate, tt.Burst)
+			if b != nil {
+				b.last = now
+			}
+			for i, c := range tt.Calls {
+				if got := b.allow(now.Add(c.At), c.N); got != c.Want {
+					t.Errorf("call %d: allow(%s, %d)=%t; want %t", i, c.At, c.N, got, c.Want)
+				}
+			}
+		})
+	}
+}
+
+func TestOffences(t *testing.T) {
+	now := time.Now()
+	o := offences{window: time.Minute}
+	for i := 1; i <= 3; i++ {
+		if got := o.add(now.Add(time.Duration(i) * time.Second)); got != i {
+			t.Errorf("add #%d=%d; want %d", i, got, i)
+		}
+	}
+	if got := o.add(now.Add(2 * time.Minute)); got != 1 {
+		t.Errorf("add after the window=%d; want 1", got)
+	}
+}
+
+func TestLimitsValidate(t *testing.T) {
+	testsTable := []struct {
+		Name    string
+		Limits  Limits
+		WantErr error
+	}{
+		{
+			Name:   "default",
+			Limits: DefaultLimits(),
+		},
+		{
+			Name:    "byte burst lower than a message",
+			Limits:  Limits{ParticipantBytes: 10, ParticipantBytesBurst: 100, MaxMessageBytes: 101},
+			WantErr: ErrInvalidArgument,
+		},
+		{
+			Name:   "byte rate disabled",
+			Limits: Limits{MaxMessageBytes: 101},
+		},
+		{
+			Name:   "message size unlimited",
+			Limits: Limits{ParticipantBytes: 10, ParticipantBytesBurst: 100},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Limits.Validate(); !errors.Is(err, tt.WantErr) {
+				t.Errorf("got error %v, want %v", err, tt.WantErr)
+			}
+		})
+	}
+}
+
+func TestParticipantSetLimits(t *testing.T) {
+	limits := Limits{ParticipantMessages: 1, ParticipantMessagesBurst: 2, MuteWindow: time.Minute}
+	p, err := NewParticipant("alice", limits)
+	if err != nil {
+		t.Fatalf("NewParticipant failed: %v", err)
+	}
+	now := time.Now()
+	messagesBucket, _, o := p.rateLimits()
+	if !messagesBucket.allow(now, 2) {
+		t.Fatal("burst not allowed")
+	}
+	o.add(now)
+
+	limits.ParticipantMessagesBurst = 5
+	p.setLimits(now, limits)
+	messagesBucket, _, o = p.rateLimits()
+	if messagesBucket.allow(now, 1) {
+		t.Error("tokens refilled by the new limits")
+	}
+	if n := o.add(now); n != 2 {
+		t.Errorf("got %d offences, want 2", n)
+	}
+	if !messagesBucket.allow(now.Add(5*time.Second), 5) {
+		t.Error("new burst not allowed")
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	// The buckets do not refill during the test.
+	limits := Limits{
+		ParticipantMessages:      0.001,
+		ParticipantMessagesBurst: 1,
+		ParticipantBytes:         0.001,
+		ParticipantBytesBurst:    50,
+		RoomMessages:             0.001,
+		RoomMessagesBurst:        1,
+		MuteWindow:               time.Minute,
+	}
+	testsTable := []struct {
+		Name string
+		// Busy is set when the tokens of the room are spent.
+		Busy         bool
+		Size         int
+		WantErr      error
+		WantOffences int
+	}{
+		{
+			Name: "allowed",
+			Size: 10,
+		},
+		{
+			Name:    "busy room",
+			Busy:    true,
+			Size:    10,
+			WantErr: ErrRateLimited,
+		},
+		{
+			Name:         "too many bytes",
+			Size:         100,
+			WantErr:      ErrRateLimited,
+			WantOffences: 1,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm, err := NewRoomManager(limits, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			id, err := rm.CreateRoom("test")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			if tt.Busy {
+				r.messagesBucket.allow(time.Now(), 1)
+			}
+			p, err := NewParticipant("alice", limits)
+			if err != nil {
+				t.Fatalf("NewParticipant failed: %v", err)
+			}
+			defer p.Close()
+
+			if err := r.checkLimits(p, tt.Size); !errors.Is(err, tt.WantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			// The tokens of alice are only spent on the messages allowed.
+			messagesBucket, _, o := p.rateLimits()
+			if got := messagesBucket.allow(time.Now(), 1); got != (tt.WantErr != nil) {
+				t.Errorf("got a token left %t, want %t", got, tt.WantErr != nil)
+			}
+			if got := o.add(time.Now()) - 1; got != tt.WantOffences {
+				t.Errorf("got %d offences, want %d", got, tt.WantOffences)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// MarkRead records that p read the room up to the message id, or up to the
+// last message when id is zero, returning the resulting marker. When share
+// is set, the participants of the room receive a read receipt, which only
+// the members of the room can share.
+func (r *room) MarkRead(p *Participant, id store.MessageID, share bool) (store.MessageID, error) {
+	if !r.VisibleTo(p.username) {
+		return 0, fmt.Errorf("%w: %s", ErrRoomNotFound, r.name)
+	}
+	if share && !r.hasMember(p.username) {
+		return 0, fmt.Errorf("%w: not a member of room %s", ErrPermissionDenied, r.name)
+	}
+	r.mu.Lock()
+	last := r.lastMessageID
+	r.mu.Unlock()
+	if id == 0 {
+		id = last
+	}
+	if id > last {
+		return 0, fmt.Errorf("%w: no message %d in room %s", ErrInvalidArgument, id, r.name)
+	}
+
+	marker, err := r.rm.store.SetReadMarker(p.username, string(r.id), id)
+	if err != nil {
+		return 0, fmt.Errorf("store failed: %w", err)
+	}
+	if share {
+		if err := r.broadcastReceipt(p.username, marker); err != nil {
+			return marker, err
+		}
+	}
+	r.publishReadMarker(p.username, marker, share)
+
+	return marker, nil
+}
+
+// publishReadMarker publishes the read marker of username to the replicas,
+// which store the messages under other IDs: the marker is sent as the time
+// of its message.
+func (r *room) publishReadMarker(username string, marker store.MessageID, share bool) {
+	if marker == 0 {
+		return
+	}
+	messages, _, err := r.rm.store.History(string(r.id), store.HistoryQuery{AfterID: marker - 1, Limit: 1})
+	if err != nil || len(messages) == 0 || messages[0].ID != marker {
+		r.rm.log.Warnf("Read marker %d of room %s not published: message not found", marker, r.name)
+
+		return
+	}
+	r.publishChange(eventReadMarker, roomChange{Username: username, ReadUpTo: messages[0].SentAt, Share: share})
+}
+
+// applyReadMarker moves the read marker of username to the last message sent
+// at or before upTo, as published by another replica.
+func (r *room) applyReadMarker(username string, upTo time.Time, share bool) {
+	messages, _, err := r.rm.store.History(string(r.id), store.HistoryQuery{Before: upTo.Add(time.Nanosecond), Limit: 1})
+	if err != nil {
+		r.rm.log.Errorf("Reading the history of room %s failed: %v", r.name, err)
+
+		return
+	}
+	if len(messages) == 0 {
+		return
+	}
+	marker, err := r.rm.store.SetReadMarker(username, string(r.id), messages[0].ID)
+	if err != nil {
+		r.rm.log.Errorf("Storing the read marker of %s in room %s failed: %v", username, r.name, err)
+
+		return
+	}
+	if share {
+		if err := r.broadcastReceipt(username, marker); err != nil {
+			r.rm.log.Errorf("Broadcasting the read receipt of %s failed: %v", username, err)
+		}
+	}
+}
+
+// Unread returns the number of messages of the room username has not read.
+func (r *room) Unread(username string) (int, error) {
+	n, err := r.rm.store.CountUnread(username, string(r.id))
+	if err != nil {
+		return 0, fmt.Errorf("store failed: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *room) broadcastReceipt(username string, id store.MessageID) error {
+	op, err := pbutils.MarshalAny(&pb.ServerMessage_ServerReadReceipt{
+		RoomId:    string(r.id),
+		Username:  username,
+		MessageId: uint64(id),
+		ReadAt:    timestamppb.New(time.Now()),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal from readReceipt failed: %w", err)
+	}
+	for _, p := range copyParticipants(r) {
+		p.Send(&pb.ServerMessage{
+			Command:   pb.ServerMessage_ReadReceipt,
+			Operation: op,
+		})
+	}
+
+	return nil
+}
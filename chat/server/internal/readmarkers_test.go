@@ -0,0 +1,149 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestMarkRead(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Marks are the indexes of the messages marked in turn, -1 for zero.
+		Marks      []int
+		WantMarker int
+		WantUnread int
+		WantErr    error
+	}{
+		{
+			Name:       "unread",
+			WantMarker: -1,
+			WantUnread: 3,
+		},
+		{
+			Name:       "up to the last message",
+			Marks:      []int{-1},
+			WantMarker: 2,
+		},
+		{
+			Name:       "up to a message",
+			Marks:      []int{0},
+			WantMarker: 0,
+			WantUnread: 2,
+		},
+		{
+			Name:       "never backward",
+			Marks:      []int{1, 0},
+			WantMarker: 1,
+			WantUnread: 1,
+		},
+		{
+			Name:       "unknown message",
+			Marks:      []int{3},
+			WantMarker: -1,
+			WantUnread: 3,
+			WantErr:    ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			rID, err := rm.CreateRoom("test")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(rID)
+			alice, _ := NewParticipant("alice", Limits{})
+			bob, _ := NewParticipant("bob", Limits{})
+			for _, p := range []*Participant{alice, bob} {
+				if err := p.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+			}
+			defer alice.Close()
+			defer bob.Close()
+
+			// The IDs of the messages, with the one past the last.
+			ids := make([]store.MessageID, 0, 4)
+			for _, body := range []string{"one", "two", "three"} {
+				if err := writeMessage(t, alice, r, body); err != nil {
+					t.Fatalf("Write failed: %v", err)
+				}
+				<-alice.Out
+				<-bob.Out
+				r.mu.Lock()
+				ids = append(ids, r.lastMessageID)
+				r.mu.Unlock()
+			}
+			ids = append(ids, ids[2]+1)
+
+			for i, mark := range tt.Marks {
+				id := store.MessageID(0)
+				if mark >= 0 {
+					id = ids[mark]
+				}
+				_, err := r.MarkRead(bob, id, false)
+				if i == len(tt.Marks)-1 && !errors.Is(err, tt.WantErr) {
+					t.Errorf("MarkRead: got %v, want %v", err, tt.WantErr)
+				}
+			}
+
+			wantMarker := store.MessageID(0)
+			if tt.WantMarker >= 0 {
+				wantMarker = ids[tt.WantMarker]
+			}
+			if marker, _ := rm.store.ReadMarker("bob", string(rID)); marker != wantMarker {
+				t.Errorf("marker: got %d, want %d", marker, wantMarker)
+			}
+			if unread, _ := r.Unread("bob"); unread != tt.WantUnread {
+				t.Errorf("bob unread: got %d, want %d", unread, tt.WantUnread)
+			}
+			if unread, _ := r.Unread("alice"); unread != 0 {
+				t.Errorf("alice unread: got %d, want 0", unread)
+			}
+		})
+	}
+}
+
+func TestShareReadReceipt(t *testing.T) {
+	testsTable := []struct {
+		Name    string
+		Member  bool
+		WantErr error
+	}{
+		{
+			Name:   "member",
+			Member: true,
+		},
+		{
+			Name:    "not a member",
+			WantErr: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm := newStressManager(t)
+			defer rm.Close()
+			rID, err := rm.CreateRoom("test")
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(rID)
+			bob, _ := NewParticipant("bob", Limits{})
+			defer testSession(bob)()
+			if tt.Member {
+				if err := bob.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+			}
+
+			if _, err := r.MarkRead(bob, 0, true); !errors.Is(err, tt.WantErr) {
+				t.Errorf("got error %v, want %v", err, tt.WantErr)
+			}
+		})
+	}
+}
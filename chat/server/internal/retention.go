@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// WithRetention sets the retention of the history of the room. The zero
+// Retention applies the default of the manager.
+func WithRetention(retention store.Retention) RoomOption {
+	return func(r *room) {
+		r.retention = retention
+	}
+}
+
+// RetentionFromProto converts the retention of a request.
+func RetentionFromProto(retention *pb.Retention) store.Retention {
+	if retention == nil {
+		return store.Retention{}
+	}
+
+	return store.Retention{
+		MaxAge:      retention.MaxAge.AsDuration(),
+		MaxMessages: int(retention.MaxMessages),
+		MaxBytes:    int(retention.MaxBytes),
+	}
+}
+
+func retentionInfo(retention store.Retention) *pb.Retention {
+	if retention.IsZero() {
+		return nil
+	}
+	info := &pb.Retention{
+		MaxMessages: uint32(retention.MaxMessages),
+		MaxBytes:    uint64(retention.MaxBytes),
+	}
+	if retention.MaxAge > 0 {
+		info.MaxAge = durationpb.New(retention.MaxAge)
+	}
+
+	return info
+}
+
+func checkRetention(retention store.Retention) error {
+	if retention.MaxAge < 0 || retention.MaxMessages < 0 || retention.MaxBytes < 0 {
+		return fmt.Errorf("%w: negative retention", ErrInvalidArgument)
+	}
+
+	return nil
+}
+
+// effectiveRetention returns the retention enforced on the history of the
+// room. The caller must hold r.mu.
+func (r *room) effectiveRetention() store.Retention {
+	if !r.retention.IsZero() {
+		return r.retention
+	}
+
+	return r.rm.lifecycle.Retention
+}
+
+// SetRetention changes the retention of the history of the room, enforced
+// by the next compaction. The zero Retention applies the default of the
+// manager.
+func (r *room) SetRetention(retention store.Retention) error {
+	if err := checkRetention(retention); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev := r.retention
+	r.retention = retention
+	if err := r.rm.store.SetRetention(string(r.id), r.effectiveRetention()); err != nil {
+		r.retention = prev
+
+		return fmt.Errorf("store failed: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeRoom drops the history of the room roomID, which can be closed,
+// returning how many messages were dropped.
+func (rm *RoomManager) PurgeRoom(roomID string) (int, error) {
+	dropped, err := rm.store.PurgeRoom(roomID)
+	if err != nil {
+		return 0, fmt.Errorf("store failed: %w", err)
+	}
+	rm.index.Remove(dropped)
+
+	return len(dropped), nil
+}
+
+// PurgeAuthor drops the messages written by author and the mail it sent,
+// returning how many messages were dropped.
+func (rm *RoomManager) PurgeAuthor(author string) (int, error) {
+	if author == "" {
+		return 0, fmt.Errorf("%w: empty author", ErrInvalidArgument)
+	}
+	dropped, err := rm.store.PurgeAuthor(author)
+	if err != nil {
+		return 0, fmt.Errorf("store failed: %w", err)
+	}
+	rm.index.Remove(dropped)
+
+	return len(dropped), nil
+}
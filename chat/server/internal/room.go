@@ -1,15 +1,19 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	pbutils "github.com/golang/protobuf/ptypes"
 	"github.com/google/uuid"
 	"github.com/looplab/fsm"
-	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
 	utils "github.com/savo92/playground-go-grpc/chat/utils"
 )
 
@@ -20,38 +24,333 @@ type RoomMessage struct {
 	Participant *Participant
 }
 
+// RoomOption configures a room on creation.
+type RoomOption func(*room)
+
+func WithVisibility(visibility pb.RoomVisibility) RoomOption {
+	return func(r *room) {
+		r.visibility = visibility
+	}
+}
+
+// Room is the exported name of room, for the packages that need to refer to
+// the rooms they get from the RoomManager.
+type Room = room
+
 type room struct {
 	id   RoomID
 	name string
 
 	rm *RoomManager
 
+	topic       string
+	description string
+	createdAt   time.Time
+	// creator is empty for the rooms created by the server.
+	creator string
+	labels  map[string]string
+
+	// permanent rooms are never closed by the lifecycle policy.
+	permanent bool
+	lifecycle pb.RoomLifecycle
+	// emptyTTL overrides the default of the manager when not zero.
+	emptyTTL time.Duration
+	// emptySince is zero while the room has participants.
+	emptySince   time.Time
+	lastActivity time.Time
+	// lastMessageID is the ID of the last message stored.
+	lastMessageID store.MessageID
+	// retention is zero for the rooms applying the default of the manager.
+	retention store.Retention
+
 	participants map[participantID]*Participant
-	mu           sync.Mutex
+	// watchers follow the room without being participants.
+	watchers map[*Watcher]bool
+	// capacity is zero for rooms without limit.
+	capacity      int
+	queueWhenFull bool
+	waiting       []*Participant
+	// queuePositions holds the places in the waiting queue of the users who
+	// waited for a seat on the node the room was handed over from, until
+	// they join the room again.
+	queuePositions map[string]int
+	visibility     pb.RoomVisibility
+	// members holds the usernames who joined the room or redeemed an invite
+	// to it. They can join the room when private, as well as the ones with a
+	// role.
+	members map[string]bool
+	invites map[string]*Invite
+	// roles holds the usernames with a role other than member.
+	roles map[string]pb.Role
+	// muted holds the usernames muted by the moderators, whatever their
+	// role.
+	muted map[string]bool
+	bans  map[string]ban
+	// autoMuted holds the usernames muted for flooding, until the given time.
+	autoMuted map[string]time.Time
+	mode      pb.RoomMode
+	// slowModeInterval is set when mode is pb.RoomMode_Slow.
+	slowModeInterval time.Duration
+	// lastWrites holds the time of the last message of each username, since
+	// the last mode change.
+	lastWrites map[string]time.Time
+	mu         sync.Mutex
+
+	// limits and messagesBucket are guarded by mu.
+	limits         Limits
+	messagesBucket *tokenBucket
 
-	In chan RoomMessage
+	in chan RoomMessage
+	// events queues the messages received from the backplane.
+	events chan []byte
+	// flushC carries the requests to deliver the queued events, closed once
+	// delivered.
+	flushC chan chan struct{}
 
-	closeC chan interface{}
+	// closeC carries the reason notified to the participants.
+	closeC chan string
+	// closed is guarded by mu, it is set once the room is closing and does
+	// not accept participants anymore.
 	closed bool
+	// frozen is guarded by mu, it is set while the room is handed over to
+	// another node and does not accept messages.
+	frozen bool
+	// done is closed when the room stopped consuming its messages.
+	done chan struct{}
+	// unsubscribe stops receiving the messages of the room from the
+	// backplane.
+	unsubscribe func()
 }
 
-func (r *room) AddParticipant(p *Participant) error {
-	if r.closed {
-		return fmt.Errorf("room is closed")
+func (r *room) ID() RoomID {
+	return r.id
+}
+
+func (r *room) Name() string {
+	return r.name
+}
+
+func (r *room) Participants() []*Participant {
+	return copyParticipants(r)
+}
+
+func (r *room) Info() *pb.RoomInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info := &pb.RoomInfo{
+		Id:             string(r.id),
+		Name:           r.name,
+		Participants:   uint32(len(r.participants)),
+		Mode:           r.mode,
+		Visibility:     r.visibility,
+		Capacity:       uint32(r.capacity),
+		QueueWhenFull:  r.queueWhenFull,
+		Waiting:        uint32(len(r.waiting)),
+		Topic:          r.topic,
+		Description:    r.description,
+		CreatedAt:      timestamppb.New(r.createdAt),
+		Creator:        r.creator,
+		Labels:         make(map[string]string, len(r.labels)),
+		Lifecycle:      r.lifecycle,
+		LastActivityAt: timestamppb.New(r.lastActivity),
+		Retention:      retentionInfo(r.effectiveRetention()),
+		Watchers:       uint32(len(r.watchers)),
+	}
+	if r.lifecycle == pb.RoomLifecycle_Ephemeral {
+		info.EmptyTtl = durationpb.New(r.effectiveEmptyTTL())
+	}
+	for k, v := range r.labels {
+		info.Labels[k] = v
+	}
+	if r.slowModeInterval > 0 {
+		info.SlowModeInterval = durationpb.New(r.slowModeInterval)
+	}
+
+	return info
+}
+
+// Announce sends a system announcement to every participant of the room.
+func (r *room) Announce(body string) error {
+	if r.isClosed() {
+		return fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
+	}
+	announcement := pb.ServerMessage_ServerAnnouncement{
+		Body: body,
+	}
+	op, err := pbutils.MarshalAny(&announcement)
+	if err != nil {
+		return fmt.Errorf("marshal from announcement failed: %w", err)
+	}
+	sMsgP := &pb.ServerMessage{
+		Command:   pb.ServerMessage_Announcement,
+		Operation: op,
+	}
+	for _, p := range copyParticipants(r) {
+		p.Send(sMsgP)
+	}
+	r.notifyWatchers(sMsgP)
+
+	return nil
+}
+
+// Write submits rMsg to the room. It fails when the room is closed.
+func (r *room) Write(rMsg RoomMessage) error {
+	select {
+	case r.in <- rMsg:
+		return nil
+	case <-r.done:
+		return fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
 	}
-	p.CurrentRoom = r
+}
+
+// VisibleTo reports whether the room is listed to username.
+func (r *room) VisibleTo(username string) bool {
 	r.mu.Lock()
-	r.participants[p.id] = p
+	defer r.mu.Unlock()
+
+	return r.visibility == pb.RoomVisibility_Public || r.isMember(username)
+}
+
+// SetVisibility changes the visibility of the room, on behalf of the server
+// operators. The participants of the room stay, and the users who joined it
+// stay members when it becomes private.
+func (r *room) SetVisibility(visibility pb.RoomVisibility) error {
+	if _, ok := pb.RoomVisibility_name[int32(visibility)]; !ok {
+		return fmt.Errorf("%w: unknown room visibility %d", ErrInvalidArgument, visibility)
+	}
+	r.mu.Lock()
+	r.visibility = visibility
 	r.mu.Unlock()
 
+	r.publishChange(eventVisibility, roomChange{Visibility: visibility})
+
 	return nil
 }
 
+// addParticipant adds p to the room. Joining a private room requires either
+// to be a member or a valid invite code, which makes p a member. When the
+// room is full, p is either rejected with ErrRoomFull or queued, and
+// ErrQueued is returned. It returns the room p was in, from which the caller
+// must remove it, and the room p was waiting to join.
+func (r *room) addParticipant(p *Participant, inviteCode string) (prev, prevQueue *room, err error) {
+	r.mu.Lock()
+	prev, prevQueue, joined, err := r.admit(p, inviteCode)
+	r.mu.Unlock()
+	if joined != nil {
+		r.publishChange(eventMember, *joined)
+	}
+	if errors.Is(err, ErrQueued) {
+		r.notifyQueue()
+	}
+
+	return prev, prevQueue, err
+}
+
+// admit adds p to the room, or to its waiting queue. It returns the change
+// to publish when p became a member. The caller must hold r.mu.
+func (r *room) admit(p *Participant, inviteCode string) (prev, prevQueue *room, joined *roomChange, err error) {
+	if r.closed {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.checkJoin(r); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := r.checkBan(p.username); err != nil {
+		return nil, nil, nil, err
+	}
+	invited := r.visibility == pb.RoomVisibility_Private && !r.isMember(p.username)
+	if invited {
+		if inviteCode == "" {
+			return nil, nil, nil, fmt.Errorf("%w: room %s is private, an invite is required", ErrPermissionDenied, r.name)
+		}
+		if !r.validInvite(inviteCode) {
+			return nil, nil, nil, fmt.Errorf("%w: invalid or expired invite to room %s", ErrPermissionDenied, r.name)
+		}
+	}
+	prevQueue = p.queuedIn
+	full := r.full()
+	if full && !r.queueWhenFull {
+		return nil, nil, nil, fmt.Errorf("%w: room %s is full", ErrRoomFull, r.name)
+	}
+	// The invite is used once p is admitted or queued.
+	if invited {
+		change, err := r.redeemInvite(inviteCode)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		r.members[p.username] = true
+		change.Username = p.username
+		joined = &change
+	}
+	if full {
+		r.enqueue(p)
+
+		return nil, prevQueue, joined, fmt.Errorf("%w: room %s is full", ErrQueued, r.name)
+	}
+	if !r.members[p.username] {
+		r.members[p.username] = true
+		joined = &roomChange{Username: p.username}
+	}
+	prev = p.room
+	p.room = r
+	p.queuedIn = nil
+	delete(r.queuePositions, p.username)
+	r.participants[p.id] = p
+	r.emptySince = time.Time{}
+
+	return prev, prevQueue, joined, nil
+}
+
+// isMember reports whether username can join the room without invite. The
+// caller must hold r.mu.
+func (r *room) isMember(username string) bool {
+	_, hasRole := r.roles[username]
+
+	return r.visibility == pb.RoomVisibility_Public || r.members[username] || hasRole
+}
+
+// hasMember reports whether username joined the room, was invited to it or
+// has a role in it, whatever its visibility.
+func (r *room) hasMember(username string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, hasRole := r.roles[username]
+
+	return r.members[username] || hasRole
+}
+
 func (r *room) removeParticipant(id participantID) {
-	log.Debugf("Participant %s removed from room %s", id, r.name)
 	r.mu.Lock()
-	delete(r.participants, id)
+	p, ok := r.participants[id]
+	if ok {
+		delete(r.participants, id)
+		p.mu.Lock()
+		if p.room == r {
+			p.room = nil
+		}
+		p.mu.Unlock()
+	}
+	if len(r.participants) == 0 && r.emptySince.IsZero() {
+		r.emptySince = time.Now()
+	}
 	r.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	r.rm.log.Debugf("Participant %s removed from room %s", id, r.name)
+	r.admitWaiting()
+}
+
+func (r *room) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.closed
 }
 
 func (r *room) consumeChan() {
@@ -62,82 +361,191 @@ func (r *room) consumeChan() {
 			{Name: "readyAgain", Src: []string{"receiving"}, Dst: "idle"},
 		},
 		fsm.Callbacks{
+			utils.BeforeEvent(pb.ClientMessage_WriteMessage): func(e *fsm.Event) {
+				rMsg, err := extractRoomMsg(e)
+				if err != nil {
+					e.Cancel(err)
+
+					return
+				}
+				if err := r.checkWrite(rMsg); err != nil {
+					e.Cancel(err)
+				}
+			},
 			utils.AfterEvent(pb.ClientMessage_WriteMessage): func(e *fsm.Event) {
 				rMsg, err := extractRoomMsg(e)
 				if err != nil {
-					log.Errorf("Cannot extract room msg: %v", err)
+					r.rm.log.Errorf("Cannot extract room msg: %v", err)
 
 					return
 				}
 				var writeMsg pb.ClientMessage_ClientWriteMessage
 				if err := pbutils.UnmarshalAny(rMsg.CMsgP.Operation, &writeMsg); err != nil {
-					log.Errorf("Marshal to writeMsg failed: %v", err)
+					r.rm.log.Errorf("Marshal to writeMsg failed: %v", err)
 					// TODO no writeMsg no party
 					return
 				}
 
-				for _, p := range copyParticipants(r) {
-					forwardMessage := pb.ServerMessage_ServerForwardMessage{
-						Author: rMsg.Participant.username,
-						Body:   writeMsg.Body,
-					}
-					op, err := pbutils.MarshalAny(&forwardMessage)
-					if err != nil {
-						log.Errorf("Marshal from forwardMessage failed: %v", err)
-						// TODO handle marshalling failed
-						return
-					}
-					sMsg := pb.ServerMessage{
-						Command:   pb.ServerMessage_ForwardMessage,
-						Operation: op,
-					}
-					p.Out <- &sMsg
+				ev := roomEvent{
+					Node:   r.rm.node,
+					Author: rMsg.Participant.username,
+					Body:   writeMsg.Body,
+					SentAt: time.Now(),
+				}
+				if err := r.publish(ev); err != nil {
+					r.rm.log.Errorf("Publishing message in room %s failed: %v", r.name, err)
+					rMsg.Participant.SendError(rMsg.CMsgP.Command, fmt.Errorf("message not delivered: %w", err))
+
+					return
 				}
+				r.mu.Lock()
+				r.lastWrites[rMsg.Participant.username] = ev.SentAt
+				r.mu.Unlock()
 			},
 		},
 	)
 
+	defer close(r.done)
 	for {
 		select {
-		case <-r.closeC:
-			r.closed = true
+		case reason := <-r.closeC:
+			r.mu.Lock()
+			waiting := r.waiting
+			r.waiting = nil
+			r.mu.Unlock()
+			for _, p := range waiting {
+				// The waiting participants not in another room would
+				// otherwise wait forever.
+				if roomless := p.leaveQueue(r); roomless {
+					p.Disconnect(reason)
+				}
+			}
 			for _, p := range copyParticipants(r) {
-				p.disconnect()
+				p.disconnect(r, reason)
 			}
 
 			return
-		case rMsgP := <-r.In:
+		case payload := <-r.events:
+			r.deliver(payload)
+		case flushed := <-r.flushC:
+			r.flushEvents()
+			close(flushed)
+		case rMsgP := <-r.in:
 			cmd := rMsgP.CMsgP.Command.String()
 			if err := sm.Event(cmd, rMsgP); err != nil {
-				log.Errorf("Failed to submit %s: %v", cmd, err)
+				var canceledErr fsm.CanceledError
+				if errors.As(err, &canceledErr) {
+					rMsgP.Participant.SendError(rMsgP.CMsgP.Command, canceledErr.Err)
+				} else {
+					r.rm.log.Errorf("Failed to submit %s: %v", cmd, err)
+				}
 			}
 			if sm.Current() == "receiving" {
 				if err := sm.Event("readyAgain"); err != nil {
-					log.Errorf("Failed to submit readyAgain: %v", err)
+					r.rm.log.Errorf("Failed to submit readyAgain: %v", err)
 				}
 			}
 		}
 	}
 }
 
-func (r *room) close() {
+// checkWrite returns an error when the participant of rMsg cannot write it in
+// the room.
+func (r *room) checkWrite(rMsg RoomMessage) error {
+	p := rMsg.Participant
+	r.mu.Lock()
+	_, ok := r.participants[p.id]
+	role := r.roles[p.username]
+	muted := r.muted[p.username]
+	modeErr := r.checkMode(p.username, role, time.Now())
+	frozen := r.frozen
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: not in room %s", ErrPermissionDenied, r.name)
+	}
+	if frozen {
+		return fmt.Errorf("%w: room %s is moving to another node", ErrRoomClosed, r.name)
+	}
+	if muted {
+		return fmt.Errorf("%w: you are muted in room %s", ErrPermissionDenied, r.name)
+	}
+	if modeErr != nil {
+		return modeErr
+	}
+
+	var writeMsg pb.ClientMessage_ClientWriteMessage
+	if err := pbutils.UnmarshalAny(rMsg.CMsgP.Operation, &writeMsg); err != nil {
+		return fmt.Errorf("%w: malformed message: %v", ErrInvalidArgument, err)
+	}
+
+	return r.checkLimits(p, len(writeMsg.Body))
+}
+
+// close stops the room, disconnecting its participants with reason. It does
+// not wait for the participants to be disconnected.
+func (r *room) close(reason string) {
+	r.mu.Lock()
 	if r.closed {
+		r.mu.Unlock()
+
 		return
 	}
-	log.Debugf("Closing room %s", r.name)
 	r.closed = true
+	r.mu.Unlock()
+
+	r.rm.log.Debugf("Closing room %s: %s", r.name, reason)
+	r.unsubscribe()
 	r.rm.removeRoom(r.id)
-	r.closeC <- struct{}{}
+	r.closeWatchers()
+	r.closeC <- reason
 }
 
-func newRoom(name string) (*room, error) {
+func newRoom(name string, rm *RoomManager, opts ...RoomOption) (*room, error) {
+	limits := rm.Limits()
+	now := time.Now()
 	r := &room{
-		id:           RoomID(uuid.New().String()),
-		name:         name,
-		participants: make(map[participantID]*Participant),
-		In:           make(chan RoomMessage),
-		closeC:       make(chan interface{}),
+		id:             RoomID(uuid.New().String()),
+		name:           name,
+		rm:             rm,
+		createdAt:      now,
+		emptySince:     now,
+		lastActivity:   now,
+		labels:         make(map[string]string),
+		participants:   make(map[participantID]*Participant),
+		queuePositions: make(map[string]int),
+		watchers:       make(map[*Watcher]bool),
+		members:        make(map[string]bool),
+		invites:        make(map[string]*Invite),
+		roles:          make(map[string]pb.Role),
+		muted:          make(map[string]bool),
+		bans:           make(map[string]ban),
+		autoMuted:      make(map[string]time.Time),
+		lastWrites:     make(map[string]time.Time),
+		in:             make(chan RoomMessage),
+		events:         make(chan []byte, eventQueueSize),
+		flushC:         make(chan chan struct{}),
+		closeC:         make(chan string, 1),
+		done:           make(chan struct{}),
+		limits:         limits,
+		messagesBucket: newTokenBucket(limits.RoomMessages, limits.RoomMessagesBurst),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if err := r.checkMetadata(); err != nil {
+		return nil, err
+	}
+	if err := checkRetention(r.retention); err != nil {
+		return nil, err
+	}
+	if err := rm.store.SetRetention(string(r.id), r.effectiveRetention()); err != nil {
+		return nil, fmt.Errorf("store failed: %w", err)
+	}
+	unsubscribe, err := rm.backplane.Subscribe(roomTopic(name), r.receive)
+	if err != nil {
+		return nil, fmt.Errorf("backplane subscription failed: %w", err)
 	}
+	r.unsubscribe = unsubscribe
 
 	go r.consumeChan()
 
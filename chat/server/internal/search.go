@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/search"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+)
+
+// Search searches the history of the rooms username is a member of.
+func (rm *RoomManager) Search(username string, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	q := search.Query{
+		Terms:   req.Query,
+		Author:  req.Author,
+		RoomIDs: make(map[string]bool),
+		Limit:   int(req.PageSize),
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultSearchPageSize
+	}
+	if q.Limit > maxSearchPageSize {
+		q.Limit = maxSearchPageSize
+	}
+	if req.Since != nil {
+		q.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		q.Until = req.Until.AsTime()
+	}
+	if req.PageToken != "" {
+		before, err := strconv.ParseUint(req.PageToken, 10, 64)
+		if err != nil || before == 0 {
+			return nil, fmt.Errorf("%w: invalid page token", ErrInvalidArgument)
+		}
+		q.Before = store.MessageID(before)
+	}
+
+	names := make(map[string]string)
+	if req.Room != "" {
+		r, ok := rm.FindRoom(req.Room)
+		// Private rooms are reported as missing to the users who cannot see
+		// them.
+		if !ok || !r.VisibleTo(username) {
+			return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, req.Room)
+		}
+		if !r.hasMember(username) {
+			return nil, fmt.Errorf("%w: not a member of room %s", ErrPermissionDenied, r.name)
+		}
+		q.RoomIDs[string(r.id)] = true
+		names[string(r.id)] = r.name
+	} else {
+		for _, r := range rm.ListRooms() {
+			if r.hasMember(username) {
+				q.RoomIDs[string(r.id)] = true
+				names[string(r.id)] = r.name
+			}
+		}
+	}
+
+	messages, more := rm.index.Search(q)
+	res := &pb.SearchResponse{}
+	for _, m := range messages {
+		res.Messages = append(res.Messages, storedMessage(m, names[m.RoomID]))
+	}
+	if more {
+		res.NextPageToken = strconv.FormatUint(uint64(messages[len(messages)-1].ID), 10)
+	}
+
+	return res, nil
+}
+
+// indexHistory indexes the history kept in the store, including the one
+// written before the manager was created.
+func (rm *RoomManager) indexHistory() error {
+	roomIDs, err := rm.store.RoomIDs()
+	if err != nil {
+		return fmt.Errorf("store failed: %w", err)
+	}
+	for _, roomID := range roomIDs {
+		messages, _, err := rm.store.History(roomID, store.HistoryQuery{})
+		if err != nil {
+			return fmt.Errorf("store failed: %w", err)
+		}
+		for _, m := range messages {
+			rm.index.Add(m)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestSearch(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// Joined is set when the user searching joined the room.
+		Joined    bool
+		Room      string
+		WantFound int
+		WantErr   error
+	}{
+		{
+			Name:      "member",
+			Joined:    true,
+			WantFound: 2,
+		},
+		{
+			Name: "not a member",
+		},
+		{
+			Name:      "room of a member",
+			Joined:    true,
+			Room:      "lobby",
+			WantFound: 2,
+		},
+		{
+			Name:    "room of another",
+			Room:    "lobby",
+			WantErr: ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			// The history written before the manager was created is found.
+			st := store.NewMemoryStore()
+			id := ClusterRoomID("lobby")
+			if _, err := st.AppendMessage(store.Message{RoomID: string(id), Author: "alice", Body: "hi there", SentAt: time.Now()}); err != nil {
+				t.Fatalf("AppendMessage failed: %v", err)
+			}
+			rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, st, backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			if _, err := rm.CreateRoom("lobby", WithID(id)); err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+			if err := r.publish(roomEvent{Node: rm.node, Author: "alice", Body: "hi again", SentAt: time.Now()}); err != nil {
+				t.Fatalf("publish failed: %v", err)
+			}
+			delivered := func() bool {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+
+				return r.lastMessageID == 2
+			}
+			if !eventually(delivered) {
+				t.Fatal("message not delivered")
+			}
+			bob, _ := NewParticipant("bob", Limits{})
+			defer testSession(bob)()
+			if tt.Joined {
+				if err := bob.JoinRoom(r, ""); err != nil {
+					t.Fatalf("JoinRoom failed: %v", err)
+				}
+			}
+
+			res, err := rm.Search("bob", &pb.SearchRequest{Query: "hi", Room: tt.Room})
+			if !errors.Is(err, tt.WantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			if got := len(res.GetMessages()); got != tt.WantFound {
+				t.Errorf("found %d messages, want %d", got, tt.WantFound)
+			}
+		})
+	}
+}
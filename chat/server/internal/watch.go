@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// watcherBuffer is how many messages wait for a slow watcher before the new
+// ones are dropped.
+const watcherBuffer = 64
+
+// Watcher follows a public room without being a participant: it cannot
+// write, and it does not count as a member of the room.
+type Watcher struct {
+	// Out carries the messages, announcements, topic and mode changes of the
+	// room. The messages are dropped while Out is full.
+	Out chan *pb.ServerMessage
+	// Done is closed when the watcher stops, with the room or on Close.
+	Done chan struct{}
+
+	r *room
+	// dropped counts the messages dropped. Use atomic ops.
+	dropped uint64
+}
+
+// Watch starts following the room.
+func (r *room) Watch() (*Watcher, error) {
+	w := &Watcher{
+		Out:  make(chan *pb.ServerMessage, watcherBuffer),
+		Done: make(chan struct{}),
+		r:    r,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, fmt.Errorf("%w: %s", ErrRoomClosed, r.name)
+	}
+	if r.visibility == pb.RoomVisibility_Private {
+		return nil, fmt.Errorf("%w: room %s is private", ErrPermissionDenied, r.name)
+	}
+	r.watchers[w] = true
+
+	return w, nil
+}
+
+// Close stops following the room.
+func (w *Watcher) Close() {
+	w.r.mu.Lock()
+	defer w.r.mu.Unlock()
+
+	if w.r.watchers[w] {
+		delete(w.r.watchers, w)
+		close(w.Done)
+	}
+}
+
+func (r *room) notifyWatchers(sMsgP *pb.ServerMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for w := range r.watchers {
+		select {
+		case w.Out <- sMsgP:
+		default:
+			if atomic.AddUint64(&w.dropped, 1) == 1 {
+				r.rm.log.Warnf("Watcher of room %s too slow, dropping messages", r.name)
+			}
+		}
+	}
+}
+
+func (r *room) closeWatchers() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for w := range r.watchers {
+		delete(r.watchers, w)
+		close(w.Done)
+	}
+}
+
+func (r *room) watcherCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.watchers)
+}
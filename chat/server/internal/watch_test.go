@@ -0,0 +1,80 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestWatch(t *testing.T) {
+	testsTable := []struct {
+		Name         string
+		Visibility   pb.RoomVisibility
+		WantErr      error
+		WantCommands []pb.ServerMessage_ServerCommand
+	}{
+		{
+			Name:         "public room",
+			Visibility:   pb.RoomVisibility_Public,
+			WantCommands: []pb.ServerMessage_ServerCommand{pb.ServerMessage_Announcement, pb.ServerMessage_ForwardMessage},
+		},
+		{
+			Name:       "private room",
+			Visibility: pb.RoomVisibility_Private,
+			WantErr:    ErrPermissionDenied,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			rm, err := NewRoomManager(Limits{}, Lifecycle{}, Mailbox{}, store.NewMemoryStore(), backplane.NewLocal())
+			if err != nil {
+				t.Fatalf("NewRoomManager failed: %v", err)
+			}
+			defer rm.Close()
+			id, err := rm.CreateRoom("lobby", WithVisibility(tt.Visibility))
+			if err != nil {
+				t.Fatalf("CreateRoom failed: %v", err)
+			}
+			r, _ := rm.GetRoom(id)
+
+			w, err := r.Watch()
+			if !errors.Is(err, tt.WantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.WantErr)
+			}
+			if err != nil {
+				return
+			}
+			if stats := rm.Stats(); stats.Watchers != 1 || stats.Participants != 0 {
+				t.Errorf("got %d watchers and %d participants, want 1 and 0", stats.Watchers, stats.Participants)
+			}
+			// The published messages are delivered by the room goroutine,
+			// after the announcement.
+			if err := r.Announce("maintenance"); err != nil {
+				t.Fatalf("Announce failed: %v", err)
+			}
+			if err := r.publish(roomEvent{Node: rm.node, Author: "alice", Body: "hi", SentAt: time.Now()}); err != nil {
+				t.Fatalf("publish failed: %v", err)
+			}
+			for _, want := range tt.WantCommands {
+				if got := (<-w.Out).Command; got != want {
+					t.Errorf("got %s, want %s", got, want)
+				}
+			}
+
+			if err := rm.CloseRoom(id); err != nil {
+				t.Fatalf("CloseRoom failed: %v", err)
+			}
+			select {
+			case <-w.Done:
+			case <-time.After(time.Second):
+				t.Errorf("watcher not stopped with the room")
+			}
+			w.Close()
+		})
+	}
+}
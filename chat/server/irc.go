@@ -0,0 +1,685 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// The IRC sessions are RouteChat sessions of the IRC clients: NICK and USER
+// make the Helo, and the channel #name is the room named name. As the
+// participants are in one room at a time, joining a channel parts the
+// previous one, and parting the current channel returns to the default room.
+// The key of a JOIN is the invite code of the room, and the PASS sent before
+// the registration is the token of the user.
+
+const (
+	ircServerName = "chat"
+	// ircMaxLine is the longest line read from the clients, beyond the 512
+	// bytes of RFC 1459 for the clients sending tags.
+	ircMaxLine = 8192
+	// ircNamesPerLine is how many nicks a RPL_NAMREPLY holds.
+	ircNamesPerLine = 20
+)
+
+// IRCServer exposes the chat of a Server to the IRC clients, on its own
+// listener.
+type IRCServer struct {
+	listener net.Listener
+	s        *Server
+	// ctx is canceled on shutdown, ending the sessions.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// wg accounts for the connections being served. closed is set on
+	// shutdown, both are guarded by mu.
+	wg     sync.WaitGroup
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewIRCServer listens on port, on the bind address of s, for the IRC
+// clients of s.
+func NewIRCServer(port int, s *Server) (*IRCServer, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.bindAddress, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &IRCServer{
+		listener: listener,
+		s:        s,
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+func (i *IRCServer) Serve() error {
+	for {
+		conn, err := i.listener.Accept()
+		if err != nil {
+			if i.ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+		i.mu.Lock()
+		if i.closed {
+			i.mu.Unlock()
+			conn.Close()
+
+			return nil
+		}
+		i.wg.Add(1)
+		i.mu.Unlock()
+		go func() {
+			defer i.wg.Done()
+			i.serveConn(conn)
+		}()
+	}
+}
+
+func (i *IRCServer) Shutdown(ctx context.Context) error {
+	i.mu.Lock()
+	i.closed = true
+	i.mu.Unlock()
+	i.cancel()
+	err := i.listener.Close()
+
+	done := make(chan struct{})
+	go func() {
+		i.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		i.s.log.Warn("Graceful IRC shutdown timed out")
+
+		return ctx.Err()
+	}
+}
+
+func (i *IRCServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	is := newIRCStream(conn, i.s)
+	// The session starts once registered, with the password sent by the
+	// client as its token.
+	helo, err := is.Recv()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			i.s.log.Debugf("IRC registration failed: %v", err)
+		}
+
+		return
+	}
+	is.pending = helo
+	ctx := i.ctx
+	if is.pass != "" {
+		ctx = ContextWithToken(ctx, is.pass)
+	}
+	reason := "Closing link"
+	if err := i.s.ServeStream(ctx, is); err != nil {
+		i.s.log.Errorf("IRC session failed: %v", err)
+		reason = err.Error()
+	}
+	is.closeLink(reason)
+}
+
+// ircMessage is a line of the IRC protocol, without its tags and prefix.
+type ircMessage struct {
+	command string
+	params  []string
+}
+
+// parseIRCMessage parses a line sent by a client. It returns false when the
+// line holds no command.
+func parseIRCMessage(line string) (ircMessage, bool) {
+	var m ircMessage
+	line = strings.TrimLeft(strings.TrimRight(line, "\r"), " ")
+	for _, skip := range []string{"@", ":"} {
+		if !strings.HasPrefix(line, skip) {
+			continue
+		}
+		i := strings.IndexByte(line, ' ')
+		if i < 0 {
+			return m, false
+		}
+		line = strings.TrimLeft(line[i+1:], " ")
+	}
+	for line != "" {
+		if line[0] == ':' && m.command != "" {
+			m.params = append(m.params, line[1:])
+
+			break
+		}
+		word := line
+		line = ""
+		if i := strings.IndexByte(word, ' '); i >= 0 {
+			word, line = word[:i], strings.TrimLeft(word[i+1:], " ")
+		}
+		if m.command == "" {
+			m.command = strings.ToUpper(word)
+		} else {
+			m.params = append(m.params, word)
+		}
+	}
+
+	return m, m.command != ""
+}
+
+func (m ircMessage) param(i int) string {
+	if i < len(m.params) {
+		return m.params[i]
+	}
+
+	return ""
+}
+
+// ircStream is a MessageStream over the connection of an IRC client.
+type ircStream struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	s       *Server
+	host    string
+
+	// nick, user and pass are set by Recv during the registration, before
+	// the Helo, and never change afterwards.
+	nick, user, pass string
+	// pending is the message returned by the next Recv, read during the
+	// registration.
+	pending *pb.ClientMessage
+
+	// channel is the channel of the current room. linkClosed is set once
+	// the ERROR closing the link is sent. Both are guarded by mu, which
+	// serializes the writes to conn.
+	channel    string
+	linkClosed bool
+	mu         sync.Mutex
+}
+
+func newIRCStream(conn net.Conn, s *Server) *ircStream {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 512), ircMaxLine)
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		host = conn.RemoteAddr().String()
+	}
+
+	return &ircStream{
+		conn:    conn,
+		scanner: scanner,
+		s:       s,
+		host:    host,
+	}
+}
+
+func (is *ircStream) Recv() (*pb.ClientMessage, error) {
+	if m := is.pending; m != nil {
+		is.pending = nil
+
+		return m, nil
+	}
+	for is.scanner.Scan() {
+		m, ok := parseIRCMessage(is.scanner.Text())
+		if !ok {
+			continue
+		}
+		cMsg, err := is.handle(m)
+		if err != nil {
+			return nil, err
+		}
+		if cMsg != nil {
+			return cMsg, nil
+		}
+	}
+	if err := is.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// handle answers m, returning the client message it maps to, if any.
+func (is *ircStream) handle(m ircMessage) (*pb.ClientMessage, error) {
+	switch m.command {
+	case "PING":
+		return nil, is.write(ircServerName, "PONG", ircServerName, m.param(0))
+	case "PONG":
+		return nil, nil
+	case "PASS":
+		if is.user != "" && is.nick != "" {
+			return nil, is.reply("462", "You may not reregister")
+		}
+		if m.param(0) == "" {
+			return nil, is.reply("461", "PASS", "Not enough parameters")
+		}
+		is.pass = m.param(0)
+
+		return nil, nil
+	case "CAP":
+		// No capability is supported.
+		switch strings.ToUpper(m.param(0)) {
+		case "LS", "LIST":
+			return nil, is.write(ircServerName, "CAP", "*", strings.ToUpper(m.param(0)), "")
+		case "REQ":
+			return nil, is.write(ircServerName, "CAP", "*", "NAK", m.param(1))
+		}
+
+		return nil, nil
+	case "QUIT":
+		return clientMessage(pb.ClientMessage_Quit, &pb.ClientMessage_ClientQuit{})
+	}
+	if is.user == "" || is.nick == "" {
+		return is.register(m)
+	}
+
+	switch m.command {
+	case "JOIN":
+		channel := strings.Split(m.param(0), ",")[0]
+		if channel == "" || channel == "0" {
+			return nil, is.reply("461", "JOIN", "Not enough parameters")
+		}
+
+		return clientMessage(pb.ClientMessage_JoinRoom, &pb.ClientMessage_ClientJoinRoom{
+			Room:       roomOfChannel(channel),
+			InviteCode: strings.Split(m.param(1), ",")[0],
+		})
+	case "PART":
+		if !is.inChannel(m.param(0)) {
+			return nil, is.reply("442", m.param(0), "You're not on that channel")
+		}
+
+		return clientMessage(pb.ClientMessage_JoinRoom, &pb.ClientMessage_ClientJoinRoom{
+			Room: string(is.s.defaultRoom),
+		})
+	case "PRIVMSG", "NOTICE":
+		target, body := m.param(0), m.param(1)
+		if target == "" || body == "" {
+			return nil, is.reply("412", "No text to send")
+		}
+		if !isChannel(target) {
+			return clientMessage(pb.ClientMessage_DirectMessage, &pb.ClientMessage_ClientDirectMessage{
+				To:   target,
+				Body: body,
+			})
+		}
+		if !is.inChannel(target) {
+			return nil, is.reply("404", target, "Cannot send to channel")
+		}
+
+		return clientMessage(pb.ClientMessage_WriteMessage, &pb.ClientMessage_ClientWriteMessage{Body: body})
+	case "NAMES":
+		channel := m.param(0)
+		if channel == "" {
+			channel = is.currentChannel()
+		}
+
+		return nil, is.names(channel)
+	case "TOPIC":
+		if !is.inChannel(m.param(0)) {
+			return nil, is.reply("442", m.param(0), "You're not on that channel")
+		}
+		if len(m.params) < 2 {
+			return clientMessage(pb.ClientMessage_GetRoomInfo, &pb.ClientMessage_ClientGetRoomInfo{})
+		}
+
+		return clientMessage(pb.ClientMessage_SetTopic, &pb.ClientMessage_ClientSetTopic{Topic: m.param(1)})
+	case "LIST":
+		return clientMessage(pb.ClientMessage_ListRooms, &pb.ClientMessage_ClientListRooms{})
+	case "MODE":
+		if isChannel(m.param(0)) {
+			return nil, is.reply("324", m.param(0), "+")
+		}
+
+		return nil, is.reply("221", "+")
+	case "WHO":
+		return nil, is.reply("315", m.param(0), "End of WHO list")
+	case "NICK":
+		return nil, is.write(ircServerName, "NOTICE", is.nick, "Changing nick is not supported")
+	case "USER":
+		return nil, is.reply("462", "You may not reregister")
+	default:
+		return nil, is.reply("421", m.command, "Unknown command")
+	}
+}
+
+// register handles the messages sent before the registration, returning the
+// Helo once both NICK and USER are received.
+func (is *ircStream) register(m ircMessage) (*pb.ClientMessage, error) {
+	switch m.command {
+	case "NICK":
+		nick := m.param(0)
+		if nick == "" {
+			return nil, is.reply("431", "No nickname given")
+		}
+		if isChannel(nick) || strings.ContainsAny(nick, "!@,*?") {
+			return nil, is.reply("432", nick, "Erroneous nickname")
+		}
+		is.nick = nick
+	case "USER":
+		if m.param(0) == "" {
+			return nil, is.reply("461", "USER", "Not enough parameters")
+		}
+		is.user = m.param(0)
+	default:
+		return nil, is.reply("451", "You have not registered")
+	}
+	if is.user == "" || is.nick == "" {
+		return nil, nil
+	}
+
+	welcome := [][]string{
+		{"001", "Welcome to the chat, " + is.nick},
+		{"002", "Your host is " + ircServerName},
+		{"003", "This server bridges the rooms of the chat as channels"},
+		{"422", "MOTD File is missing"},
+	}
+	for _, r := range welcome {
+		if err := is.reply(r[0], r[1:]...); err != nil {
+			return nil, err
+		}
+	}
+
+	return clientMessage(pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: is.nick})
+}
+
+func (is *ircStream) Send(m *pb.ServerMessage) error {
+	switch m.Command {
+	case pb.ServerMessage_ForwardMessage:
+		var forwardMsg pb.ServerMessage_ServerForwardMessage
+		if err := pbutils.UnmarshalAny(m.Operation, &forwardMsg); err != nil {
+			return err
+		}
+		// The IRC clients echo their own messages.
+		if forwardMsg.Author == is.nick {
+			return nil
+		}
+
+		return is.privmsg(ircPrefix(forwardMsg.Author), is.currentChannel(), forwardMsg.Body)
+	case pb.ServerMessage_DirectMessage:
+		var directMsg pb.ServerMessage_ServerDirectMessage
+		if err := pbutils.UnmarshalAny(m.Operation, &directMsg); err != nil {
+			return err
+		}
+
+		return is.privmsg(ircPrefix(directMsg.From), is.nick, directMsg.Body)
+	case pb.ServerMessage_ConfirmRoomCheckout:
+		var confirm pb.ServerMessage_ServerConfirmRoomCheckout
+		if err := pbutils.UnmarshalAny(m.Operation, &confirm); err != nil {
+			return err
+		}
+
+		return is.joined(confirm.Room)
+	case pb.ServerMessage_RoomDetails:
+		var details pb.ServerMessage_ServerRoomDetails
+		if err := pbutils.UnmarshalAny(m.Operation, &details); err != nil {
+			return err
+		}
+
+		return is.topic(channelOfRoom(details.Room.GetName()), details.Room.GetTopic())
+	case pb.ServerMessage_TopicChanged:
+		var topicMsg pb.ServerMessage_ServerTopicChanged
+		if err := pbutils.UnmarshalAny(m.Operation, &topicMsg); err != nil {
+			return err
+		}
+
+		return is.write(ircPrefix(topicMsg.ChangedBy), "TOPIC", is.currentChannel(), topicMsg.Topic)
+	case pb.ServerMessage_RoomList:
+		var listMsg pb.ServerMessage_ServerRoomList
+		if err := pbutils.UnmarshalAny(m.Operation, &listMsg); err != nil {
+			return err
+		}
+		for _, r := range listMsg.Rooms {
+			if err := is.reply("322", channelOfRoom(r.Name), fmt.Sprint(r.Participants), r.Topic); err != nil {
+				return err
+			}
+		}
+
+		return is.reply("323", "End of LIST")
+	case pb.ServerMessage_Announcement:
+		var announcement pb.ServerMessage_ServerAnnouncement
+		if err := pbutils.UnmarshalAny(m.Operation, &announcement); err != nil {
+			return err
+		}
+
+		return is.notice(announcement.Body)
+	case pb.ServerMessage_QueuePosition:
+		var queueMsg pb.ServerMessage_ServerQueuePosition
+		if err := pbutils.UnmarshalAny(m.Operation, &queueMsg); err != nil {
+			return err
+		}
+
+		return is.notice(fmt.Sprintf("%s is full, you are number %d in the queue", channelOfRoom(queueMsg.Room.GetName()), queueMsg.Position))
+	case pb.ServerMessage_RoomState:
+		var stateMsg pb.ServerMessage_ServerRoomState
+		if err := pbutils.UnmarshalAny(m.Operation, &stateMsg); err != nil {
+			return err
+		}
+
+		return is.notice(fmt.Sprintf("The room is now in %s mode", stateMsg.Mode))
+	case pb.ServerMessage_Mention:
+		var mention pb.ServerMessage_ServerMention
+		if err := pbutils.UnmarshalAny(m.Operation, &mention); err != nil {
+			return err
+		}
+		// The mentions in the current room are forwarded as messages.
+		if !mention.Offline && channelOfRoom(mention.RoomName) == is.currentChannel() {
+			return nil
+		}
+
+		return is.notice(fmt.Sprintf("%s mentioned you in %s: %s", mention.Author, channelOfRoom(mention.RoomName), mention.Body))
+	case pb.ServerMessage_Error:
+		var errorMsg pb.ServerMessage_ServerError
+		if err := pbutils.UnmarshalAny(m.Operation, &errorMsg); err != nil {
+			return err
+		}
+
+		return is.notice(fmt.Sprintf("%s failed: %s", errorMsg.Command, errorMsg.Message))
+	case pb.ServerMessage_Shutdown:
+		is.closeLink(shutdownReason(m))
+
+		return nil
+	default:
+		is.s.log.Debugf("Dropping %s, not supported by IRC", m.Command)
+
+		return nil
+	}
+}
+
+// joined reports the checkout of room as the JOIN of its channel, after
+// parting the previous one.
+func (is *ircStream) joined(room *pb.RoomInfo) error {
+	channel := channelOfRoom(room.GetName())
+	is.mu.Lock()
+	prev := is.channel
+	is.channel = channel
+	is.mu.Unlock()
+
+	self := is.nick + "!" + is.user + "@" + is.host
+	if prev != "" && prev != channel {
+		if err := is.write(self, "PART", prev); err != nil {
+			return err
+		}
+	}
+	if err := is.write(self, "JOIN", channel); err != nil {
+		return err
+	}
+	if err := is.topic(channel, room.GetTopic()); err != nil {
+		return err
+	}
+
+	return is.names(channel)
+}
+
+func (is *ircStream) topic(channel, topic string) error {
+	if topic == "" {
+		return is.reply("331", channel, "No topic is set")
+	}
+
+	return is.reply("332", channel, topic)
+}
+
+// names lists the participants of the room of channel connected to this
+// server.
+func (is *ircStream) names(channel string) error {
+	var nicks []string
+	if r, ok := is.s.rm.FindRoom(roomOfChannel(channel)); ok && r.VisibleTo(is.nick) {
+		for _, p := range r.Participants() {
+			nicks = append(nicks, ircNick(p.Username()))
+		}
+	}
+	for len(nicks) > 0 {
+		n := ircNamesPerLine
+		if n > len(nicks) {
+			n = len(nicks)
+		}
+		if err := is.reply("353", "=", channel, strings.Join(nicks[:n], " ")); err != nil {
+			return err
+		}
+		nicks = nicks[n:]
+	}
+
+	return is.reply("366", channel, "End of NAMES list")
+}
+
+// privmsg sends body from prefix to target, one PRIVMSG per line.
+func (is *ircStream) privmsg(prefix, target, body string) error {
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r", ""), "\n") {
+		if line == "" {
+			continue
+		}
+		if err := is.write(prefix, "PRIVMSG", target, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (is *ircStream) notice(body string) error {
+	target := is.currentChannel()
+	if target == "" {
+		target = is.nick
+	}
+
+	return is.write(ircServerName, "NOTICE", target, body)
+}
+
+// reply sends the numeric reply code to the client.
+func (is *ircStream) reply(code string, params ...string) error {
+	nick := is.nick
+	if nick == "" {
+		nick = "*"
+	}
+
+	return is.write(ircServerName, code, append([]string{nick}, params...)...)
+}
+
+// write sends a line to the client, the last of params being the trailing
+// parameter.
+func (is *ircStream) write(prefix, command string, params ...string) error {
+	var b strings.Builder
+	b.WriteString(":" + prefix + " " + command)
+	for i, p := range params {
+		p = strings.NewReplacer("\r", " ", "\n", " ").Replace(p)
+		if i == len(params)-1 {
+			p = ":" + p
+		}
+		b.WriteString(" " + p)
+	}
+	b.WriteString("\r\n")
+
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if is.linkClosed {
+		return io.EOF
+	}
+	if _, err := io.WriteString(is.conn, b.String()); err != nil {
+		if errors.Is(err, net.ErrClosed) {
+			return io.EOF
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// closeLink sends the ERROR ending the session, once.
+func (is *ircStream) closeLink(reason string) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if is.linkClosed {
+		return
+	}
+	is.linkClosed = true
+	if _, err := io.WriteString(is.conn, "ERROR :"+reason+"\r\n"); err != nil {
+		is.s.log.Debugf("Closing the IRC link failed: %v", err)
+	}
+}
+
+func (is *ircStream) currentChannel() string {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	return is.channel
+}
+
+func (is *ircStream) inChannel(channel string) bool {
+	current := is.currentChannel()
+
+	return current != "" && strings.EqualFold(channel, current)
+}
+
+func clientMessage(cmd pb.ClientMessage_ClientCommand, op proto.Message) (*pb.ClientMessage, error) {
+	operation, err := pbutils.MarshalAny(op)
+	if err != nil {
+		return nil, fmt.Errorf("marshal from %s failed: %w", cmd, err)
+	}
+
+	return &pb.ClientMessage{Command: cmd, Operation: operation}, nil
+}
+
+func isChannel(target string) bool {
+	return strings.HasPrefix(target, "#") || strings.HasPrefix(target, "&")
+}
+
+func channelOfRoom(name string) string {
+	return "#" + name
+}
+
+func roomOfChannel(channel string) string {
+	if isChannel(channel) {
+		return channel[1:]
+	}
+
+	return channel
+}
+
+// ircNick makes a nick of a username, as the federated ones hold an @.
+func ircNick(username string) string {
+	return strings.NewReplacer(" ", "_", "!", "|", "@", "|").Replace(username)
+}
+
+func ircPrefix(username string) string {
+	nick := ircNick(username)
+
+	return nick + "!" + nick + "@" + ircServerName
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+func TestParseIRCMessage(t *testing.T) {
+	testsTable := []struct {
+		Name   string
+		Line   string
+		Want   ircMessage
+		WantOK bool
+	}{
+		{
+			Name:   "command",
+			Line:   "ping",
+			Want:   ircMessage{command: "PING"},
+			WantOK: true,
+		},
+		{
+			Name:   "parameters",
+			Line:   "JOIN #news key\r",
+			Want:   ircMessage{command: "JOIN", params: []string{"#news", "key"}},
+			WantOK: true,
+		},
+		{
+			Name:   "trailing parameter",
+			Line:   "PRIVMSG #news :hello  :world",
+			Want:   ircMessage{command: "PRIVMSG", params: []string{"#news", "hello  :world"}},
+			WantOK: true,
+		},
+		{
+			Name:   "empty trailing parameter",
+			Line:   "TOPIC #news :",
+			Want:   ircMessage{command: "TOPIC", params: []string{"#news", ""}},
+			WantOK: true,
+		},
+		{
+			Name:   "prefix",
+			Line:   ":alice!alice@host  PART  #news",
+			Want:   ircMessage{command: "PART", params: []string{"#news"}},
+			WantOK: true,
+		},
+		{
+			Name:   "tags and prefix",
+			Line:   "@time=2021-01-01T00:00:00Z;msgid=1 :alice PRIVMSG #news :hi",
+			Want:   ircMessage{command: "PRIVMSG", params: []string{"#news", "hi"}},
+			WantOK: true,
+		},
+		{
+			Name: "empty",
+			Line: "  \r",
+		},
+		{
+			Name: "prefix only",
+			Line: ":alice",
+		},
+		{
+			Name: "tags only",
+			Line: "@time=2021-01-01T00:00:00Z ",
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, ok := parseIRCMessage(tt.Line)
+			if ok != tt.WantOK {
+				t.Fatalf("got ok %t, want %t", ok, tt.WantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.Want) {
+				t.Errorf("got %+v, want %+v", got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestIRCCommands(t *testing.T) {
+	s, _ := newTestClient(t)
+	testsTable := []struct {
+		Name string
+		Line string
+		// Channel is the channel of the current room.
+		Channel     string
+		WantCommand pb.ClientMessage_ClientCommand
+		WantOp      proto.Message
+		// WantReply, when set, is the numeric reply sent instead of a client
+		// message.
+		WantReply string
+	}{
+		{
+			Name:        "JOIN",
+			Line:        "JOIN #news,#sport key",
+			WantCommand: pb.ClientMessage_JoinRoom,
+			WantOp:      &pb.ClientMessage_ClientJoinRoom{Room: "news", InviteCode: "key"},
+		},
+		{
+			Name:      "JOIN without channel",
+			Line:      "JOIN",
+			WantReply: "461",
+		},
+		{
+			Name:        "PART",
+			Line:        "PART #News :bye",
+			Channel:     "#news",
+			WantCommand: pb.ClientMessage_JoinRoom,
+			WantOp:      &pb.ClientMessage_ClientJoinRoom{Room: string(s.defaultRoom)},
+		},
+		{
+			Name:      "PART of another channel",
+			Line:      "PART #sport",
+			Channel:   "#news",
+			WantReply: "442",
+		},
+		{
+			Name:        "PRIVMSG to the channel",
+			Line:        "PRIVMSG #news :hello world",
+			Channel:     "#news",
+			WantCommand: pb.ClientMessage_WriteMessage,
+			WantOp:      &pb.ClientMessage_ClientWriteMessage{Body: "hello world"},
+		},
+		{
+			Name:        "PRIVMSG to a nick",
+			Line:        "PRIVMSG bob :hi",
+			Channel:     "#news",
+			WantCommand: pb.ClientMessage_DirectMessage,
+			WantOp:      &pb.ClientMessage_ClientDirectMessage{To: "bob", Body: "hi"},
+		},
+		{
+			Name:      "PRIVMSG to another channel",
+			Line:      "PRIVMSG #sport :hi",
+			Channel:   "#news",
+			WantReply: "404",
+		},
+		{
+			Name:      "PRIVMSG without text",
+			Line:      "PRIVMSG #news",
+			Channel:   "#news",
+			WantReply: "412",
+		},
+		{
+			Name:        "TOPIC",
+			Line:        "TOPIC #news",
+			Channel:     "#news",
+			WantCommand: pb.ClientMessage_GetRoomInfo,
+			WantOp:      &pb.ClientMessage_ClientGetRoomInfo{},
+		},
+		{
+			Name:        "TOPIC set",
+			Line:        "TOPIC #news :Breaking news",
+			Channel:     "#news",
+			WantCommand: pb.ClientMessage_SetTopic,
+			WantOp:      &pb.ClientMessage_ClientSetTopic{Topic: "Breaking news"},
+		},
+		{
+			Name:        "TOPIC cleared",
+			Line:        "TOPIC #news :",
+			Channel:     "#news",
+			WantCommand: pb.ClientMessage_SetTopic,
+			WantOp:      &pb.ClientMessage_ClientSetTopic{},
+		},
+		{
+			Name:      "TOPIC of another channel",
+			Line:      "TOPIC #sport :Goal",
+			Channel:   "#news",
+			WantReply: "442",
+		},
+		{
+			Name:      "NAMES",
+			Line:      "NAMES",
+			Channel:   "#default",
+			WantReply: "366 alice #default",
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			conn, client := net.Pipe()
+			defer client.Close()
+			is := newIRCStream(conn, s)
+			defer conn.Close()
+			is.nick, is.user, is.channel = "alice", "alice", tt.Channel
+			lines := make(chan string, 1)
+			go func() {
+				defer close(lines)
+				scanner := bufio.NewScanner(client)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+
+			m, ok := parseIRCMessage(tt.Line)
+			if !ok {
+				t.Fatalf("parseIRCMessage of %q failed", tt.Line)
+			}
+			cMsg, err := is.handle(m)
+			if err != nil {
+				t.Fatalf("handle failed: %v", err)
+			}
+			if tt.WantReply != "" {
+				if cMsg != nil {
+					t.Fatalf("got %s, want the reply %s", cMsg.Command, tt.WantReply)
+				}
+				want := ":" + ircServerName + " " + tt.WantReply
+				if line := <-lines; !strings.HasPrefix(line, want) {
+					t.Errorf("got %q, want the reply %s", line, tt.WantReply)
+				}
+
+				return
+			}
+			if cMsg == nil {
+				t.Fatalf("got no client message, want %s", tt.WantCommand)
+			}
+			if cMsg.Command != tt.WantCommand {
+				t.Fatalf("got %s, want %s", cMsg.Command, tt.WantCommand)
+			}
+			got := proto.Clone(tt.WantOp)
+			got.Reset()
+			if err := pbutils.UnmarshalAny(cMsg.Operation, got); err != nil {
+				t.Fatalf("UnmarshalAny failed: %v", err)
+			}
+			if !proto.Equal(got, tt.WantOp) {
+				t.Errorf("got %v, want %v", got, tt.WantOp)
+			}
+		})
+	}
+}
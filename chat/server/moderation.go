@@ -0,0 +1,144 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"github.com/looplab/fsm"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+func kickHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var kickMsg pb.ClientMessage_ClientKick
+		r, ok := ss.extractOperation(e, pb.ClientMessage_Kick, &kickMsg)
+		if !ok {
+			return
+		}
+
+		if err := r.Kick(ss.p, kickMsg.Username, kickMsg.Reason); err != nil {
+			ss.p.SendError(pb.ClientMessage_Kick, err)
+
+			return
+		}
+		ss.announce(r, fmt.Sprintf("%s was kicked by %s", kickMsg.Username, ss.p.Username()))
+	}
+}
+
+func banHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var banMsg pb.ClientMessage_ClientBan
+		r, ok := ss.extractOperation(e, pb.ClientMessage_Ban, &banMsg)
+		if !ok {
+			return
+		}
+
+		d := banMsg.Duration.AsDuration()
+		if err := r.Ban(ss.p, banMsg.Username, d, banMsg.Reason); err != nil {
+			ss.p.SendError(pb.ClientMessage_Ban, err)
+
+			return
+		}
+		if d == 0 {
+			ss.announce(r, fmt.Sprintf("%s was banned by %s", banMsg.Username, ss.p.Username()))
+		} else {
+			ss.announce(r, fmt.Sprintf("%s was banned for %s by %s", banMsg.Username, d, ss.p.Username()))
+		}
+	}
+}
+
+func muteHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var muteMsg pb.ClientMessage_ClientMute
+		r, ok := ss.extractOperation(e, pb.ClientMessage_Mute, &muteMsg)
+		if !ok {
+			return
+		}
+
+		if err := r.Mute(ss.p, muteMsg.Username, muteMsg.Muted); err != nil {
+			ss.p.SendError(pb.ClientMessage_Mute, err)
+
+			return
+		}
+		if muteMsg.Muted {
+			ss.announce(r, fmt.Sprintf("%s was muted by %s", muteMsg.Username, ss.p.Username()))
+		} else {
+			ss.announce(r, fmt.Sprintf("%s was unmuted by %s", muteMsg.Username, ss.p.Username()))
+		}
+	}
+}
+
+func setRoleHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var setRoleMsg pb.ClientMessage_ClientSetRole
+		r, ok := ss.extractOperation(e, pb.ClientMessage_SetRole, &setRoleMsg)
+		if !ok {
+			return
+		}
+
+		if err := r.SetRole(ss.p, setRoleMsg.Username, setRoleMsg.Role); err != nil {
+			ss.p.SendError(pb.ClientMessage_SetRole, err)
+
+			return
+		}
+		ss.announce(r, fmt.Sprintf("%s is now %s", setRoleMsg.Username, setRoleMsg.Role))
+	}
+}
+
+func setRoomModeHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var setRoomModeMsg pb.ClientMessage_ClientSetRoomMode
+		r, ok := ss.extractOperation(e, pb.ClientMessage_SetRoomMode, &setRoomModeMsg)
+		if !ok {
+			return
+		}
+
+		d := setRoomModeMsg.SlowModeInterval.AsDuration()
+		if err := r.SetMode(ss.p, setRoomModeMsg.Mode, d); err != nil {
+			ss.p.SendError(pb.ClientMessage_SetRoomMode, err)
+		}
+	}
+}
+
+// extractOperation unmarshals the operation of the client message carried by
+// e into op, returning the room of the participant. On failure, it reports the
+// error to the participant and returns false.
+func (ss *session) extractOperation(e *fsm.Event, cmd pb.ClientMessage_ClientCommand, op proto.Message) (*internal.Room, bool) {
+	r, ok := ss.currentRoom(cmd)
+	if !ok {
+		return nil, false
+	}
+	if !ss.unmarshalOperation(e, cmd, op) {
+		return nil, false
+	}
+
+	return r, true
+}
+
+// unmarshalOperation is extractOperation for the commands not requiring the
+// participant to be in a room.
+func (ss *session) unmarshalOperation(e *fsm.Event, cmd pb.ClientMessage_ClientCommand, op proto.Message) bool {
+	cMsgP, err := extractClientMsg(e)
+	if err != nil {
+		ss.s.log.Errorf("Cannot extract client msg: %v", err)
+
+		return false
+	}
+	if err := pbutils.UnmarshalAny(cMsgP.Operation, op); err != nil {
+		ss.p.SendError(cmd, fmt.Errorf("%w: malformed %s: %v", internal.ErrInvalidArgument, cmd, err))
+
+		return false
+	}
+
+	return true
+}
+
+// announce notifies r on behalf of the participant of the session.
+func (ss *session) announce(r *internal.Room, body string) {
+	if err := r.Announce(body); err != nil {
+		ss.s.log.Errorf("Announce to room %s failed: %v", r.Name(), err)
+	}
+}
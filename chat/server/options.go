@@ -0,0 +1,279 @@
+package server
+
+import (
+	"crypto/tls"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/savo92/playground-go-grpc/chat/backplane"
+	"github.com/savo92/playground-go-grpc/chat/cluster"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+// Limits configures the rate and size limits of the messages written in the
+// rooms.
+type Limits = internal.Limits
+
+func DefaultLimits() Limits {
+	return internal.DefaultLimits()
+}
+
+// Lifecycle configures the cleanup of the empty ephemeral rooms and the
+// archival of the idle rooms.
+type Lifecycle = internal.Lifecycle
+
+func DefaultLifecycle() Lifecycle {
+	return internal.DefaultLifecycle()
+}
+
+// Mailbox configures how long and how many direct messages and mentions are
+// kept for the offline users.
+type Mailbox = internal.Mailbox
+
+func DefaultMailbox() Mailbox {
+	return internal.DefaultMailbox()
+}
+
+type options struct {
+	// bindAddress is the address listened on, without listener.
+	bindAddress string
+	listener    net.Listener
+	// gRPCServer, when set, is served and stopped by the caller.
+	gRPCServer         *grpc.Server
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	defaultRoomName    string
+	tlsConfig          *tls.Config
+	logger             log.FieldLogger
+	authenticator      Authenticator
+	bannedUsers        []string
+	rooms              []Room
+
+	limits    Limits
+	lifecycle Lifecycle
+	mailbox   Mailbox
+	store     store.Store
+	backplane backplane.Backplane
+	// clusterSelf is set in cluster mode.
+	clusterSelf   *cluster.Member
+	clusterPeers  []cluster.Member
+	clusterSecret string
+	federation    *Federation
+}
+
+// Option configures a Server.
+type Option func(*options)
+
+// WithBindAddress sets the address the server listens on, with its port, as
+// do its HTTP and IRC servers. They listen on localhost by default.
+func WithBindAddress(addr string) Option {
+	return func(o *options) {
+		o.bindAddress = addr
+	}
+}
+
+// WithListener makes the server accept the connections of listener, which is
+// closed with the server, instead of listening on its port.
+func WithListener(listener net.Listener) Option {
+	return func(o *options) {
+		o.listener = listener
+	}
+}
+
+// WithGRPCServer registers the Chat service on gRPCServer instead of a gRPC
+// server of its own. The caller serves and stops gRPCServer: Serve only runs
+// the cluster and federation services, and Shutdown closes the rooms. The
+// port, the listener and the interceptors of the options are then ignored.
+// gRPCServer must accept the messages allowed by the limits: create it with
+// MaxRecvMsgSize when they exceed the 4MB gRPC default.
+func WithGRPCServer(gRPCServer *grpc.Server) Option {
+	return func(o *options) {
+		o.gRPCServer = gRPCServer
+	}
+}
+
+// WithUnaryInterceptors chains interceptors in front of the unary RPCs of the
+// gRPC server of the Server.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors chains interceptors in front of the streaming RPCs
+// of the gRPC server of the Server.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.streamInterceptors = append(o.streamInterceptors, interceptors...)
+	}
+}
+
+// WithDefaultRoom sets the name of the room joined by the participants after
+// their Helo, "default" by default.
+func WithDefaultRoom(name string) Option {
+	return func(o *options) {
+		o.defaultRoomName = name
+	}
+}
+
+// WithTLS serves the gRPC server of the Server over TLS configured by cfg. It
+// serves plaintext by default.
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithRooms provisions rooms on the server. The default room is created with
+// the settings of its declaration, when declared.
+func WithRooms(rooms ...Room) Option {
+	return func(o *options) {
+		o.rooms = append(o.rooms, rooms...)
+	}
+}
+
+// WithBannedUsers bans usernames from the server.
+func WithBannedUsers(usernames ...string) Option {
+	return func(o *options) {
+		o.bannedUsers = append(o.bannedUsers, usernames...)
+	}
+}
+
+// WithLogger sets the logger of the server and of its rooms. They log to the
+// standard logger of logrus by default.
+func WithLogger(logger log.FieldLogger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithAuthenticator verifies the usernames claimed by the clients with a.
+// They are trusted as is by default.
+func WithAuthenticator(a Authenticator) Option {
+	return func(o *options) {
+		o.authenticator = a
+	}
+}
+
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+func WithLifecycle(lifecycle Lifecycle) Option {
+	return func(o *options) {
+		o.lifecycle = lifecycle
+	}
+}
+
+func WithMailbox(mailbox Mailbox) Option {
+	return func(o *options) {
+		o.mailbox = mailbox
+	}
+}
+
+// WithStore sets the store of the rooms history, which is closed with the
+// server. The history is kept in memory by default.
+func WithStore(st store.Store) Option {
+	return func(o *options) {
+		o.store = st
+	}
+}
+
+// WithBackplane shares the rooms with the replicas of the server on bp, which
+// is not closed with the server. The rooms are local to the server by
+// default.
+func WithBackplane(bp backplane.Backplane) Option {
+	return func(o *options) {
+		o.backplane = bp
+	}
+}
+
+// WithCluster shares the rooms among the nodes of a cluster: each room lives
+// on one node, self, or one of peers, and the participants connected to the
+// other nodes reach it through the cluster service of self. The nodes
+// authenticate each other with secret, shared by the whole cluster. The rooms
+// are local to the server by default.
+func WithCluster(self cluster.Member, peers []cluster.Member, secret string) Option {
+	return func(o *options) {
+		o.clusterSelf = &self
+		o.clusterPeers = peers
+		o.clusterSecret = secret
+	}
+}
+
+// WithFederation bridges rooms of the server with the rooms of the federated
+// servers configured by f. The server is not federated by default.
+func WithFederation(f Federation) Option {
+	return func(o *options) {
+		o.federation = &f
+	}
+}
+
+// gRPCServerOptions returns the options of the gRPC server of the Server.
+func (o options) gRPCServerOptions() []grpc.ServerOption {
+	var serverOpts []grpc.ServerOption
+	if o.tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(o.tlsConfig)))
+	}
+	serverOpts = append(serverOpts, MaxRecvMsgSize(o.limits))
+	if len(o.unaryInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.ChainUnaryInterceptor(o.unaryInterceptors...))
+	}
+	if len(o.streamInterceptors) > 0 {
+		serverOpts = append(serverOpts, grpc.ChainStreamInterceptor(o.streamInterceptors...))
+	}
+
+	return serverOpts
+}
+
+// MaxRecvMsgSize returns the option sizing the messages received by a gRPC
+// server for the messages allowed by limits. It keeps the gRPC default unless
+// limits allow larger messages: the messages too long for limits are rejected
+// by the rooms, with an error their authors can recover from, rather than by
+// the transport, which closes the stream.
+func MaxRecvMsgSize(limits Limits) grpc.ServerOption {
+	if size := recvMsgSize(limits); size > defaultMaxRecvMsgSize {
+		return grpc.MaxRecvMsgSize(size)
+	}
+
+	return grpc.EmptyServerOption{}
+}
+
+// recvMsgSize returns the size of the messages received by a gRPC server
+// sized by MaxRecvMsgSize for limits.
+func recvMsgSize(limits Limits) int {
+	size := limits.MaxMessageBytes + messageEnvelopeBytes
+	if limits.MaxMessageBytes <= 0 || size < defaultMaxRecvMsgSize {
+		return defaultMaxRecvMsgSize
+	}
+
+	return size
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		bindAddress:     "localhost",
+		defaultRoomName: defaultRoomName,
+		logger:          log.StandardLogger(),
+		limits:          DefaultLimits(),
+		lifecycle:       DefaultLifecycle(),
+		mailbox:         DefaultMailbox(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.store == nil {
+		o.store = store.NewMemoryStore()
+	}
+	if o.backplane == nil {
+		o.backplane = backplane.NewLocal()
+	}
+
+	return o
+}
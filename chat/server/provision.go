@@ -0,0 +1,305 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+// Room declares a room provisioned by the server, as configured by its
+// operators.
+type Room struct {
+	Name        string
+	Topic       string
+	Description string
+	Labels      map[string]string
+	Mode        pb.RoomMode
+	// SlowModeInterval is required by the slow mode.
+	SlowModeInterval time.Duration
+	Visibility       pb.RoomVisibility
+	// Capacity limits the participants of the room, zero disables the limit.
+	// When Queue is true, the participants joining the full room wait for a
+	// seat, otherwise they are rejected.
+	Capacity int
+	Queue    bool
+	// Ephemeral rooms are closed once empty for the empty TTL of the
+	// lifecycle, the others are exempt from the lifecycle policy. The default
+	// room is never ephemeral.
+	Ephemeral bool
+}
+
+func (r Room) validate() error {
+	if r.Name == "" {
+		return errors.New("a room requires a name")
+	}
+	if _, ok := pb.RoomMode_name[int32(r.Mode)]; !ok {
+		return fmt.Errorf("room %s: unknown mode %d", r.Name, r.Mode)
+	}
+	if r.Mode == pb.RoomMode_Slow && r.SlowModeInterval <= 0 {
+		return fmt.Errorf("room %s: the slow mode requires a positive interval", r.Name)
+	}
+	if _, ok := pb.RoomVisibility_name[int32(r.Visibility)]; !ok {
+		return fmt.Errorf("room %s: unknown visibility %d", r.Name, r.Visibility)
+	}
+	if r.Capacity < 0 {
+		return fmt.Errorf("room %s: negative capacity", r.Name)
+	}
+
+	return nil
+}
+
+func (r Room) options() []internal.RoomOption {
+	opts := []internal.RoomOption{
+		internal.WithVisibility(r.Visibility),
+		internal.WithTopic(r.Topic),
+		internal.WithDescription(r.Description),
+		internal.WithLabels(r.Labels),
+		internal.WithCapacity(r.Capacity, r.Queue),
+	}
+	if r.Ephemeral {
+		return append(opts, internal.WithLifecycle(pb.RoomLifecycle_Ephemeral, 0))
+	}
+
+	return append(opts, internal.WithPermanent())
+}
+
+// ValidateRooms returns an error when a room of rooms is invalid, or when
+// rooms declares a room twice.
+func ValidateRooms(rooms []Room) error {
+	names := make(map[string]bool, len(rooms))
+	for _, r := range rooms {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate room %s", r.Name)
+		}
+		names[r.Name] = true
+	}
+
+	return nil
+}
+
+// SetRooms declares the rooms provisioned by the server, reconciling them
+// with the rooms declared before: the rooms missing are created, the rooms
+// no longer declared are closed, moving their participants to the default
+// room, and the settings changed are applied to the rooms still declared.
+// The lifecycle of the rooms still declared is kept. In cluster mode, each
+// node reconciles the rooms it owns. The rooms created by the users are
+// neither provisioned nor closed by the server, even when they have the name
+// of a declared room.
+func (s *Server) SetRooms(rooms []Room) error {
+	if err := ValidateRooms(rooms); err != nil {
+		return err
+	}
+	declared := make(map[string]bool, len(rooms))
+	for _, r := range rooms {
+		declared[r.Name] = true
+	}
+	s.roomsMu.Lock()
+	previous := s.rooms
+	s.rooms = rooms
+	s.roomsMu.Unlock()
+
+	kept := make(map[string]Room, len(previous))
+	for _, decl := range previous {
+		if declared[decl.Name] {
+			kept[decl.Name] = decl
+
+			continue
+		}
+		if decl.Name == s.defaultRoomName {
+			continue
+		}
+		s.retireRoom(decl.Name)
+	}
+	var errs []error
+	for _, decl := range rooms {
+		old, ok := kept[decl.Name]
+		if !ok {
+			continue
+		}
+		if reflect.DeepEqual(old, decl) {
+			continue
+		}
+		if err := s.updateRoom(old, decl); err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", decl.Name, err))
+		}
+	}
+	if err := s.provisionRooms(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("reconciling %d rooms failed, first error: %w", len(errs), errs[0])
+	}
+
+	return nil
+}
+
+// provisionedRoom looks up the room named name, when on the server. It
+// returns the user who created the room instead, when a user did.
+func (s *Server) provisionedRoom(name string) (r *internal.Room, creator string, ok bool) {
+	r, ok = s.rm.FindRoom(name)
+	if !ok {
+		return nil, "", false
+	}
+	if creator = r.Info().GetCreator(); creator != "" {
+		return nil, creator, false
+	}
+
+	return r, "", true
+}
+
+// retireRoom closes the room named name, when provisioned on the server,
+// moving its participants to the default room.
+func (s *Server) retireRoom(name string) {
+	r, creator, ok := s.provisionedRoom(name)
+	if creator != "" {
+		s.log.Infof("Kept room %s, no longer declared but created by %s", name, creator)
+	}
+	if !ok {
+		return
+	}
+	landing, _ := s.rm.GetRoom(s.defaultRoom)
+	notice := fmt.Sprintf("room %s was removed by the operators", name)
+	if err := s.rm.RetireRoom(r.ID(), landing, notice); err != nil {
+		s.log.Errorf("Closing room %s failed: %v", name, err)
+
+		return
+	}
+	s.log.Infof("Closed room %s, no longer declared", name)
+}
+
+// updateRoom applies the settings of decl changed since old, its previous
+// declaration, to the room, when provisioned on the server. The settings
+// that did not change are kept as the moderators set them.
+func (s *Server) updateRoom(old, decl Room) error {
+	r, _, ok := s.provisionedRoom(decl.Name)
+	if !ok {
+		return nil
+	}
+	if decl.Topic != old.Topic {
+		if err := r.SetTopic(nil, decl.Topic); err != nil {
+			return err
+		}
+	}
+	if decl.Description != old.Description {
+		if err := r.SetDescription(nil, decl.Description); err != nil {
+			return err
+		}
+	}
+	for k, v := range decl.Labels {
+		if old.Labels[k] == v {
+			continue
+		}
+		if err := r.SetLabel(nil, k, v); err != nil {
+			return err
+		}
+	}
+	for k := range old.Labels {
+		if _, ok := decl.Labels[k]; ok {
+			continue
+		}
+		if err := r.SetLabel(nil, k, ""); err != nil {
+			return err
+		}
+	}
+	if decl.Mode != old.Mode || decl.SlowModeInterval != old.SlowModeInterval {
+		if err := r.SetMode(nil, decl.Mode, decl.SlowModeInterval); err != nil {
+			return err
+		}
+	}
+	if decl.Visibility != old.Visibility {
+		if err := r.SetVisibility(decl.Visibility); err != nil {
+			return err
+		}
+	}
+	if decl.Capacity != old.Capacity || decl.Queue != old.Queue {
+		if err := r.SetCapacity(decl.Capacity, decl.Queue); err != nil {
+			return err
+		}
+	}
+	s.log.Infof("Updated room %s", decl.Name)
+
+	return nil
+}
+
+// provisionRooms creates the declared rooms missing on the server.
+func (s *Server) provisionRooms() error {
+	s.roomsMu.Lock()
+	rooms := s.rooms
+	s.roomsMu.Unlock()
+
+	var errs []error
+	for _, decl := range rooms {
+		if decl.Name == s.defaultRoomName {
+			continue
+		}
+		if _, creator, ok := s.provisionedRoom(decl.Name); ok || creator != "" {
+			if creator != "" {
+				s.log.Warnf("Room %s is declared but was created by %s, it is not provisioned", decl.Name, creator)
+			}
+
+			continue
+		}
+		opts, err := s.placeRoom(decl.Name)
+		if errors.Is(err, errRemoteRoom) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+		_, err = s.createRoom(decl, opts...)
+		if errors.Is(err, internal.ErrRoomExists) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", decl.Name, err))
+
+			continue
+		}
+		s.log.Infof("Provisioned room %s", decl.Name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("provisioning %d rooms failed, first error: %w", len(errs), errs[0])
+	}
+
+	return nil
+}
+
+// createDefaultRoom creates the default room, as declared when it is.
+func (s *Server) createDefaultRoom(opts ...internal.RoomOption) (internal.RoomID, error) {
+	decl := Room{Name: s.defaultRoomName}
+	s.roomsMu.Lock()
+	for _, r := range s.rooms {
+		if r.Name == s.defaultRoomName {
+			decl = r
+		}
+	}
+	s.roomsMu.Unlock()
+	decl.Ephemeral = false
+
+	return s.createRoom(decl, opts...)
+}
+
+func (s *Server) createRoom(decl Room, opts ...internal.RoomOption) (internal.RoomID, error) {
+	id, err := s.rm.CreateRoom(decl.Name, append(opts, decl.options()...)...)
+	if err != nil {
+		return id, err
+	}
+	if decl.Mode == pb.RoomMode_Normal {
+		return id, nil
+	}
+	r, ok := s.rm.GetRoom(id)
+	if !ok {
+		return id, fmt.Errorf("%w: %s", internal.ErrRoomNotFound, id)
+	}
+
+	return id, r.SetMode(nil, decl.Mode, decl.SlowModeInterval)
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"github.com/looplab/fsm"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func listRoomsHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var listRoomsMsg pb.ClientMessage_ClientListRooms
+		if !ss.unmarshalOperation(e, pb.ClientMessage_ListRooms, &listRoomsMsg) {
+			return
+		}
+
+		roomListMsg := pb.ServerMessage_ServerRoomList{}
+		for _, r := range ss.s.rm.ListRooms() {
+			if !r.VisibleTo(ss.p.Username()) {
+				continue
+			}
+			info := r.Info()
+			unread, err := r.Unread(ss.p.Username())
+			if err != nil {
+				ss.s.log.Errorf("Counting unread messages in room %s failed: %v", r.Name(), err)
+			}
+			info.Unread = uint32(unread)
+			roomListMsg.Rooms = append(roomListMsg.Rooms, info)
+		}
+		ss.send(pb.ServerMessage_RoomList, &roomListMsg)
+	}
+}
+
+func joinRoomHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var joinRoomMsg pb.ClientMessage_ClientJoinRoom
+		if !ss.unmarshalOperation(e, pb.ClientMessage_JoinRoom, &joinRoomMsg) {
+			return
+		}
+
+		r, ok := ss.s.rm.FindRoom(joinRoomMsg.Room)
+		if joinRoomMsg.Room == "" {
+			if r, ok = ss.s.rm.FindRoomByInvite(joinRoomMsg.InviteCode); !ok {
+				ss.p.SendError(pb.ClientMessage_JoinRoom, fmt.Errorf("%w: invalid or expired invite", internal.ErrPermissionDenied))
+
+				return
+			}
+		}
+		// Private rooms are reported as missing to the participants who
+		// cannot see them.
+		if !ok || (!r.VisibleTo(ss.p.Username()) && joinRoomMsg.InviteCode == "") {
+			ss.p.SendError(pb.ClientMessage_JoinRoom, fmt.Errorf("%w: %s", internal.ErrRoomNotFound, joinRoomMsg.Room))
+
+			return
+		}
+		err := ss.p.JoinRoom(r, joinRoomMsg.InviteCode)
+		if errors.Is(err, internal.ErrQueued) {
+			// The room notified the queue position, the checkout is
+			// confirmed once admitted.
+			return
+		}
+		if err != nil {
+			ss.p.SendError(pb.ClientMessage_JoinRoom, err)
+
+			return
+		}
+		ss.confirmRoomCheckout()
+	}
+}
+
+func createInviteHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var createInviteMsg pb.ClientMessage_ClientCreateInvite
+		r, ok := ss.extractOperation(e, pb.ClientMessage_CreateInvite, &createInviteMsg)
+		if !ok {
+			return
+		}
+
+		inv, err := r.CreateInvite(ss.p, int(createInviteMsg.MaxUses), createInviteMsg.Ttl.AsDuration())
+		if err != nil {
+			ss.p.SendError(pb.ClientMessage_CreateInvite, err)
+
+			return
+		}
+		ss.send(pb.ServerMessage_InviteCreated, &pb.ServerMessage_ServerInviteCreated{
+			RoomId:    string(r.ID()),
+			Code:      inv.Code,
+			ExpiresAt: timestamppb.New(inv.ExpiresAt),
+			MaxUses:   uint32(inv.MaxUses),
+		})
+	}
+}
+
+func getRoomInfoHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var getRoomInfoMsg pb.ClientMessage_ClientGetRoomInfo
+		r, ok := ss.extractOperation(e, pb.ClientMessage_GetRoomInfo, &getRoomInfoMsg)
+		if !ok {
+			return
+		}
+
+		ss.send(pb.ServerMessage_RoomDetails, &pb.ServerMessage_ServerRoomDetails{
+			Room: r.Info(),
+		})
+	}
+}
+
+func setTopicHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var setTopicMsg pb.ClientMessage_ClientSetTopic
+		r, ok := ss.extractOperation(e, pb.ClientMessage_SetTopic, &setTopicMsg)
+		if !ok {
+			return
+		}
+
+		if err := r.SetTopic(ss.p, setTopicMsg.Topic); err != nil {
+			ss.p.SendError(pb.ClientMessage_SetTopic, err)
+		}
+	}
+}
+
+func setDescriptionHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var setDescriptionMsg pb.ClientMessage_ClientSetDescription
+		r, ok := ss.extractOperation(e, pb.ClientMessage_SetDescription, &setDescriptionMsg)
+		if !ok {
+			return
+		}
+
+		if err := r.SetDescription(ss.p, setDescriptionMsg.Description); err != nil {
+			ss.p.SendError(pb.ClientMessage_SetDescription, err)
+
+			return
+		}
+		ss.send(pb.ServerMessage_RoomDetails, &pb.ServerMessage_ServerRoomDetails{Room: r.Info()})
+	}
+}
+
+func setLabelHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var setLabelMsg pb.ClientMessage_ClientSetLabel
+		r, ok := ss.extractOperation(e, pb.ClientMessage_SetLabel, &setLabelMsg)
+		if !ok {
+			return
+		}
+
+		if err := r.SetLabel(ss.p, setLabelMsg.Key, setLabelMsg.Value); err != nil {
+			ss.p.SendError(pb.ClientMessage_SetLabel, err)
+
+			return
+		}
+		ss.send(pb.ServerMessage_RoomDetails, &pb.ServerMessage_ServerRoomDetails{Room: r.Info()})
+	}
+}
+
+func markReadHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		var markReadMsg pb.ClientMessage_ClientMarkRead
+		if !ss.unmarshalOperation(e, pb.ClientMessage_MarkRead, &markReadMsg) {
+			return
+		}
+
+		// The rooms named are marked without being in them.
+		var r *internal.Room
+		var ok bool
+		if markReadMsg.Room == "" {
+			if r, ok = ss.currentRoom(pb.ClientMessage_MarkRead); !ok {
+				return
+			}
+		} else if r, ok = ss.s.rm.FindRoom(markReadMsg.Room); !ok {
+			ss.p.SendError(pb.ClientMessage_MarkRead, fmt.Errorf("%w: %s", internal.ErrRoomNotFound, markReadMsg.Room))
+
+			return
+		}
+		marker, err := r.MarkRead(ss.p, store.MessageID(markReadMsg.MessageId), markReadMsg.Share)
+		if err != nil {
+			ss.p.SendError(pb.ClientMessage_MarkRead, err)
+
+			return
+		}
+		if !markReadMsg.Share || ss.p.CurrentRoom() != r {
+			// Otherwise, the participant got the receipt shared with its
+			// room.
+			ss.send(pb.ServerMessage_ReadReceipt, &pb.ServerMessage_ServerReadReceipt{
+				RoomId:    string(r.ID()),
+				Username:  ss.p.Username(),
+				MessageId: uint64(marker),
+				ReadAt:    timestamppb.Now(),
+			})
+		}
+	}
+}
+
+// confirmRoomCheckout notifies the participant of the session that it joined
+// its current room.
+func (ss *session) confirmRoomCheckout() {
+	ss.p.ConfirmRoomCheckout()
+}
+
+// send sends op to the participant of the session as a cmd server message.
+func (ss *session) send(cmd pb.ServerMessage_ServerCommand, op proto.Message) {
+	operation, err := pbutils.MarshalAny(op)
+	if err != nil {
+		ss.s.log.Errorf("Marshal from %s failed: %v", cmd, err)
+
+		return
+	}
+	ss.p.Send(&pb.ServerMessage{
+		Command:   cmd,
+		Operation: operation,
+	})
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/looplab/fsm"
-	log "github.com/sirupsen/logrus"
 
 	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
 	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
@@ -19,73 +18,224 @@ type closeCMD struct {
 	delay bool
 }
 
+// session holds the state of a RouteChat stream shared by its fsm callbacks.
+// The callbacks run in the goroutine receiving from the stream, which can
+// outlive the session.
+type session struct {
+	ctx    context.Context
+	wg     *sync.WaitGroup
+	stream pb.Chat_RouteChatServer
+	s      *Server
+	closeC chan<- closeCMD
+	// landing is the id or the name of the room joined after the Helo, with
+	// the invite code landingInvite, instead of the default room.
+	landing       string
+	landingInvite string
+
+	// p is set by the Helo callback, through start. It is read by other
+	// goroutines under mu. err, when set, is returned by RouteChat.
+	p     *internal.Participant
+	mu    sync.Mutex
+	ended bool
+	err   error
+}
+
+// start registers p as the participant of the session and accounts for its
+// sending goroutine in wg. It returns false when the session already ended.
+func (ss *session) start(p *internal.Participant) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.ended {
+		return false
+	}
+	ss.p = p
+	ss.wg.Add(1)
+
+	return true
+}
+
+// participant returns the participant of the session, nil until the Helo
+// created it.
+func (ss *session) participant() *internal.Participant {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.p
+}
+
+// end marks the session as ended and returns its participant, if any.
+func (ss *session) end() *internal.Participant {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.ended = true
+
+	return ss.p
+}
+
+// fail ends the session with err.
+func (ss *session) fail(err error) {
+	ss.mu.Lock()
+	ss.err = err
+	ss.mu.Unlock()
+	ss.close(closeCMD{})
+}
+
+// close asks the closer of the session to end the stream. It does not block
+// when the stream is already closing.
+func (ss *session) close(cCMD closeCMD) {
+	select {
+	case ss.closeC <- cCMD:
+	case <-ss.ctx.Done():
+	}
+}
+
 func (s *Server) RouteChat(stream pb.Chat_RouteChatServer) error {
-	var p *internal.Participant
+	if s.cluster != nil {
+		return s.cluster.gateway(stream)
+	}
+
+	return s.routeChat(stream, "", "")
+}
 
+// routeChat runs the session of a participant. When landing is not empty,
+// the participant joins it instead of the default room.
+func (s *Server) routeChat(stream pb.Chat_RouteChatServer, landing, landingInvite string) error {
 	var wg sync.WaitGroup
 	closeC := make(chan closeCMD)
 	ctx, cancelFunc := context.WithCancel(stream.Context())
 	defer cancelFunc()
 
+	ss := &session{
+		ctx:    ctx,
+		wg:     &wg,
+		stream: stream,
+		s:      s,
+		closeC: closeC,
+
+		landing:       landing,
+		landingInvite: landingInvite,
+	}
+
 	sm := fsm.NewFSM(
 		"booting",
 		fsm.Events{
 			{Name: pb.ClientMessage_Helo.String(), Src: []string{"booting"}, Dst: "ready"},
 			{Name: pb.ClientMessage_WriteMessage.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_Kick.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_Ban.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_Mute.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_SetRole.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_SetRoomMode.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_ListRooms.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_JoinRoom.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_CreateInvite.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_GetRoomInfo.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_SetTopic.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_SetDescription.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_SetLabel.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_DirectMessage.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_MarkRead.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_Search.String(), Src: []string{"ready"}, Dst: "receiving"},
+			{Name: pb.ClientMessage_GetHistory.String(), Src: []string{"ready"}, Dst: "receiving"},
 			{Name: "readyAgain", Src: []string{"receiving"}, Dst: "ready"},
 			{Name: pb.ClientMessage_Quit.String(), Src: []string{"booting", "ready", "receiving"}, Dst: "closed"},
 		},
 		fsm.Callbacks{
-			utils.AfterEvent(pb.ClientMessage_Helo):         heloHandler(ctx, &wg, stream, s, p, closeC),
-			utils.AfterEvent(pb.ClientMessage_WriteMessage): writeMessageHandler(ctx, &wg, stream, s, p, closeC),
-			utils.AfterEvent(pb.ClientMessage_Quit):         quitHandler(ctx, &wg, stream, s, p, closeC),
+			utils.AfterEvent(pb.ClientMessage_Helo):           heloHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_WriteMessage):   writeMessageHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_Kick):           kickHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_Ban):            banHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_Mute):           muteHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_SetRole):        setRoleHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_SetRoomMode):    setRoomModeHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_ListRooms):      listRoomsHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_JoinRoom):       joinRoomHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_CreateInvite):   createInviteHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_GetRoomInfo):    getRoomInfoHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_SetTopic):       setTopicHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_SetDescription): setDescriptionHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_SetLabel):       setLabelHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_DirectMessage):  directMessageHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_MarkRead):       markReadHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_Search):         searchHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_GetHistory):     getHistoryHandler(ss),
+			utils.AfterEvent(pb.ClientMessage_Quit):           quitHandler(ss),
 		},
 	)
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		cCMD := <-closeC
+		var cCMD closeCMD
+		select {
+		case cCMD = <-closeC:
+		case <-ctx.Done():
+		}
 
 		if cCMD.delay {
-			log.Debugf("Delaying before closing RouteChat")
+			s.log.Debugf("Delaying before closing RouteChat")
 			t := time.NewTimer(5 * time.Second)
-			<-t.C
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+			}
 		}
-		log.Debugf("Closing RouteChat")
+		s.log.Debugf("Closing RouteChat")
 		cancelFunc()
 	}()
 
-	wg.Add(1)
+	// The receiving goroutine is not waited for: Recv blocks until the client
+	// closes the stream or RouteChat returns.
 	go func() {
-		defer wg.Done()
 		for {
 			cMsgP, err := stream.Recv()
-			if errors.As(err, &io.EOF) {
-				closeC <- closeCMD{} // TODO is this blocking when the close comes from quitHandler?
+			if errors.Is(err, io.EOF) {
+				ss.close(closeCMD{})
 
 				return
 			}
 			if err != nil {
-				log.Errorf("Recv failed: %v", err)
+				if ctx.Err() == nil {
+					s.log.Errorf("Recv failed: %v", err)
+				}
+				ss.close(closeCMD{})
 
 				return
 			}
 
 			cmd := cMsgP.Command.String()
-			log.Debugf("Got %s", cmd)
+			s.log.Debugf("Got %s", cmd)
+			// The callbacks act on behalf of the participant, which a failed
+			// Helo never creates.
+			if ss.participant() == nil && cMsgP.Command != pb.ClientMessage_Helo && cMsgP.Command != pb.ClientMessage_Quit {
+				s.log.Debugf("Rejected %s before a successful Helo", cmd)
+
+				continue
+			}
 			if err := sm.Event(cmd, cMsgP); err != nil {
-				log.Errorf("Failed to submit %s: %v", cmd, err)
+				s.log.Errorf("Failed to submit %s: %v", cmd, err)
 			}
 			if sm.Current() == "receiving" {
 				if err := sm.Event("readyAgain"); err != nil {
-					log.Errorf("Failed to submit readyAgain: %v", err)
+					s.log.Errorf("Failed to submit readyAgain: %v", err)
 				}
 			}
 		}
 	}()
 
+	<-ctx.Done()
+	// The participant leaves its room when the stream ends, otherwise the
+	// room would keep forwarding messages to it.
+	if p := ss.end(); p != nil {
+		p.Close()
+	}
 	wg.Wait()
 
-	return nil
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.err
 }
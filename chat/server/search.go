@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	pbutils "github.com/golang/protobuf/ptypes"
+	"github.com/looplab/fsm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+// UsernameMetadataKey is the metadata carrying the username of the callers
+// of the unary RPCs of the Chat service. Like the author of a Helo, it is
+// checked by the Authenticator of the server, if any, and trusted as is
+// otherwise.
+const UsernameMetadataKey = "chat-username"
+
+// callerUsername returns the authenticated username in the metadata of ctx.
+func (s *Server) callerUsername(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	usernames := md.Get(UsernameMetadataKey)
+	if len(usernames) == 0 || usernames[0] == "" {
+		return "", status.Errorf(codes.Unauthenticated, "missing %s metadata", UsernameMetadataKey)
+	}
+	if err := s.authenticate(ctx, usernames[0]); err != nil {
+		return "", status.Errorf(codes.Unauthenticated, "%s: %v", usernames[0], err)
+	}
+
+	return usernames[0], nil
+}
+
+func (s *Server) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	username, err := s.callerUsername(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.rm.Search(username, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return res, nil
+}
+
+func searchHandler(ss *session) fsm.Callback {
+	return func(e *fsm.Event) {
+		cMsgP, err := extractClientMsg(e)
+		if err != nil {
+			ss.s.log.Errorf("Cannot extract client msg: %v", err)
+
+			return
+		}
+		var searchMsg pb.ClientMessage_ClientSearch
+		if err := pbutils.UnmarshalAny(cMsgP.Operation, &searchMsg); err != nil || searchMsg.Request == nil {
+			ss.p.SendError(pb.ClientMessage_Search, fmt.Errorf("%w: malformed %s", internal.ErrInvalidArgument, cMsgP.Command))
+
+			return
+		}
+
+		res, err := ss.s.rm.Search(ss.p.Username(), searchMsg.Request)
+		if err != nil {
+			ss.p.SendError(pb.ClientMessage_Search, err)
+
+			return
+		}
+		ss.send(pb.ServerMessage_SearchResults, &pb.ServerMessage_ServerSearchResults{Response: res})
+	}
+}
@@ -5,78 +5,247 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/looplab/fsm"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
 	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
 	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+	"github.com/savo92/playground-go-grpc/chat/store"
 )
 
+// messageEnvelopeBytes is the room left in a gRPC message for the envelope of
+// a message body.
+const messageEnvelopeBytes = 1024
+
+// defaultMaxRecvMsgSize is the gRPC default for the size of the messages
+// received.
+const defaultMaxRecvMsgSize = 4 << 20
+
+const defaultRoomName = "default"
+
 type Server struct {
 	pb.UnimplementedChatServer
 
+	// listener is nil when the gRPC server is served by the caller.
 	listener   net.Listener
 	gRPCServer *grpc.Server
 
-	rm          *internal.RoomManager
-	defaultRoom internal.RoomID
+	rm              *internal.RoomManager
+	defaultRoom     internal.RoomID
+	defaultRoomName string
+	store           store.Store
+	authenticator   Authenticator
+	log             log.FieldLogger
+	// bindAddress is the address listened on by the servers of the Server,
+	// besides the admin server.
+	bindAddress string
+	// recvMsgSize is the size of the messages received by the gRPC server,
+	// fixed at creation.
+	recvMsgSize int
+
+	startedAt time.Time
+
+	// rooms are the rooms declared by the operators, guarded by roomsMu.
+	rooms   []Room
+	roomsMu sync.Mutex
+	// banned are the usernames banned from the server, guarded by bannedMu.
+	banned   map[string]bool
+	bannedMu sync.Mutex
+
+	// cluster is set in cluster mode, federation when federated.
+	cluster    *clusterNode
+	federation *federationNode
 }
 
 func (s *Server) Serve() error {
-	return s.gRPCServer.Serve(s.listener)
+	g := new(errgroup.Group)
+	if s.cluster != nil {
+		g.Go(s.cluster.serve)
+	}
+	if s.federation != nil {
+		g.Go(s.federation.serve)
+	}
+	if s.listener != nil {
+		g.Go(func() error {
+			return s.gRPCServer.Serve(s.listener)
+		})
+	}
+
+	return g.Wait()
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	gracefulShutdownSignal := make(chan struct{}, 1)
-	go func() {
-		s.rm.Close()
-		s.gRPCServer.GracefulStop()
-		close(gracefulShutdownSignal)
-	}()
-
-	select {
-	case <-ctx.Done():
-		log.Warn("Graceful shutdown timed out, killing")
-		s.gRPCServer.Stop()
-	case <-gracefulShutdownSignal:
+	beforeStop := s.rm.Close
+	if s.cluster != nil {
+		beforeStop = func() {
+			s.cluster.leave(ctx)
+			s.rm.Close()
+		}
 	}
-
-	if err := s.listener.Close(); !errors.Is(err, net.ErrClosed) {
+	if s.listener == nil {
+		beforeStop()
+	} else if err := shutdownGRPC(ctx, s.log, s.gRPCServer, s.listener, beforeStop); err != nil {
 		return err
 	}
+	if s.cluster != nil {
+		if err := s.cluster.shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	if s.federation != nil {
+		if err := s.federation.shutdown(ctx); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return s.store.Close()
 }
 
-func NewServer(port int) (*Server, error) {
-	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+// SetLimits changes the limits of the rooms and of the connected
+// participants. The size of the messages received by the gRPC server is
+// fixed at creation: limits cannot allow larger messages.
+func (s *Server) SetLimits(limits Limits) error {
+	if size := recvMsgSize(limits); size > s.recvMsgSize {
+		return fmt.Errorf("%w: a maximum message size of %d bytes requires a restart, the gRPC server accepts %d", internal.ErrInvalidArgument, limits.MaxMessageBytes, s.recvMsgSize-messageEnvelopeBytes)
+	}
+
+	return s.rm.SetLimits(limits)
+}
+
+// placeRoom returns the options placing the room named name on the server.
+// In cluster mode, the room must be owned by the server.
+func (s *Server) placeRoom(name string) ([]internal.RoomOption, error) {
+	if s.cluster == nil {
+		return nil, nil
+	}
+	id, err := s.cluster.roomID(name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to listen: %w", err)
+		return nil, err
 	}
-	rm, err := internal.NewRoomManager()
+
+	return []internal.RoomOption{internal.WithID(id)}, nil
+}
+
+// NewServer creates a Server listening on port, unless configured otherwise
+// by opts.
+func NewServer(port int, opts ...Option) (_ *Server, err error) {
+	o := newOptions(opts...)
+
+	rm, err := internal.NewRoomManager(o.limits, o.lifecycle, o.mailbox, o.store, o.backplane, internal.WithLogger(o.logger))
 	if err != nil {
 		return nil, fmt.Errorf("newRoomManager failed: %w", err)
 	}
-
 	s := &Server{
-		listener:   listener,
-		gRPCServer: grpc.NewServer(),
-		rm:         rm,
+		gRPCServer:      o.gRPCServer,
+		rm:              rm,
+		bindAddress:     o.bindAddress,
+		recvMsgSize:     recvMsgSize(o.limits),
+		defaultRoomName: o.defaultRoomName,
+		store:           o.store,
+		authenticator:   o.authenticator,
+		log:             o.logger,
+		startedAt:       time.Now(),
+		banned:          make(map[string]bool, len(o.bannedUsers)),
+	}
+	defer func() {
+		if err != nil {
+			s.closeOnError()
+		}
+	}()
+	for _, username := range o.bannedUsers {
+		s.banned[username] = true
+	}
+	if err := ValidateRooms(o.rooms); err != nil {
+		return nil, err
+	}
+	s.rooms = o.rooms
+	if s.gRPCServer == nil {
+		s.listener = o.listener
+		if s.listener == nil {
+			s.listener, err = net.Listen("tcp", net.JoinHostPort(o.bindAddress, strconv.Itoa(port)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to listen: %w", err)
+			}
+		}
+		s.gRPCServer = grpc.NewServer(o.gRPCServerOptions()...)
 	}
 
 	pb.RegisterChatServer(s.gRPCServer, s)
 
-	rID, err := rm.CreateRoom("default")
+	if o.federation != nil {
+		s.federation, err = newFederationNode(s, *o.federation)
+		if err != nil {
+			return nil, fmt.Errorf("federation node creation failed: %w", err)
+		}
+	}
+	if o.clusterSelf != nil {
+		// The default room is created by its owner, once known.
+		s.cluster, err = newClusterNode(s, *o.clusterSelf, o.clusterPeers, o.clusterSecret)
+		if err != nil {
+			return nil, fmt.Errorf("cluster node creation failed: %w", err)
+		}
+		s.defaultRoom = internal.ClusterRoomID(s.defaultRoomName)
+		s.cluster.requestRebalance()
+
+		return s, nil
+	}
+	rID, err := s.createDefaultRoom()
 	if err != nil {
 		return nil, fmt.Errorf("default room creation failed: %w", err)
 	}
 	s.defaultRoom = rID
+	if err := s.provisionRooms(); err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
 
+// closeOnError releases what NewServer acquired before failing.
+func (s *Server) closeOnError() {
+	s.rm.Close()
+	if s.federation != nil {
+		s.federation.gRPCServer.Stop()
+		s.federation.listener.Close()
+		s.federation.closePeers()
+	}
+	if s.listener != nil {
+		if err := s.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			s.log.Warnf("Closing the listener failed: %v", err)
+		}
+	}
+}
+
+// shutdownGRPC runs beforeStop and gracefully stops gRPCServer, falling back
+// to a hard stop when ctx is done first.
+func shutdownGRPC(ctx context.Context, logger log.FieldLogger, gRPCServer *grpc.Server, listener net.Listener, beforeStop func()) error {
+	gracefulShutdownSignal := make(chan struct{}, 1)
+	go func() {
+		beforeStop()
+		gRPCServer.GracefulStop()
+		close(gracefulShutdownSignal)
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Warn("Graceful shutdown timed out, killing")
+		gRPCServer.Stop()
+	case <-gracefulShutdownSignal:
+	}
+
+	if err := listener.Close(); !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	return nil
+}
+
 func extractClientMsg(e *fsm.Event) (*pb.ClientMessage, error) {
 	if len(e.Args) == 0 {
 		return nil, fmt.Errorf("not enough Args")
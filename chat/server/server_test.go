@@ -0,0 +1,713 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pbutils "github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/savo92/playground-go-grpc/chat/cluster"
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+func TestEmbeddedServer(t *testing.T) {
+	testsTable := []struct {
+		Name string
+		// OwnGRPCServer registers the Chat service on a gRPC server of the
+		// test instead of serving it on the listener.
+		OwnGRPCServer bool
+		Author        string
+		WantCode      codes.Code
+	}{
+		{
+			Name:   "listener",
+			Author: "alice",
+		},
+		{
+			Name:          "gRPC server",
+			OwnGRPCServer: true,
+			Author:        "alice",
+		},
+		{
+			Name:     "rejected author",
+			Author:   "mallory",
+			WantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			listener := bufconn.Listen(1 << 20)
+			opts := []Option{
+				WithDefaultRoom("lobby"),
+				WithAuthenticator(AuthenticatorFunc(func(ctx context.Context, username string) error {
+					if username == "mallory" {
+						return errors.New("banned")
+					}
+
+					return nil
+				})),
+			}
+			var gRPCServer *grpc.Server
+			if tt.OwnGRPCServer {
+				gRPCServer = grpc.NewServer(MaxRecvMsgSize(DefaultLimits()))
+				opts = append(opts, WithGRPCServer(gRPCServer))
+			} else {
+				opts = append(opts, WithListener(listener))
+			}
+			s, err := NewServer(0, opts...)
+			if err != nil {
+				t.Fatalf("NewServer failed: %v", err)
+			}
+			go func() {
+				if err := s.Serve(); err != nil {
+					t.Errorf("Serve failed: %v", err)
+				}
+			}()
+			if gRPCServer != nil {
+				go func() {
+					if err := gRPCServer.Serve(listener); err != nil {
+						t.Errorf("Serve of the gRPC server failed: %v", err)
+					}
+				}()
+			}
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				if err := s.Shutdown(ctx); err != nil {
+					t.Errorf("Shutdown failed: %v", err)
+				}
+				if gRPCServer != nil {
+					gRPCServer.Stop()
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, err := grpc.DialContext(ctx, "bufnet",
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return listener.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			)
+			if err != nil {
+				t.Fatalf("Dial failed: %v", err)
+			}
+			defer conn.Close()
+
+			stream, err := pb.NewChatClient(conn).RouteChat(ctx)
+			if err != nil {
+				t.Fatalf("RouteChat failed: %v", err)
+			}
+			op, err := pbutils.MarshalAny(&pb.ClientMessage_ClientHelo{Author: tt.Author})
+			if err != nil {
+				t.Fatalf("Marshal from helo failed: %v", err)
+			}
+			if err := stream.Send(&pb.ClientMessage{Command: pb.ClientMessage_Helo, Operation: op}); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+
+			m, err := stream.Recv()
+			if got := status.Code(err); got != tt.WantCode {
+				t.Fatalf("got code %s, want %s", got, tt.WantCode)
+			}
+			if err != nil {
+				return
+			}
+			var confirm pb.ServerMessage_ServerConfirmRoomCheckout
+			if err := pbutils.UnmarshalAny(m.Operation, &confirm); err != nil {
+				t.Fatalf("got %s, want %s", m.Command, pb.ServerMessage_ConfirmRoomCheckout)
+			}
+			if got := confirm.Room.GetName(); got != "lobby" {
+				t.Errorf("joined room %s, want lobby", got)
+			}
+			if err := stream.CloseSend(); err != nil {
+				t.Errorf("CloseSend failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestSetRooms(t *testing.T) {
+	s, err := NewServer(0,
+		WithListener(bufconn.Listen(1<<20)),
+		WithDefaultRoom("lobby"),
+		WithRooms(
+			Room{Name: "lobby", Topic: "Welcome"},
+			Room{Name: "news", Mode: pb.RoomMode_ReadOnly, Capacity: 2},
+		),
+	)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown failed: %v", err)
+		}
+	}()
+
+	lobby, ok := s.rm.FindRoom("lobby")
+	if !ok || lobby.ID() != s.defaultRoom {
+		t.Fatalf("lobby is not the default room")
+	}
+	if got := lobby.Info().GetTopic(); got != "Welcome" {
+		t.Errorf("got lobby topic %q, want Welcome", got)
+	}
+	news, ok := s.rm.FindRoom("news")
+	if !ok {
+		t.Fatalf("news was not provisioned")
+	}
+	if info := news.Info(); info.GetMode() != pb.RoomMode_ReadOnly || info.GetCapacity() != 2 {
+		t.Errorf("got news mode %s and capacity %d, want ReadOnly and 2", info.GetMode(), info.GetCapacity())
+	}
+	p, err := internal.NewParticipant("alice", DefaultLimits())
+	if err != nil {
+		t.Fatalf("NewParticipant failed: %v", err)
+	}
+	defer p.Close()
+	if err := p.JoinRoom(news, ""); err != nil {
+		t.Fatalf("JoinRoom failed: %v", err)
+	}
+
+	random := Room{
+		Name:       "random",
+		Topic:      "Dice",
+		Labels:     map[string]string{"lang": "en"},
+		Mode:       pb.RoomMode_ReadOnly,
+		Visibility: pb.RoomVisibility_Private,
+		Capacity:   3,
+	}
+	testsTable := []struct {
+		Name string
+		// Setup, when set, runs before the rooms are set, and Check after.
+		Setup     func(t *testing.T)
+		Check     func(t *testing.T)
+		Rooms     []Room
+		WantRooms []string
+		WantErr   bool
+	}{
+		{
+			Name:      "add and remove",
+			Rooms:     []Room{{Name: "random"}},
+			WantRooms: []string{"lobby", "random"},
+		},
+		{
+			Name:      "duplicate",
+			Rooms:     []Room{{Name: "random"}, {Name: "random"}},
+			WantRooms: []string{"lobby", "random"},
+			WantErr:   true,
+		},
+		{
+			Name:      "slow mode without interval",
+			Rooms:     []Room{{Name: "slow", Mode: pb.RoomMode_Slow}},
+			WantRooms: []string{"lobby", "random"},
+			WantErr:   true,
+		},
+		{
+			Name: "changed settings",
+			Check: func(t *testing.T) {
+				r, _ := s.rm.FindRoom("random")
+				info := r.Info()
+				if info.GetTopic() != "Dice" || info.GetLabels()["lang"] != "en" || info.GetMode() != pb.RoomMode_ReadOnly ||
+					info.GetVisibility() != pb.RoomVisibility_Private || info.GetCapacity() != 3 {
+					t.Errorf("got room %v, want the declared settings", info)
+				}
+			},
+			Rooms:     []Room{random},
+			WantRooms: []string{"lobby", "random"},
+		},
+		{
+			Name: "room of a user",
+			Setup: func(t *testing.T) {
+				if _, err := s.rm.CreateRoom("mine", internal.WithCreator("bob"), internal.WithTopic("Bob's")); err != nil {
+					t.Fatalf("CreateRoom failed: %v", err)
+				}
+			},
+			Check: func(t *testing.T) {
+				r, _ := s.rm.FindRoom("mine")
+				if got := r.Info().GetTopic(); got != "Bob's" {
+					t.Errorf("got topic %q, want the topic of bob", got)
+				}
+			},
+			Rooms:     []Room{random, {Name: "mine", Topic: "Ours"}},
+			WantRooms: []string{"lobby", "mine", "random"},
+		},
+		{
+			Name:      "room of a user no longer declared",
+			Rooms:     []Room{random},
+			WantRooms: []string{"lobby", "mine", "random"},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			if tt.Setup != nil {
+				tt.Setup(t)
+			}
+			err := s.SetRooms(tt.Rooms)
+			if (err != nil) != tt.WantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.WantErr)
+			}
+			var got []string
+			for _, r := range s.rm.ListRooms() {
+				got = append(got, r.Name())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.WantRooms, ",") {
+				t.Errorf("got rooms %v, want %v", got, tt.WantRooms)
+			}
+			if tt.Check != nil {
+				tt.Check(t)
+			}
+		})
+	}
+	if r := p.CurrentRoom(); r == nil || r.ID() != s.defaultRoom {
+		t.Errorf("alice was not moved to the default room")
+	}
+}
+
+// newTestClient serves a Server configured by opts on an in-memory listener
+// and returns a client of it.
+func newTestClient(t *testing.T, opts ...Option) (*Server, pb.ChatClient) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	s, err := NewServer(0, append(opts, WithListener(listener))...)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	// Shutdown stops the servers not serving yet.
+	go func() {
+		if err := s.Serve(); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			t.Errorf("Serve failed: %v", err)
+		}
+	}()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown failed: %v", err)
+		}
+	})
+
+	return s, pb.NewChatClient(conn)
+}
+
+// sendCommand sends cmd with op, when not nil, as its operation.
+func sendCommand(t *testing.T, stream pb.Chat_RouteChatClient, cmd pb.ClientMessage_ClientCommand, op proto.Message) {
+	t.Helper()
+	cMsg := &pb.ClientMessage{Command: cmd}
+	if op != nil {
+		var err error
+		if cMsg.Operation, err = pbutils.MarshalAny(op); err != nil {
+			t.Fatalf("Marshal from %s failed: %v", cmd, err)
+		}
+	}
+	if err := stream.Send(cMsg); err != nil {
+		t.Fatalf("Send of %s failed: %v", cmd, err)
+	}
+}
+
+func TestMalformedHelo(t *testing.T) {
+	testsTable := []struct {
+		Name    string
+		Command pb.ClientMessage_ClientCommand
+		Op      proto.Message
+	}{
+		{
+			Name:    "direct message",
+			Command: pb.ClientMessage_DirectMessage,
+			Op:      &pb.ClientMessage_ClientDirectMessage{To: "bob", Body: "hi"},
+		},
+		{
+			Name:    "search",
+			Command: pb.ClientMessage_Search,
+			Op:      &pb.ClientMessage_ClientSearch{Request: &pb.SearchRequest{Query: "hi"}},
+		},
+		{
+			Name:    "history",
+			Command: pb.ClientMessage_GetHistory,
+			Op:      &pb.ClientMessage_ClientGetHistory{Request: &pb.GetHistoryRequest{Room: "default"}},
+		},
+	}
+
+	_, client := newTestClient(t)
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			stream, err := client.RouteChat(ctx)
+			if err != nil {
+				t.Fatalf("RouteChat failed: %v", err)
+			}
+			sendCommand(t, stream, pb.ClientMessage_Helo, nil)
+			sendCommand(t, stream, tt.Command, tt.Op)
+
+			for {
+				_, err := stream.Recv()
+				if err == nil {
+					continue
+				}
+				if got := status.Code(err); got != codes.InvalidArgument {
+					t.Fatalf("got code %s, want %s", got, codes.InvalidArgument)
+				}
+
+				break
+			}
+		})
+	}
+}
+
+func TestHeloUsername(t *testing.T) {
+	testsTable := []struct {
+		Name     string
+		Author   string
+		WantCode codes.Code
+	}{
+		{
+			Name:   "local user",
+			Author: "alice",
+		},
+		{
+			Name:     "attributed to a federated server",
+			Author:   "bob@b",
+			WantCode: codes.InvalidArgument,
+		},
+	}
+
+	_, client := newTestClient(t)
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			stream, err := client.RouteChat(ctx)
+			if err != nil {
+				t.Fatalf("RouteChat failed: %v", err)
+			}
+			sendCommand(t, stream, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: tt.Author})
+
+			for {
+				sMsg, err := stream.Recv()
+				if err != nil {
+					if got := status.Code(err); got != tt.WantCode {
+						t.Fatalf("got code %s, want %s", got, tt.WantCode)
+					}
+
+					return
+				}
+				if sMsg.Command == pb.ServerMessage_ConfirmRoomCheckout {
+					if tt.WantCode != codes.OK {
+						t.Fatalf("joined, want code %s", tt.WantCode)
+					}
+
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestClusterSecret(t *testing.T) {
+	testsTable := []struct {
+		Name     string
+		Secret   string
+		WantCode codes.Code
+	}{
+		{
+			Name:     "no secret",
+			WantCode: codes.Unauthenticated,
+		},
+		{
+			Name:     "wrong secret",
+			Secret:   "guess",
+			WantCode: codes.Unauthenticated,
+		},
+		{
+			Name:     "secret",
+			Secret:   "s3cret",
+			WantCode: codes.OK,
+		},
+	}
+
+	s, _ := newTestClient(t, WithCluster(cluster.Member{ID: "a", Addr: "localhost:0"}, nil, "s3cret"))
+	conn, err := grpc.Dial(s.cluster.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewChatClusterClient(conn)
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if tt.Secret != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.Secret)
+			}
+			_, err := client.Ping(ctx, &pb.PingRequest{})
+			if got := status.Code(err); got != tt.WantCode {
+				t.Errorf("Ping got code %s, want %s", got, tt.WantCode)
+			}
+			if tt.WantCode == codes.OK {
+				return
+			}
+			stream, err := client.ProxyChat(metadata.AppendToOutgoingContext(ctx, proxyRoomKey, "default"))
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if got := status.Code(err); got != tt.WantCode {
+				t.Errorf("ProxyChat got code %s, want %s", got, tt.WantCode)
+			}
+		})
+	}
+}
+
+func TestNewServerFailure(t *testing.T) {
+	addr, fedAddr := freeAddr(t), freeAddr(t)
+	_, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+	_, err := NewServer(portNum,
+		WithBindAddress("127.0.0.1"),
+		WithFederation(Federation{Name: "a", Addr: fedAddr}),
+		// The cluster requires a secret.
+		WithCluster(cluster.Member{ID: "a", Addr: "127.0.0.1:0"}, nil, ""),
+	)
+	if err == nil {
+		t.Fatal("NewServer succeeded without a cluster secret")
+	}
+	for _, a := range []string{addr, fedAddr} {
+		listener, err := net.Listen("tcp", a)
+		if err != nil {
+			t.Fatalf("%s not released: %v", a, err)
+		}
+		listener.Close()
+	}
+}
+
+// freeAddr returns a local address free to listen to.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+// recvCommand receives from stream until a message of command want.
+func recvCommand(t *testing.T, stream pb.Chat_RouteChatClient, want pb.ServerMessage_ServerCommand) *pb.ServerMessage {
+	t.Helper()
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed waiting for %s: %v", want, err)
+		}
+		if m.Command == want {
+			return m
+		}
+		if m.Command == pb.ServerMessage_Error {
+			t.Fatalf("got an error waiting for %s: %v", want, m.Operation)
+		}
+	}
+}
+
+func TestQueuedAtHelo(t *testing.T) {
+	testsTable := []struct {
+		Name        string
+		Command     pb.ClientMessage_ClientCommand
+		Op          proto.Message
+		WantCommand pb.ServerMessage_ServerCommand
+	}{
+		{
+			Name:        "list rooms",
+			Command:     pb.ClientMessage_ListRooms,
+			Op:          &pb.ClientMessage_ClientListRooms{},
+			WantCommand: pb.ServerMessage_RoomList,
+		},
+		{
+			Name:        "join another room",
+			Command:     pb.ClientMessage_JoinRoom,
+			Op:          &pb.ClientMessage_ClientJoinRoom{Room: "other"},
+			WantCommand: pb.ServerMessage_ConfirmRoomCheckout,
+		},
+	}
+
+	s, client := newTestClient(t,
+		WithDefaultRoom("lobby"),
+		WithRooms(Room{Name: "lobby", Capacity: 1, Queue: true}, Room{Name: "other"}),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	seated, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat failed: %v", err)
+	}
+	sendCommand(t, seated, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: "alice"})
+	recvCommand(t, seated, pb.ServerMessage_ConfirmRoomCheckout)
+	queued, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat failed: %v", err)
+	}
+	sendCommand(t, queued, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: "bob"})
+	recvCommand(t, queued, pb.ServerMessage_QueuePosition)
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			sendCommand(t, queued, tt.Command, tt.Op)
+			recvCommand(t, queued, tt.WantCommand)
+		})
+	}
+	lobby, _ := s.rm.FindRoom("lobby")
+	if got := lobby.Info().GetWaiting(); got != 0 {
+		t.Errorf("got %d participants waiting in lobby, want 0", got)
+	}
+}
+
+func TestLongMessage(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat failed: %v", err)
+	}
+	sendCommand(t, stream, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: "alice"})
+	recvCommand(t, stream, pb.ServerMessage_ConfirmRoomCheckout)
+
+	// The message is longer than the limit, but the stream stays open.
+	body := strings.Repeat("a", 2*DefaultLimits().MaxMessageBytes)
+	sendCommand(t, stream, pb.ClientMessage_WriteMessage, &pb.ClientMessage_ClientWriteMessage{Body: body})
+	for {
+		m, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if m.Command == pb.ServerMessage_Error {
+			break
+		}
+	}
+	sendCommand(t, stream, pb.ClientMessage_ListRooms, &pb.ClientMessage_ClientListRooms{})
+	recvCommand(t, stream, pb.ServerMessage_RoomList)
+}
+
+func TestSetLimits(t *testing.T) {
+	testsTable := []struct {
+		Name            string
+		MaxMessageBytes int
+		WantErr         bool
+	}{
+		{
+			Name:            "smaller messages",
+			MaxMessageBytes: 1024,
+		},
+		{
+			Name:            "messages the gRPC server accepts",
+			MaxMessageBytes: 1 << 20,
+		},
+		{
+			Name:            "messages larger than the gRPC server accepts",
+			MaxMessageBytes: 8 << 20,
+			WantErr:         true,
+		},
+	}
+
+	s, _ := newTestClient(t)
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			limits := DefaultLimits()
+			limits.MaxMessageBytes = tt.MaxMessageBytes
+			limits.ParticipantBytesBurst = tt.MaxMessageBytes
+			if err := s.SetLimits(limits); (err != nil) != tt.WantErr {
+				t.Errorf("got error %v, want error %t", err, tt.WantErr)
+			}
+		})
+	}
+}
+
+func TestMarkReadRoom(t *testing.T) {
+	testsTable := []struct {
+		Name        string
+		Op          *pb.ClientMessage_ClientMarkRead
+		WantCommand pb.ServerMessage_ServerCommand
+	}{
+		{
+			Name:        "current room",
+			Op:          &pb.ClientMessage_ClientMarkRead{},
+			WantCommand: pb.ServerMessage_Error,
+		},
+		{
+			Name:        "room named",
+			Op:          &pb.ClientMessage_ClientMarkRead{Room: "other"},
+			WantCommand: pb.ServerMessage_ReadReceipt,
+		},
+		{
+			Name:        "shared in a room of others",
+			Op:          &pb.ClientMessage_ClientMarkRead{Room: "other", Share: true},
+			WantCommand: pb.ServerMessage_Error,
+		},
+	}
+
+	_, client := newTestClient(t,
+		WithDefaultRoom("lobby"),
+		WithRooms(Room{Name: "lobby", Capacity: 1, Queue: true}, Room{Name: "other"}),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	seated, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat failed: %v", err)
+	}
+	sendCommand(t, seated, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: "alice"})
+	recvCommand(t, seated, pb.ServerMessage_ConfirmRoomCheckout)
+	// bob waits for a seat, without a current room.
+	queued, err := client.RouteChat(ctx)
+	if err != nil {
+		t.Fatalf("RouteChat failed: %v", err)
+	}
+	sendCommand(t, queued, pb.ClientMessage_Helo, &pb.ClientMessage_ClientHelo{Author: "bob"})
+	recvCommand(t, queued, pb.ServerMessage_QueuePosition)
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			sendCommand(t, queued, pb.ClientMessage_MarkRead, tt.Op)
+			for {
+				m, err := queued.Recv()
+				if err != nil {
+					t.Fatalf("Recv failed: %v", err)
+				}
+				if m.Command == pb.ServerMessage_Error || m.Command == pb.ServerMessage_ReadReceipt {
+					if m.Command != tt.WantCommand {
+						t.Errorf("got %s, want %s", m.Command, tt.WantCommand)
+					}
+
+					return
+				}
+			}
+		})
+	}
+}
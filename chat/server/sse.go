@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// sseHeartbeat is how often a comment is sent on an idle event stream, so
+// that the proxies keep it open.
+const sseHeartbeat = 15 * time.Second
+
+// serveEvents streams the room named by the room query parameter as Server-
+// Sent Events, one per ServerMessage: the event is its command, and the data
+// its protobuf JSON encoding, as on the WebSocket sessions.
+func (h *HTTPServer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+
+		return
+	}
+	watcher, err := h.s.watch(r.URL.Query().Get("room"))
+	if err != nil {
+		st := status.Convert(err)
+		http.Error(w, st.Message(), httpStatus(st.Code()))
+
+		return
+	}
+	defer watcher.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	t := time.NewTicker(sseHeartbeat)
+	defer t.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.ctx.Done():
+			return
+		case <-watcher.Done:
+			fmt.Fprint(w, "event: Closed\ndata: {}\n\n")
+			flusher.Flush()
+
+			return
+		case <-t.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		case sMsgP := <-watcher.Out:
+			err = writeEvent(w, sMsgP)
+		}
+		if err != nil {
+			h.s.log.Debugf("Event stream ended: %v", err)
+
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, sMsgP *pb.ServerMessage) error {
+	data, err := protojson.Marshal(sMsgP)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sMsgP.Command, data)
+
+	return err
+}
+
+// httpStatus maps the gRPC codes returned to the HTTP clients.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// MessageStream carries the messages of a RouteChat session over a
+// transport other than gRPC. Recv returns io.EOF when the client ends the
+// session.
+type MessageStream interface {
+	Recv() (*pb.ClientMessage, error)
+	Send(*pb.ServerMessage) error
+}
+
+// ServeStream runs a RouteChat session over ms until the client ends it or
+// ctx is done. The participants of ms share the rooms with the gRPC clients.
+// The Authenticator of the server reads the credentials of the client from
+// the incoming metadata of ctx, see ContextWithToken.
+func (s *Server) ServeStream(ctx context.Context, ms MessageStream) error {
+	return s.RouteChat(&streamAdapter{serverStream: serverStream{ctx: ctx}, ms: ms})
+}
+
+// ContextWithToken returns a copy of ctx carrying token as the bearer token
+// of a client, in its incoming metadata.
+func ContextWithToken(ctx context.Context, token string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set("authorization", "Bearer "+token)
+
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+var errNotGRPC = errors.New("not a gRPC stream")
+
+// serverStream implements the methods of grpc.ServerStream for the streams
+// of other transports. The gRPC-specific methods are not supported.
+type serverStream struct {
+	ctx context.Context
+}
+
+func (ss serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss serverStream) SetHeader(metadata.MD) error {
+	return errNotGRPC
+}
+
+func (ss serverStream) SendHeader(metadata.MD) error {
+	return errNotGRPC
+}
+
+func (ss serverStream) SetTrailer(metadata.MD) {}
+
+func (ss serverStream) SendMsg(m interface{}) error {
+	return errNotGRPC
+}
+
+func (ss serverStream) RecvMsg(m interface{}) error {
+	return errNotGRPC
+}
+
+// streamAdapter adapts a MessageStream to pb.Chat_RouteChatServer.
+type streamAdapter struct {
+	serverStream
+	ms MessageStream
+}
+
+func (a *streamAdapter) Send(m *pb.ServerMessage) error {
+	return a.ms.Send(m)
+}
+
+func (a *streamAdapter) Recv() (*pb.ClientMessage, error) {
+	return a.ms.Recv()
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+	"github.com/savo92/playground-go-grpc/chat/store"
+	"github.com/savo92/playground-go-grpc/chat/transcript"
+)
+
+const (
+	// transcriptPageSize is the number of messages read from the store at
+	// once.
+	transcriptPageSize  = 500
+	transcriptChunkSize = 64 * 1024
+)
+
+func (a *AdminServer) ExportTranscript(req *pb.ExportTranscriptRequest, stream pb.ChatAdmin_ExportTranscriptServer) error {
+	if req.RoomId == "" {
+		return status.Error(codes.InvalidArgument, "room_id is required")
+	}
+	h := transcript.Header{RoomID: req.RoomId}
+	if req.Since != nil {
+		h.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		h.Until = req.Until.AsTime()
+	}
+	if !h.Since.IsZero() && !h.Until.IsZero() && !h.Since.Before(h.Until) {
+		return status.Error(codes.InvalidArgument, "since must be before until")
+	}
+	name, ok, err := a.roomName(req.RoomId)
+	if err != nil {
+		return toStatus(err)
+	}
+	h.RoomName = name
+
+	// The history is read forward from since, or from the first message.
+	q := store.HistoryQuery{After: time.Unix(0, 0), Before: h.Until, Limit: transcriptPageSize}
+	if !h.Since.IsZero() {
+		q.After = h.Since.Add(-time.Nanosecond)
+	}
+	page, more, err := a.s.store.History(req.RoomId, q)
+	if err != nil {
+		return toStatus(err)
+	}
+	if !ok && len(page) == 0 {
+		return toStatus(fmt.Errorf("%w: %s", internal.ErrRoomNotFound, req.RoomId))
+	}
+
+	bw := bufio.NewWriterSize(chunkWriter{stream}, transcriptChunkSize)
+	tw, err := transcript.NewWriter(bw, transcript.Format(req.Format), h)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var exported int
+	for {
+		for _, m := range page {
+			if err := tw.WriteMessage(m); err != nil {
+				return err
+			}
+		}
+		exported += len(page)
+		if !more {
+			break
+		}
+		q.AfterID = page[len(page)-1].ID
+		if page, more, err = a.s.store.History(req.RoomId, q); err != nil {
+			return toStatus(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	a.s.log.Infof("Exported %d messages of room %s as %s", exported, req.RoomId, req.Format)
+
+	return nil
+}
+
+// roomName returns the name of a live or archived room, or its id for the
+// other closed rooms. It reports whether the room is known.
+func (a *AdminServer) roomName(roomID string) (string, bool, error) {
+	if r, ok := a.s.rm.GetRoom(internal.RoomID(roomID)); ok {
+		return r.Name(), true, nil
+	}
+	archived, err := a.s.store.ArchivedRooms()
+	if err != nil {
+		return "", false, err
+	}
+	for _, ar := range archived {
+		if ar.ID == roomID {
+			return ar.Name, true, nil
+		}
+	}
+
+	return roomID, false, nil
+}
+
+// chunkWriter sends what is written to it as transcript chunks.
+type chunkWriter struct {
+	stream pb.ChatAdmin_ExportTranscriptServer
+}
+
+func (cw chunkWriter) Write(p []byte) (int, error) {
+	// The stats handlers may keep the message, while the buffer is reused.
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := cw.stream.Send(&pb.ExportTranscriptChunk{Data: data}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
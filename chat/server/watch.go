@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+	internal "github.com/savo92/playground-go-grpc/chat/server/internal"
+)
+
+func (s *Server) WatchRoom(req *pb.WatchRoomRequest, stream pb.Chat_WatchRoomServer) error {
+	w, err := s.watch(req.Room)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return forwardWatched(stream.Context(), w, stream.Send)
+}
+
+// watch starts watching the room ref, an id or a name. The errors are
+// gRPC statuses.
+func (s *Server) watch(ref string) (*internal.Watcher, error) {
+	if ref == "" {
+		return nil, status.Error(codes.InvalidArgument, "room is required")
+	}
+	r, ok := s.rm.FindRoom(ref)
+	if !ok {
+		return nil, toStatus(fmt.Errorf("%w: %s", internal.ErrRoomNotFound, ref))
+	}
+	w, err := r.Watch()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return w, nil
+}
+
+// forwardWatched sends the messages of w until ctx is done or the room
+// closes.
+func forwardWatched(ctx context.Context, w *internal.Watcher, send func(*pb.ServerMessage) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.Done:
+			return status.Error(codes.Unavailable, "room closed")
+		case sMsgP := <-w.Out:
+			if err := send(sMsgP); err != nil {
+				return err
+			}
+		}
+	}
+}
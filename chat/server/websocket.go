@@ -0,0 +1,156 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/savo92/playground-go-grpc/chat/pbuf"
+)
+
+// The WebSocket sessions exchange the ClientMessage and ServerMessage of
+// RouteChat, one per text frame, in their protobuf JSON encoding. The
+// operations are google.protobuf.Any, tagged by their @type:
+//
+//	{"command": "WriteMessage", "operation": {
+//	  "@type": "type.googleapis.com/pbuf.ClientMessage.ClientWriteMessage",
+//	  "body": "hello"}}
+//
+// The token of the user is the bearer token of the Authorization header of
+// the upgrade request, or its token query parameter.
+
+const wsCloseTimeout = time.Second
+
+func (h *HTTPServer) webSocketHandler(allowedOrigins []string) http.Handler {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: checkOrigin(allowedOrigins),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The Upgrader already replied.
+			h.s.log.Debugf("WebSocket upgrade failed: %v", err)
+
+			return
+		}
+		defer conn.Close()
+
+		ws := &wsStream{conn: conn}
+		ctx := h.ctx
+		if token := requestToken(r); token != "" {
+			ctx = ContextWithToken(ctx, token)
+		}
+		closeCode, closeText := websocket.CloseNormalClosure, ""
+		if err := h.s.ServeStream(ctx, ws); err != nil {
+			h.s.log.Errorf("WebSocket session failed: %v", err)
+			closeCode, closeText = websocket.CloseInternalServerErr, err.Error()
+		}
+		if err := ws.recvErr(); err != nil {
+			closeCode, closeText = websocket.CloseUnsupportedData, err.Error()
+		}
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+		_ = conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(closeCode, closeText),
+			time.Now().Add(wsCloseTimeout),
+		)
+	})
+}
+
+// checkOrigin accepts the requests of the same origin, or of one of
+// allowedOrigins, "*" allowing all.
+func checkOrigin(allowedOrigins []string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		for _, allowed := range allowedOrigins {
+			if allowed == "*" || allowed == origin {
+				return true
+			}
+		}
+		u, err := url.Parse(origin)
+
+		return err == nil && u.Host == r.Host
+	}
+}
+
+// wsStream is a MessageStream over a WebSocket connection.
+type wsStream struct {
+	conn *websocket.Conn
+	// mu serializes the writes to conn.
+	mu sync.Mutex
+	// err is the malformed message ending the session, guarded by errMu.
+	err   error
+	errMu sync.Mutex
+}
+
+func (ws *wsStream) Recv() (*pb.ClientMessage, error) {
+	_, data, err := ws.conn.ReadMessage()
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cMsg pb.ClientMessage
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &cMsg); err != nil {
+		err = fmt.Errorf("malformed message: %w", err)
+		ws.errMu.Lock()
+		ws.err = err
+		ws.errMu.Unlock()
+
+		return nil, err
+	}
+
+	return &cMsg, nil
+}
+
+func (ws *wsStream) recvErr() error {
+	ws.errMu.Lock()
+	defer ws.errMu.Unlock()
+
+	return ws.err
+}
+
+func (ws *wsStream) Send(m *pb.ServerMessage) error {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if err := ws.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return io.EOF
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// requestToken returns the bearer token of the Authorization header of r,
+// or else its token query parameter, since the browsers cannot set the
+// headers of a WebSocket.
+func requestToken(r *http.Request) string {
+	const prefix = "Bearer "
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, prefix) {
+		return strings.TrimPrefix(auth, prefix)
+	}
+
+	return r.URL.Query().Get("token")
+}
@@ -0,0 +1,317 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping the history in memory, which is lost when
+// the server stops.
+type MemoryStore struct {
+	mu       sync.Mutex
+	lastID   MessageID
+	messages map[string][]Message
+	archived []ArchivedRoom
+
+	lastMailID uint64
+	mailboxes  map[string][]MailItem
+
+	// readMarkers maps the room IDs to the read markers of the identities.
+	readMarkers map[string]map[string]MessageID
+
+	retentions map[string]Retention
+}
+
+func (s *MemoryStore) AppendMessage(m Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	m.ID = s.lastID
+	s.messages[m.RoomID] = append(s.messages[m.RoomID], m)
+
+	return m, nil
+}
+
+func (s *MemoryStore) History(roomID string, q HistoryQuery) ([]Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.messages[roomID]
+	// The messages of a room are sorted by ID and by time, the page is
+	// within [lo, hi).
+	lo, hi := 0, len(messages)
+	if q.AfterID > 0 {
+		lo = sort.Search(len(messages), func(i int) bool { return messages[i].ID > q.AfterID })
+	}
+	if !q.After.IsZero() {
+		if i := sort.Search(len(messages), func(i int) bool { return messages[i].SentAt.After(q.After) }); i > lo {
+			lo = i
+		}
+	}
+	if q.BeforeID > 0 {
+		hi = sort.Search(len(messages), func(i int) bool { return messages[i].ID >= q.BeforeID })
+	}
+	if !q.Before.IsZero() {
+		if i := sort.Search(len(messages), func(i int) bool { return !messages[i].SentAt.Before(q.Before) }); i < hi {
+			hi = i
+		}
+	}
+	if lo >= hi {
+		return nil, false, nil
+	}
+
+	more := q.Limit > 0 && hi-lo > q.Limit
+	if more {
+		if q.Forward() {
+			hi = lo + q.Limit
+		} else {
+			lo = hi - q.Limit
+		}
+	}
+	page := make([]Message, hi-lo)
+	copy(page, messages[lo:hi])
+
+	return page, more, nil
+}
+
+func (s *MemoryStore) RoomIDs() ([]string, error) {
+	s.mu.Lock()
+	roomIDs := make([]string, 0, len(s.messages))
+	for roomID := range s.messages {
+		roomIDs = append(roomIDs, roomID)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(roomIDs)
+
+	return roomIDs, nil
+}
+
+func (s *MemoryStore) SetRetention(roomID string, r Retention) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.IsZero() {
+		delete(s.retentions, roomID)
+	} else {
+		s.retentions[roomID] = r
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Compact(now time.Time) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dropped []Message
+	for roomID, r := range s.retentions {
+		messages := s.messages[roomID]
+		n := r.expired(messages, now)
+		if n == 0 {
+			continue
+		}
+		dropped = append(dropped, messages[:n]...)
+		s.messages[roomID] = append([]Message(nil), messages[n:]...)
+	}
+
+	return dropped, nil
+}
+
+func (s *MemoryStore) PurgeRoom(roomID string) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := s.messages[roomID]
+	delete(s.messages, roomID)
+
+	return dropped, nil
+}
+
+func (s *MemoryStore) PurgeAuthor(author string) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dropped []Message
+	for roomID, messages := range s.messages {
+		kept := make([]Message, 0, len(messages))
+		for _, m := range messages {
+			if m.Author == author {
+				dropped = append(dropped, m)
+			} else {
+				kept = append(kept, m)
+			}
+		}
+		s.messages[roomID] = kept
+	}
+	for recipient, mailbox := range s.mailboxes {
+		kept := mailbox[:0]
+		for _, item := range mailbox {
+			if item.From != author {
+				kept = append(kept, item)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.mailboxes, recipient)
+		} else {
+			s.mailboxes[recipient] = kept
+		}
+	}
+
+	return dropped, nil
+}
+
+func (s *MemoryStore) ArchiveRoom(room ArchivedRoom) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.archived = append(s.archived, room)
+
+	return nil
+}
+
+func (s *MemoryStore) ArchivedRooms() ([]ArchivedRoom, error) {
+	s.mu.Lock()
+	rooms := make([]ArchivedRoom, len(s.archived))
+	copy(rooms, s.archived)
+	s.mu.Unlock()
+
+	sort.SliceStable(rooms, func(i, j int) bool {
+		return rooms[i].ArchivedAt.After(rooms[j].ArchivedAt)
+	})
+
+	return rooms, nil
+}
+
+func (s *MemoryStore) AppendMail(item MailItem, max int) (MailItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastMailID++
+	item.ID = s.lastMailID
+	mailbox := append(s.mailboxes[item.Recipient], item)
+	if max > 0 && len(mailbox) > max {
+		mailbox = append([]MailItem(nil), mailbox[len(mailbox)-max:]...)
+	}
+	s.mailboxes[item.Recipient] = mailbox
+
+	return item, nil
+}
+
+func (s *MemoryStore) TakeMail(recipient string) ([]MailItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mailbox := s.mailboxes[recipient]
+	delete(s.mailboxes, recipient)
+
+	return mailbox, nil
+}
+
+func (s *MemoryStore) RestoreMail(recipient string, items []MailItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(items) == 0 {
+		return nil
+	}
+	mailbox := make([]MailItem, 0, len(items)+len(s.mailboxes[recipient]))
+	mailbox = append(mailbox, items...)
+	s.mailboxes[recipient] = append(mailbox, s.mailboxes[recipient]...)
+
+	return nil
+}
+
+func (s *MemoryStore) PruneMail(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pruned := 0
+	for recipient, mailbox := range s.mailboxes {
+		kept := mailbox[:0]
+		for _, item := range mailbox {
+			if item.SentAt.Before(before) {
+				pruned++
+
+				continue
+			}
+			kept = append(kept, item)
+		}
+		if len(kept) == 0 {
+			delete(s.mailboxes, recipient)
+		} else {
+			s.mailboxes[recipient] = kept
+		}
+	}
+
+	return pruned, nil
+}
+
+func (s *MemoryStore) ReadMarker(identity, roomID string) (MessageID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.readMarkers[roomID][identity], nil
+}
+
+func (s *MemoryStore) SetReadMarker(identity, roomID string, id MessageID) (MessageID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	markers := s.readMarkers[roomID]
+	if markers == nil {
+		markers = make(map[string]MessageID)
+		s.readMarkers[roomID] = markers
+	}
+	if id > markers[identity] {
+		markers[identity] = id
+	}
+
+	return markers[identity], nil
+}
+
+func (s *MemoryStore) ReadMarkers(roomID string) (map[string]MessageID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	markers := make(map[string]MessageID, len(s.readMarkers[roomID]))
+	for identity, id := range s.readMarkers[roomID] {
+		markers[identity] = id
+	}
+
+	return markers, nil
+}
+
+func (s *MemoryStore) CountUnread(identity, roomID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := s.messages[roomID]
+	marker := s.readMarkers[roomID][identity]
+	i := sort.Search(len(messages), func(i int) bool {
+		return messages[i].ID > marker
+	})
+	unread := 0
+	for _, m := range messages[i:] {
+		if m.Author != identity {
+			unread++
+		}
+	}
+
+	return unread, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		messages:    make(map[string][]Message),
+		mailboxes:   make(map[string][]MailItem),
+		readMarkers: make(map[string]map[string]MessageID),
+		retentions:  make(map[string]Retention),
+	}
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreHistory(t *testing.T) {
+	start := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		m := Message{RoomID: "dev", Author: "alice", Body: fmt.Sprint(i), SentAt: start.Add(time.Duration(i) * time.Minute)}
+		if _, err := s.AppendMessage(m); err != nil {
+			t.Fatalf("AppendMessage failed: %v", err)
+		}
+		if _, err := s.AppendMessage(Message{RoomID: "ops", Body: "other"}); err != nil {
+			t.Fatalf("AppendMessage failed: %v", err)
+		}
+	}
+
+	// The messages of dev have IDs 1, 3, 5, 7 and 9.
+	testsTable := []struct {
+		Name     string
+		Query    HistoryQuery
+		WantIDs  []MessageID
+		WantMore bool
+	}{
+		{
+			Name:    "everything",
+			WantIDs: []MessageID{1, 3, 5, 7, 9},
+		},
+		{
+			Name:     "last page",
+			Query:    HistoryQuery{Limit: 2},
+			WantIDs:  []MessageID{7, 9},
+			WantMore: true,
+		},
+		{
+			Name:     "before a message",
+			Query:    HistoryQuery{BeforeID: 7, Limit: 2},
+			WantIDs:  []MessageID{3, 5},
+			WantMore: true,
+		},
+		{
+			Name:     "after a message",
+			Query:    HistoryQuery{AfterID: 1, Limit: 2},
+			WantIDs:  []MessageID{3, 5},
+			WantMore: true,
+		},
+		{
+			Name:    "after a time",
+			Query:   HistoryQuery{After: start.Add(2 * time.Minute), Limit: 2},
+			WantIDs: []MessageID{7, 9},
+		},
+		{
+			Name:    "time range",
+			Query:   HistoryQuery{After: start, Before: start.Add(3 * time.Minute)},
+			WantIDs: []MessageID{3, 5},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			messages, more, err := s.History("dev", tt.Query)
+			if err != nil {
+				t.Fatalf("History failed: %v", err)
+			}
+			var ids []MessageID
+			for _, m := range messages {
+				ids = append(ids, m.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.WantIDs) || more != tt.WantMore {
+				t.Errorf("got %v (more: %t), want %v (more: %t)", ids, more, tt.WantIDs, tt.WantMore)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreRestoreMail(t *testing.T) {
+	s := NewMemoryStore()
+	for _, body := range []string{"one", "two"} {
+		if _, err := s.AppendMail(MailItem{Recipient: "bob", Body: body}, 2); err != nil {
+			t.Fatalf("AppendMail failed: %v", err)
+		}
+	}
+	taken, err := s.TakeMail("bob")
+	if err != nil {
+		t.Fatalf("TakeMail failed: %v", err)
+	}
+	// An item is stored while the taken ones are delivered.
+	if _, err := s.AppendMail(MailItem{Recipient: "bob", Body: "three"}, 2); err != nil {
+		t.Fatalf("AppendMail failed: %v", err)
+	}
+
+	if err := s.RestoreMail("bob", taken); err != nil {
+		t.Fatalf("RestoreMail failed: %v", err)
+	}
+	mailbox, err := s.TakeMail("bob")
+	if err != nil {
+		t.Fatalf("TakeMail failed: %v", err)
+	}
+	var got []string
+	for _, item := range mailbox {
+		got = append(got, item.Body)
+	}
+	if want := []string{"one", "two", "three"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got mailbox %v, want %v", got, want)
+	}
+	if mailbox[0].ID != taken[0].ID {
+		t.Errorf("got ID %d, want the ID %d kept", mailbox[0].ID, taken[0].ID)
+	}
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Retention limits the history kept for a room. The zero values disable the
+// corresponding limits.
+type Retention struct {
+	MaxAge      time.Duration
+	MaxMessages int
+	// MaxBytes limits the total size of the bodies of the messages.
+	MaxBytes int
+}
+
+func (r Retention) IsZero() bool {
+	return r == Retention{}
+}
+
+// expired returns how many of the oldest messages, sorted by ID, are beyond
+// the retention at now.
+func (r Retention) expired(messages []Message, now time.Time) int {
+	n := 0
+	if r.MaxMessages > 0 && len(messages) > r.MaxMessages {
+		n = len(messages) - r.MaxMessages
+	}
+	if r.MaxAge > 0 {
+		for n < len(messages) && now.Sub(messages[n].SentAt) > r.MaxAge {
+			n++
+		}
+	}
+	if r.MaxBytes > 0 {
+		size := 0
+		for i := len(messages) - 1; i >= n; i-- {
+			size += len(messages[i].Body)
+			if size > r.MaxBytes {
+				n = i + 1
+
+				break
+			}
+		}
+	}
+
+	return n
+}
+
+// Compactor periodically compacts a store, enforcing the retention of the
+// rooms.
+type Compactor struct {
+	st        Store
+	interval  time.Duration
+	onDropped func([]Message)
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+func (c *Compactor) run() {
+	defer c.wg.Done()
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-t.C:
+			c.Compact(now)
+		}
+	}
+}
+
+// Compact compacts the store at now.
+func (c *Compactor) Compact(now time.Time) {
+	dropped, err := c.st.Compact(now)
+	if err != nil {
+		log.Errorf("Compacting the history failed: %v", err)
+
+		return
+	}
+	if len(dropped) == 0 {
+		return
+	}
+	log.Infof("Compaction dropped %d messages", len(dropped))
+	if c.onDropped != nil {
+		c.onDropped(dropped)
+	}
+}
+
+// Stop stops the compaction, waiting for the running one.
+func (c *Compactor) Stop() {
+	c.once.Do(func() {
+		close(c.done)
+		c.wg.Wait()
+	})
+}
+
+// NewCompactor starts compacting st every interval. The messages dropped are
+// passed to onDropped, which can be nil.
+func NewCompactor(st Store, interval time.Duration, onDropped func([]Message)) *Compactor {
+	c := &Compactor{
+		st:        st,
+		interval:  interval,
+		onDropped: onDropped,
+		done:      make(chan struct{}),
+	}
+	c.wg.Add(1)
+	go c.run()
+
+	return c
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionExpired(t *testing.T) {
+	now := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	// Four messages of 10 bytes, sent 4, 3, 2 and 1 hours ago.
+	var messages []Message
+	for i := 4; i > 0; i-- {
+		messages = append(messages, Message{
+			ID:     MessageID(5 - i),
+			Body:   "0123456789",
+			SentAt: now.Add(-time.Duration(i) * time.Hour),
+		})
+	}
+
+	testsTable := []struct {
+		Name      string
+		Retention Retention
+		Want      int
+	}{
+		{Name: "no limit", Want: 0},
+		{Name: "max age", Retention: Retention{MaxAge: 150 * time.Minute}, Want: 2},
+		{Name: "max messages", Retention: Retention{MaxMessages: 3}, Want: 1},
+		{Name: "max bytes", Retention: Retention{MaxBytes: 25}, Want: 2},
+		{Name: "strictest limit", Retention: Retention{MaxAge: 90 * time.Minute, MaxMessages: 3}, Want: 3},
+		{Name: "everything expired", Retention: Retention{MaxAge: time.Minute}, Want: 4},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := tt.Retention.expired(messages, now); got != tt.Want {
+				t.Errorf("got %d, want %d", got, tt.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package store persists the history of the chat rooms.
+package store
+
+import (
+	"time"
+)
+
+// MessageID identifies a message. The IDs are assigned by the store in
+// increasing order.
+type MessageID uint64
+
+type Message struct {
+	ID     MessageID
+	RoomID string
+	Author string
+	Body   string
+	SentAt time.Time
+}
+
+// ArchivedRoom is the record of a room closed by the archival of idle rooms.
+// Its messages are kept in the store.
+type ArchivedRoom struct {
+	ID          string
+	Name        string
+	Topic       string
+	Description string
+	Creator     string
+	Labels      map[string]string
+	CreatedAt   time.Time
+	ArchivedAt  time.Time
+}
+
+type MailKind int
+
+const (
+	MailDirectMessage MailKind = iota
+	MailMention
+)
+
+// MailItem is a direct message or a mention waiting in the mailbox of a user
+// who was not connected when it was sent.
+type MailItem struct {
+	ID        uint64
+	Kind      MailKind
+	Recipient string
+	From      string
+	Body      string
+	SentAt    time.Time
+	// RoomID, RoomName and MessageID are set for the mentions.
+	RoomID    string
+	RoomName  string
+	MessageID MessageID
+}
+
+// HistoryQuery selects a page of the history of a room. The zero values
+// disable the corresponding bounds. The page holds the newest messages
+// within the bounds, or the oldest ones when an After bound is set, which is
+// how the history is scrolled forward.
+type HistoryQuery struct {
+	BeforeID MessageID
+	AfterID  MessageID
+	Before   time.Time
+	After    time.Time
+	Limit    int
+}
+
+// Forward reports whether q pages the history forward.
+func (q HistoryQuery) Forward() bool {
+	return q.AfterID > 0 || !q.After.IsZero()
+}
+
+// Store persists the rooms history. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// AppendMessage stores m, returning it with its ID assigned.
+	AppendMessage(m Message) (Message, error)
+	// History returns the page of the history of roomID selected by q,
+	// oldest first, and whether more messages are beyond the page.
+	History(roomID string, q HistoryQuery) ([]Message, bool, error)
+	// RoomIDs returns the IDs of the rooms with a history.
+	RoomIDs() ([]string, error)
+	// SetRetention sets the retention of the history of roomID, enforced by
+	// Compact. The zero Retention keeps the whole history.
+	SetRetention(roomID string, r Retention) error
+	// Compact drops the messages beyond the retention of their room at now,
+	// returning them.
+	Compact(now time.Time) ([]Message, error)
+	// PurgeRoom drops the history of roomID, returning the messages dropped.
+	PurgeRoom(roomID string) ([]Message, error)
+	// PurgeAuthor drops the messages written by author, returning them, and
+	// the mail items sent by author.
+	PurgeAuthor(author string) ([]Message, error)
+	// ArchiveRoom records room as archived.
+	ArchiveRoom(room ArchivedRoom) error
+	// ArchivedRooms returns the archived rooms, the most recently archived
+	// first.
+	ArchivedRooms() ([]ArchivedRoom, error)
+
+	// AppendMail stores item in the mailbox of its recipient, returning it
+	// with its ID assigned. When max is positive, the oldest items beyond max
+	// are dropped.
+	AppendMail(item MailItem, max int) (MailItem, error)
+	// TakeMail removes and returns the mailbox of recipient, oldest first.
+	TakeMail(recipient string) ([]MailItem, error)
+	// RestoreMail puts items, taken from the mailbox of recipient, back at
+	// its head, ahead of the items stored since, whatever the size of the
+	// mailbox.
+	RestoreMail(recipient string, items []MailItem) error
+	// PruneMail drops the items sent before t, returning how many.
+	PruneMail(before time.Time) (int, error)
+
+	// ReadMarker returns the ID of the last message of roomID read by
+	// identity, zero when none.
+	ReadMarker(identity, roomID string) (MessageID, error)
+	// SetReadMarker moves the read marker of identity in roomID forward to
+	// id, returning the resulting marker. Markers never move backward.
+	SetReadMarker(identity, roomID string, id MessageID) (MessageID, error)
+	// ReadMarkers returns the read markers of roomID by identity.
+	ReadMarkers(roomID string) (map[string]MessageID, error)
+	// CountUnread counts the messages of roomID after the read marker of
+	// identity, leaving out the ones written by identity.
+	CountUnread(identity, roomID string) (int, error)
+
+	Close() error
+}
@@ -0,0 +1,211 @@
+// Package transcript renders the history of a room as a JSON Lines, Markdown
+// or self-contained HTML transcript.
+//
+// The chat has neither message edits nor threads, so a transcript is the
+// flat list of the messages, as stored.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+type Format int
+
+const (
+	JSONLines Format = iota
+	Markdown
+	HTML
+)
+
+// Header describes the transcript of a room.
+type Header struct {
+	RoomID   string
+	RoomName string
+	// Since and Until bound the messages, zero when unbounded.
+	Since time.Time
+	Until time.Time
+}
+
+// Writer writes the messages of a transcript, oldest first.
+type Writer interface {
+	WriteMessage(m store.Message) error
+	// Close writes the end of the transcript. It does not close the
+	// underlying writer.
+	Close() error
+}
+
+// NewWriter starts a transcript in format on w.
+func NewWriter(w io.Writer, format Format, h Header) (Writer, error) {
+	switch format {
+	case JSONLines:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+
+		return &jsonWriter{enc: enc, h: h}, nil
+	case Markdown:
+		mw := &markdownWriter{w: w}
+		if _, err := fmt.Fprintf(w, "# Transcript of %s\n\n%s\n", escapeMarkdown(h.RoomName), h.period()); err != nil {
+			return nil, err
+		}
+
+		return mw, nil
+	case HTML:
+		if err := htmlTemplates.ExecuteTemplate(w, "header", h); err != nil {
+			return nil, err
+		}
+
+		return &htmlWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown transcript format %d", format)
+	}
+}
+
+// period describes the time range of the transcript.
+func (h Header) period() string {
+	switch {
+	case h.Since.IsZero() && h.Until.IsZero():
+		return "Whole history."
+	case h.Since.IsZero():
+		return fmt.Sprintf("Until %s.", h.Until.Format(time.RFC3339))
+	case h.Until.IsZero():
+		return fmt.Sprintf("Since %s.", h.Since.Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("From %s to %s.", h.Since.Format(time.RFC3339), h.Until.Format(time.RFC3339))
+	}
+}
+
+// jsonMessage is a line of a JSON Lines transcript.
+type jsonMessage struct {
+	ID       store.MessageID `json:"id"`
+	RoomID   string          `json:"room_id"`
+	RoomName string          `json:"room_name"`
+	Author   string          `json:"author"`
+	Body     string          `json:"body"`
+	SentAt   time.Time       `json:"sent_at"`
+}
+
+type jsonWriter struct {
+	enc *json.Encoder
+	h   Header
+}
+
+func (jw *jsonWriter) WriteMessage(m store.Message) error {
+	return jw.enc.Encode(jsonMessage{
+		ID:       m.ID,
+		RoomID:   m.RoomID,
+		RoomName: jw.h.RoomName,
+		Author:   m.Author,
+		Body:     m.Body,
+		SentAt:   m.SentAt.UTC(),
+	})
+}
+
+func (jw *jsonWriter) Close() error {
+	return nil
+}
+
+// markdownWriter groups the messages by day, in UTC.
+type markdownWriter struct {
+	w   io.Writer
+	day string
+}
+
+func (mw *markdownWriter) WriteMessage(m store.Message) error {
+	sentAt := m.SentAt.UTC()
+	if day := sentAt.Format("2006-01-02"); day != mw.day {
+		mw.day = day
+		if _, err := fmt.Fprintf(mw.w, "\n## %s\n\n", day); err != nil {
+			return err
+		}
+	}
+	// The continuation lines of the body are indented under the item.
+	body := strings.ReplaceAll(escapeMarkdown(m.Body), "\n", "  \n  ")
+	_, err := fmt.Fprintf(mw.w, "- `%s` **%s**: %s\n", sentAt.Format("15:04:05"), escapeMarkdown(m.Author), body)
+
+	return err
+}
+
+func (mw *markdownWriter) Close() error {
+	return nil
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", "&lt;", ">", "&gt;", "#", `\#`, "|", `\|`,
+)
+
+// escapeMarkdown escapes the characters of s with a meaning in Markdown.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+type htmlWriter struct {
+	w   io.Writer
+	day string
+}
+
+func (hw *htmlWriter) WriteMessage(m store.Message) error {
+	sentAt := m.SentAt.UTC()
+	data := struct {
+		store.Message
+		Day    string
+		NewDay bool
+		Time   string
+		SentAt string
+	}{
+		Message: m,
+		Day:     sentAt.Format("2006-01-02"),
+		Time:    sentAt.Format("15:04:05"),
+		SentAt:  sentAt.Format(time.RFC3339),
+	}
+	data.NewDay = data.Day != hw.day
+	hw.day = data.Day
+
+	return htmlTemplates.ExecuteTemplate(hw.w, "message", data)
+}
+
+func (hw *htmlWriter) Close() error {
+	return htmlTemplates.ExecuteTemplate(hw.w, "footer", nil)
+}
+
+var htmlTemplates = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"period": Header.period,
+}).Parse(`
+{{- define "header" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Transcript of {{.RoomName}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; color: #222; }
+h2 { font-size: 1em; border-bottom: 1px solid #ccc; margin-top: 2em; }
+.message { display: flex; gap: 1em; padding: .2em 0; }
+.message time { color: #888; font-family: monospace; }
+.author { font-weight: bold; }
+.body { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Transcript of {{.RoomName}}</h1>
+<p>{{period .}}</p>
+{{end -}}
+
+{{- define "message" -}}
+{{if .NewDay}}<h2>{{.Day}}</h2>
+{{end -}}
+<div class="message" id="m{{.ID}}"><time datetime="{{.SentAt}}">{{.Time}}</time><span class="author">{{.Author}}</span><span class="body">{{.Body}}</span></div>
+{{end -}}
+
+{{- define "footer" -}}
+</body>
+</html>
+{{end -}}
+`))
@@ -0,0 +1,77 @@
+package transcript
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/savo92/playground-go-grpc/chat/store"
+)
+
+func TestWriter(t *testing.T) {
+	start := time.Date(2021, 12, 1, 23, 59, 0, 0, time.UTC)
+	messages := []store.Message{
+		{ID: 1, RoomID: "r1", Author: "alice", Body: "is <prod> down?", SentAt: start},
+		{ID: 2, RoomID: "r1", Author: "bob", Body: "rollback *now*", SentAt: start.Add(2 * time.Minute)},
+	}
+	h := Header{RoomID: "r1", RoomName: "incidents", Since: start}
+
+	testsTable := []struct {
+		Name   string
+		Format Format
+		Want   []string
+	}{
+		{
+			Name:   "json lines",
+			Format: JSONLines,
+			Want: []string{
+				`{"id":1,"room_id":"r1","room_name":"incidents","author":"alice","body":"is <prod> down?","sent_at":"2021-12-01T23:59:00Z"}` + "\n",
+				`"author":"bob"`,
+			},
+		},
+		{
+			Name:   "markdown",
+			Format: Markdown,
+			Want: []string{
+				"# Transcript of incidents\n\nSince 2021-12-01T23:59:00Z.\n",
+				"## 2021-12-01\n\n- `23:59:00` **alice**: is &lt;prod&gt; down?\n",
+				"## 2021-12-02\n\n- `00:01:00` **bob**: rollback \\*now\\*\n",
+			},
+		},
+		{
+			Name:   "html",
+			Format: HTML,
+			Want: []string{
+				"<title>Transcript of incidents</title>",
+				"<h2>2021-12-01</h2>",
+				`<span class="author">alice</span><span class="body">is &lt;prod&gt; down?</span>`,
+				"<h2>2021-12-02</h2>",
+				"</html>",
+			},
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, tt.Format, h)
+			if err != nil {
+				t.Fatalf("NewWriter failed: %v", err)
+			}
+			for _, m := range messages {
+				if err := w.WriteMessage(m); err != nil {
+					t.Fatalf("WriteMessage failed: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			for _, want := range tt.Want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("%q not in:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
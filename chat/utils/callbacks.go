@@ -5,3 +5,7 @@ import "fmt"
 func AfterEvent(cmd fmt.Stringer) string {
 	return fmt.Sprint("after_", cmd)
 }
+
+func BeforeEvent(cmd fmt.Stringer) string {
+	return fmt.Sprint("before_", cmd)
+}
@@ -34,3 +34,25 @@ func TestAfterEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestBeforeEvent(t *testing.T) {
+	testsTable := []struct {
+		Name     string
+		Stringer fmt.Stringer
+		Want     string
+	}{
+		{
+			Name:     "before_something",
+			Stringer: stringerMock{s: "something"},
+			Want:     "before_something",
+		},
+	}
+
+	for _, tt := range testsTable {
+		t.Run(tt.Name, func(t *testing.T) {
+			if s := BeforeEvent(tt.Stringer); s != tt.Want {
+				t.Errorf("BeforeEvent(%v)=%s; want %s", tt.Stringer, s, tt.Want)
+			}
+		})
+	}
+}